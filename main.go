@@ -1,34 +1,158 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/lep13/AutoBuildGo/services/ecr"
 	"github.com/lep13/AutoBuildGo/services/gitsetup"
 )
 
+const defaultDescription = "Created from a template via automated setup" // Default description if none provided
+
+const usage = `Usage:
+  go run main.go create --name <repo-name> [--desc "optional description"] [--branch <default-branch>]
+  go run main.go delete --name <repo-name> [--force | --drain]
+  go run main.go status --name <repo-name>
+  go run main.go <repo-name> ["optional description"]`
+
 func main() {
 	if len(os.Args) > 1 {
-		handleCLI()
+		handleCLI(os.Args[1:])
 	} else {
-		gitsetup.HandleWebServer()
+		// Shut the server down gracefully on Ctrl+C or when the container is stopped
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		gitsetup.HandleWebServerContext(ctx, nil)
+	}
+}
+
+// handleCLI dispatches to the subcommand named by the first argument.
+// Anything else is treated as the positional <repo-name> ["description"] form of create.
+func handleCLI(args []string) {
+	switch args[0] {
+	case "create":
+		handleCreate(args[1:])
+	case "delete":
+		handleDelete(args[1:])
+	case "status":
+		handleStatus(args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Println(usage)
+	default:
+		description := defaultDescription
+		if len(args) > 1 {
+			description = strings.Join(args[1:], " ") // Combine all arguments after repoName as description
+		}
+		createRepos(args[0], description, "")
+	}
+}
+
+// parseFlags parses args with fs and makes sure --name was provided.
+func parseFlags(fs *flag.FlagSet, name *string, args []string) {
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	if *name == "" {
+		fs.Usage()
+		log.Fatal("--name is required")
+	}
+}
+
+func handleCreate(args []string) {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	name := fs.String("name", "", "name of the ECR and Git repositories to create")
+	description := fs.String("desc", defaultDescription, "description of the Git repository")
+	branch := fs.String("branch", "", "name the default branch of the Git repository is renamed to, the template's when empty")
+	parseFlags(fs, name, args)
+
+	createRepos(*name, *description, *branch)
+}
+
+func handleDelete(args []string) {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	name := fs.String("name", "", "name of the ECR and Git repositories to delete")
+	force := fs.Bool("force", false, "delete the ECR repository even if it still contains images")
+	drain := fs.Bool("drain", false, "delete all images from the ECR repository before deleting it")
+	parseFlags(fs, name, args)
+
+	// Delete ECR Repository
+	ecrClient, err := ecr.CreateECRClient()
+	if err != nil {
+		log.Fatalf("Failed to create ECR client: %v", err)
+	}
+	if err := ecr.DeleteRepoWithOptions(*name, ecr.DeleteRepoOptions{Force: *force, DrainFirst: *drain}, ecrClient); err != nil {
+		log.Fatalf("Failed to delete ECR repository: %v", err)
+	}
+
+	// Delete Git Repository
+	if err := newGitClient(loadUserConfig()).DeleteGitRepository(*name); err != nil {
+		log.Fatalf("Failed to delete Git repository: %v", err)
 	}
+
+	log.Println("ECR and Git repositories deleted successfully")
 }
 
-func handleCLI() {
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: go run main.go <repo-name> [\"optional description\"]")
+func handleStatus(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	name := fs.String("name", "", "name of the ECR and Git repositories to check")
+	parseFlags(fs, name, args)
+
+	ecrClient, err := ecr.CreateECRClient()
+	if err != nil {
+		log.Fatalf("Failed to create ECR client: %v", err)
+	}
+	ecrExists, err := ecr.RepoExists(*name, ecrClient)
+	if err != nil {
+		log.Fatalf("Failed to check ECR repository: %v", err)
 	}
-	repoName := os.Args[1]
-	description := "Created from a template via automated setup" // Default description if none provided
 
-	if len(os.Args) > 2 {
-		description = strings.Join(os.Args[2:], " ") // Combine all arguments after repoName as description
+	gitExists, err := newGitClient(loadUserConfig()).CheckGitRepositoryExists(*name)
+	if err != nil {
+		log.Fatalf("Failed to check Git repository: %v", err)
 	}
 
+	fmt.Printf("ECR repository %s: %s\n", *name, existence(ecrExists))
+	fmt.Printf("Git repository %s: %s\n", *name, existence(gitExists))
+}
+
+// loadUserConfig reads ~/.autobuildgo/config.yaml, exiting when it is invalid.
+func loadUserConfig() gitsetup.UserConfig {
+	userConfig, err := gitsetup.LoadUserConfig()
+	if err != nil {
+		log.Fatalf("Failed to load user configuration: %v", err)
+	}
+	return userConfig
+}
+
+// newGitClient returns a GitClient for the GitHub host of userConfig.
+func newGitClient(userConfig gitsetup.UserConfig) *gitsetup.GitClient {
+	return gitsetup.NewGitClientWithOptions(userConfig.GitClientOptions()...)
+}
+
+func existence(exists bool) string {
+	if exists {
+		return "exists"
+	}
+	return "not found"
+}
+
+// createRepos creates the ECR and Git repositories, then clones the Git repository to update go.mod.
+func createRepos(repoName, description, defaultBranch string) {
+	userConfig := loadUserConfig()
+
 	// Create ECR client
 	ecrClient, err := ecr.CreateECRClient()
 	if err != nil {
@@ -36,28 +160,41 @@ func handleCLI() {
 	}
 
 	// Create ECR Repository
-	if err := ecr.CreateRepo(repoName, ecrClient); err != nil {
+	ecrRepo, err := ecr.CreateRepo(repoName, ecrClient)
+	if err != nil {
 		log.Fatalf("Failed to create ECR repository: %v", err)
 	}
+	log.Printf("ECR repository URI: %s", aws.ToString(ecrRepo.Repository.RepositoryUri))
 
 	// Create Git Repository
-	config, err := gitsetup.DefaultRepoConfig(repoName, description)
+	config, err := userConfig.RepoConfig(repoName, description)
 	if err != nil {
 		log.Fatalf("Failed to create default repository configuration: %v", err)
 	}
-	gitClient := gitsetup.NewGitClient() // Create an instance of GitClient
+	config.DefaultBranch = defaultBranch
+	gitClient := newGitClient(userConfig) // Create an instance of GitClient
 
-	if err := gitClient.CreateGitRepository(config); err != nil {
+	gitRepo, err := gitClient.CreateGitRepository(config)
+	if err != nil {
 		log.Fatalf("Failed to create Git repository: %v", err)
 	}
+	log.Printf("Git repository URL: %s", gitRepo.HTMLURL)
 
 	log.Println("ECR and Git repositories created successfully")
 
-	// 10 second time delay
-	time.Sleep(10 * time.Second)
+	// Wait for GitHub to copy the template before cloning
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := gitRepo.Ready.WaitReady(ctx); err != nil {
+		log.Fatalf("Git repository did not become ready: %v", err)
+	}
 
 	// Clone the repo, update go.mod, and push changes
-	if err := gitsetup.CloneAndPushRepo(repoName); err != nil {
+	cloneConfig := gitsetup.DefaultCloneConfig()
+	cloneConfig.Branch = defaultBranch
+	cloneConfig.GitHubHost = userConfig.GitHubHost
+	cloneConfig.ECRRepositoryURI = aws.ToString(ecrRepo.Repository.RepositoryUri)
+	if err := gitsetup.CloneAndPushRepoWithConfig(repoName, cloneConfig); err != nil {
 		log.Fatalf("Failed to clone and push repository: %v", err)
 	}
 }
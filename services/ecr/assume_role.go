@@ -0,0 +1,46 @@
+package ecr
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// LoadAWSConfigWithRole loads the default AWS configuration and replaces its credentials with
+// ones obtained by assuming roleARN, for creating repositories in another account.
+// externalID and region are optional; an empty region keeps the default region.
+func LoadAWSConfigWithRole(roleARN, externalID, region string) (aws.Config, error) {
+	var optFns []func(*config.LoadOptions) error
+	if region != "" {
+		optFns = append(optFns, config.WithRegion(region))
+	}
+
+	cfg, err := globalAWSConfigLoader.LoadDefaultConfig(context.Background(), optFns...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleARN, func(o *stscreds.AssumeRoleOptions) {
+		if externalID != "" {
+			o.ExternalID = aws.String(externalID)
+		}
+	})
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+	return cfg, nil
+}
+
+// CreateECRClientWithRole creates an ECR client that acts as the assumed roleARN, with the
+// repository prefix in AUTOBUILD_ECR_REPOSITORY_PREFIX.
+func CreateECRClientWithRole(roleARN, externalID string) (ECRClientInterface, error) {
+	cfg, err := LoadAWSConfigWithRole(roleARN, externalID, "")
+	if err != nil {
+		return nil, err
+	}
+	return withRepositoryPrefix(ecr.NewFromConfig(cfg), os.Getenv(RepositoryPrefixEnvVar))
+}
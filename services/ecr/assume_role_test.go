@@ -0,0 +1,99 @@
+package ecr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+// stsConfigLoader applies the load options and points STS at a test server.
+type stsConfigLoader struct {
+	endpoint string
+}
+
+func (l stsConfigLoader) LoadDefaultConfig(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+	var options config.LoadOptions
+	for _, fn := range optFns {
+		if err := fn(&options); err != nil {
+			return aws.Config{}, err
+		}
+	}
+
+	region := options.Region
+	if region == "" {
+		region = "us-west-2"
+	}
+	return aws.Config{
+		Region:       region,
+		BaseEndpoint: aws.String(l.endpoint),
+		Credentials:  credentials.NewStaticCredentialsProvider("source", "source", ""),
+	}, nil
+}
+
+const assumeRoleResponse = `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASSUMED_KEY</AccessKeyId>
+      <SecretAccessKey>ASSUMED_SECRET</SecretAccessKey>
+      <SessionToken>ASSUMED_TOKEN</SessionToken>
+      <Expiration>2030-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`
+
+func TestLoadAWSConfigWithRole(t *testing.T) {
+	originalLoader := globalAWSConfigLoader
+	defer func() { globalAWSConfigLoader = originalLoader }()
+
+	var form map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = map[string]string{
+			"RoleArn":    r.Form.Get("RoleArn"),
+			"ExternalId": r.Form.Get("ExternalId"),
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(assumeRoleResponse))
+	}))
+	defer server.Close()
+
+	globalAWSConfigLoader = stsConfigLoader{endpoint: server.URL}
+
+	t.Run("AssumesRole", func(t *testing.T) {
+		cfg, err := LoadAWSConfigWithRole("arn:aws:iam::123456789012:role/ecr-admin", "external-id", "eu-west-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "eu-west-1", cfg.Region)
+
+		creds, err := cfg.Credentials.Retrieve(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "ASSUMED_KEY", creds.AccessKeyID)
+		assert.Equal(t, "ASSUMED_TOKEN", creds.SessionToken)
+		assert.Equal(t, "arn:aws:iam::123456789012:role/ecr-admin", form["RoleArn"])
+		assert.Equal(t, "external-id", form["ExternalId"])
+	})
+
+	t.Run("DefaultRegionWithoutExternalID", func(t *testing.T) {
+		cfg, err := LoadAWSConfigWithRole("arn:aws:iam::123456789012:role/ecr-admin", "", "")
+		assert.NoError(t, err)
+		assert.Equal(t, "us-west-2", cfg.Region)
+
+		_, err = cfg.Credentials.Retrieve(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, form["ExternalId"])
+	})
+
+	t.Run("LoadConfigError", func(t *testing.T) {
+		globalAWSConfigLoader = MockAWSConfigLoaderError{}
+		_, err := LoadAWSConfigWithRole("arn:aws:iam::123456789012:role/ecr-admin", "", "")
+		assert.EqualError(t, err, "failed to load AWS config: failed to load AWS config")
+
+		_, err = CreateECRClientWithRole("arn:aws:iam::123456789012:role/ecr-admin", "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,58 @@
+package ecr
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+)
+
+// AuthToken holds the decoded Docker credentials returned by ECR.
+type AuthToken struct {
+	Endpoint  string
+	Username  string
+	Password  string
+	ExpiresAt time.Time
+}
+
+// GetAuthorizationToken fetches an ECR authorization token and decodes it into Docker credentials.
+func GetAuthorizationToken(ctx context.Context, client ECRClientInterface) (AuthToken, error) {
+	output, err := client.GetAuthorizationToken(ctx, &ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return AuthToken{}, fmt.Errorf("failed to get authorization token: %v", err)
+	}
+	if output == nil || len(output.AuthorizationData) == 0 {
+		return AuthToken{}, errors.New("no authorization data returned")
+	}
+
+	data := output.AuthorizationData[0]
+	decoded, err := base64.StdEncoding.DecodeString(aws.ToString(data.AuthorizationToken))
+	if err != nil {
+		return AuthToken{}, fmt.Errorf("failed to decode authorization token: %v", err)
+	}
+
+	// The decoded token has the form "username:password"
+	username, password, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return AuthToken{}, errors.New("malformed authorization token")
+	}
+
+	return AuthToken{
+		Endpoint:  aws.ToString(data.ProxyEndpoint),
+		Username:  username,
+		Password:  password,
+		ExpiresAt: aws.ToTime(data.ExpiresAt),
+	}, nil
+}
+
+// DockerLoginCommand returns the arguments of the docker command that logs Docker in to ECR with the
+// given token, and the password to write to its stdin. Run the arguments directly rather than through
+// a shell: the password is then neither part of a command line nor interpreted by a shell.
+func DockerLoginCommand(token AuthToken) (args []string, stdin string) {
+	return []string{"docker", "login", "--username", token.Username, "--password-stdin", token.Endpoint}, token.Password
+}
@@ -0,0 +1,91 @@
+package ecr
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAuthorizationToken(t *testing.T) {
+	expiresAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Positive test case
+	t.Run("GetAuthorizationToken_Success", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			GetAuthorizationTokenFunc: func(ctx context.Context, params *ecr.GetAuthorizationTokenInput, optFns ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error) {
+				return &ecr.GetAuthorizationTokenOutput{
+					AuthorizationData: []types.AuthorizationData{{
+						AuthorizationToken: aws.String(base64.StdEncoding.EncodeToString([]byte("AWS:secret"))),
+						ProxyEndpoint:      aws.String("https://123456789012.dkr.ecr.us-east-1.amazonaws.com"),
+						ExpiresAt:          aws.Time(expiresAt),
+					}},
+				}, nil
+			},
+		}
+		token, err := GetAuthorizationToken(context.Background(), mockClient)
+		assert.NoError(t, err)
+		assert.Equal(t, AuthToken{
+			Endpoint:  "https://123456789012.dkr.ecr.us-east-1.amazonaws.com",
+			Username:  "AWS",
+			Password:  "secret",
+			ExpiresAt: expiresAt,
+		}, token)
+	})
+
+	// Negative test case: API failure
+	t.Run("GetAuthorizationToken_Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			GetAuthorizationTokenFunc: func(ctx context.Context, params *ecr.GetAuthorizationTokenInput, optFns ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error) {
+				return nil, errors.New("some error message")
+			},
+		}
+		_, err := GetAuthorizationToken(context.Background(), mockClient)
+		assert.EqualError(t, err, "failed to get authorization token: some error message")
+	})
+
+	// Negative test case: no authorization data
+	t.Run("GetAuthorizationToken_NoData", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			GetAuthorizationTokenFunc: func(ctx context.Context, params *ecr.GetAuthorizationTokenInput, optFns ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error) {
+				return &ecr.GetAuthorizationTokenOutput{}, nil
+			},
+		}
+		_, err := GetAuthorizationToken(context.Background(), mockClient)
+		assert.EqualError(t, err, "no authorization data returned")
+	})
+
+	// Negative test case: token without a password
+	t.Run("GetAuthorizationToken_Malformed", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			GetAuthorizationTokenFunc: func(ctx context.Context, params *ecr.GetAuthorizationTokenInput, optFns ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error) {
+				return &ecr.GetAuthorizationTokenOutput{
+					AuthorizationData: []types.AuthorizationData{{
+						AuthorizationToken: aws.String(base64.StdEncoding.EncodeToString([]byte("AWS"))),
+					}},
+				}, nil
+			},
+		}
+		_, err := GetAuthorizationToken(context.Background(), mockClient)
+		assert.EqualError(t, err, "malformed authorization token")
+	})
+}
+
+func TestDockerLoginCommand(t *testing.T) {
+	token := AuthToken{
+		Endpoint: "https://123456789012.dkr.ecr.us-east-1.amazonaws.com",
+		Username: "AWS",
+		Password: "it's $(secret)",
+	}
+	args, stdin := DockerLoginCommand(token)
+	assert.Equal(t,
+		[]string{"docker", "login", "--username", "AWS", "--password-stdin", "https://123456789012.dkr.ecr.us-east-1.amazonaws.com"},
+		args)
+	assert.Equal(t, "it's $(secret)", stdin, "the password should be passed on stdin as is")
+}
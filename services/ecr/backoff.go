@@ -0,0 +1,67 @@
+package ecr
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/smithy-go"
+)
+
+// sleepFunc is used to wait between retries and can be overridden in tests.
+var sleepFunc = time.Sleep
+
+// BackoffConfig controls the exponential backoff used by CreateRepoWithBackoff.
+type BackoffConfig struct {
+	MaxAttempts     int
+	InitialInterval time.Duration
+	Multiplier      float64
+	MaxInterval     time.Duration
+}
+
+// DefaultBackoffConfig returns a BackoffConfig suitable for ECR's API rate limits.
+func DefaultBackoffConfig() BackoffConfig {
+	return BackoffConfig{
+		MaxAttempts:     5,
+		InitialInterval: 500 * time.Millisecond,
+		Multiplier:      2,
+		MaxInterval:     10 * time.Second,
+	}
+}
+
+// CreateRepoWithBackoff calls CreateRepo and retries with exponential backoff while ECR is throttling.
+// Any other error is returned immediately.
+func CreateRepoWithBackoff(repoName string, ecrClient ECRClientInterface, backoff BackoffConfig) (*ecr.CreateRepositoryOutput, error) {
+	interval := backoff.InitialInterval
+
+	for attempt := 1; ; attempt++ {
+		output, err := CreateRepo(repoName, ecrClient)
+		if err == nil || !isThrottlingError(err) || attempt >= backoff.MaxAttempts {
+			return output, err
+		}
+
+		log.Printf("CreateRepository throttled (attempt %d of %d), retrying in %v", attempt, backoff.MaxAttempts, interval)
+		sleepFunc(interval)
+
+		interval = time.Duration(float64(interval) * backoff.Multiplier)
+		if backoff.MaxInterval > 0 && interval > backoff.MaxInterval {
+			interval = backoff.MaxInterval
+		}
+	}
+}
+
+// isThrottlingError reports whether err is an ECR throttling error. The ECR SDK has no modeled
+// exception type for throttling, so the API error code is inspected instead.
+func isThrottlingError(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.ErrorCode() {
+	case "ThrottlingException", "TooManyRequestsException":
+		return true
+	}
+	return false
+}
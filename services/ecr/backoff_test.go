@@ -0,0 +1,79 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRepoWithBackoff(t *testing.T) {
+	// Record the waits instead of sleeping
+	var sleeps []time.Duration
+	originalSleepFunc := sleepFunc
+	sleepFunc = func(d time.Duration) { sleeps = append(sleeps, d) }
+	defer func() { sleepFunc = originalSleepFunc }()
+
+	backoff := BackoffConfig{
+		MaxAttempts:     4,
+		InitialInterval: 100 * time.Millisecond,
+		Multiplier:      3,
+		MaxInterval:     500 * time.Millisecond,
+	}
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+
+	// Positive test case: succeeds after being throttled
+	t.Run("CreateRepository_SucceedsAfterThrottling", func(t *testing.T) {
+		sleeps = nil
+		calls := 0
+		mockClient := &MockECRClient{
+			CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+				calls++
+				if calls < 3 {
+					return nil, throttled
+				}
+				return &ecr.CreateRepositoryOutput{}, nil
+			},
+		}
+		_, err := CreateRepoWithBackoff("test-repo", mockClient, backoff)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 300 * time.Millisecond}, sleeps)
+	})
+
+	// Negative test case: gives up after MaxAttempts
+	t.Run("CreateRepository_ThrottledUntilMaxAttempts", func(t *testing.T) {
+		sleeps = nil
+		calls := 0
+		mockClient := &MockECRClient{
+			CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+				calls++
+				return nil, throttled
+			},
+		}
+		_, err := CreateRepoWithBackoff("test-repo", mockClient, backoff)
+		assert.ErrorIs(t, err, throttled)
+		assert.Equal(t, 4, calls)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 500 * time.Millisecond}, sleeps)
+	})
+
+	// Negative test case: other errors are not retried
+	t.Run("CreateRepository_NonThrottlingError", func(t *testing.T) {
+		sleeps = nil
+		calls := 0
+		mockClient := &MockECRClient{
+			CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+				calls++
+				return nil, errors.New("repository already exists")
+			},
+		}
+		_, err := CreateRepoWithBackoff("test-repo", mockClient, backoff)
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Empty(t, sleeps)
+	})
+}
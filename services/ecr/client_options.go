@@ -0,0 +1,40 @@
+package ecr
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+)
+
+// ECRClientOption configures the ECR client created by NewClientWithOptions
+// without changing the shared aws.Config.
+type ECRClientOption func(*ecr.Options)
+
+// WithEndpoint sends all ECR requests to url, e.g. a LocalStack endpoint.
+func WithEndpoint(url string) ECRClientOption {
+	return func(o *ecr.Options) {
+		o.BaseEndpoint = aws.String(url)
+	}
+}
+
+// WithMaxRetries retries a failed request at most n times.
+func WithMaxRetries(n int) ECRClientOption {
+	return func(o *ecr.Options) {
+		o.RetryMaxAttempts = n + 1
+	}
+}
+
+// WithLogMode sets what the SDK logs for ECR requests, e.g. aws.LogRequest | aws.LogRetries.
+func WithLogMode(mode aws.ClientLogMode) ECRClientOption {
+	return func(o *ecr.Options) {
+		o.ClientLogMode = mode
+	}
+}
+
+// NewClientWithOptions is NewClient with ECR specific options applied on top of cfg.
+func NewClientWithOptions(cfg aws.Config, opts ...ECRClientOption) ECRClientInterface {
+	optFns := make([]func(*ecr.Options), len(opts))
+	for i, opt := range opts {
+		optFns[i] = opt
+	}
+	return ecr.NewFromConfig(cfg, optFns...)
+}
@@ -0,0 +1,44 @@
+package ecr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClientWithOptions(t *testing.T) {
+	// Fail every request so the number of attempts shows the retry setting
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"__type": "ServerException", "message": "unavailable"}`))
+	}))
+	defer server.Close()
+
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+	}
+
+	client := NewClientWithOptions(cfg, WithEndpoint(server.URL), WithMaxRetries(1), WithLogMode(aws.LogRetries))
+
+	_, err := client.CreateRepository(context.Background(), &ecr.CreateRepositoryInput{RepositoryName: aws.String("testRepo")})
+	assert.Error(t, err)
+	assert.Equal(t, 2, requests)
+
+	options := client.(*ecr.Client).Options()
+	assert.Equal(t, server.URL, aws.ToString(options.BaseEndpoint))
+	assert.Equal(t, aws.LogRetries, options.ClientLogMode)
+
+	// The shared config is left untouched
+	assert.Nil(t, cfg.BaseEndpoint)
+	assert.Equal(t, aws.ClientLogMode(0), cfg.ClientLogMode)
+}
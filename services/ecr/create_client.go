@@ -6,6 +6,7 @@ import (
 
 	// "github.com/aws/aws-sdk-go-v2/aws"
 	"errors"
+	"os"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
@@ -14,12 +15,48 @@ import (
 // CreateECRClient creates and returns an ECR client using the provided AWS credentials.
 var getAWSConfigFunc = GetAWSConfig
 
+// ECREndpointEnvVar optionally overrides the ECR endpoint, e.g. for LocalStack.
+const ECREndpointEnvVar = "AUTOBUILD_ECR_ENDPOINT"
+
 // CreateECRClient creates and returns an ECR client using the provided AWS credentials.
-func CreateECRClient() (*ecr.Client, error) {
+// When AUTOBUILD_ECR_ENDPOINT is set the client talks to that endpoint instead of AWS, and
+// AUTOBUILD_ECR_REPOSITORY_PREFIX sets its repository prefix.
+func CreateECRClient() (ECRClientInterface, error) {
+    if endpoint := os.Getenv(ECREndpointEnvVar); endpoint != "" {
+        client, err := CreateECRClientWithEndpoint(endpoint)
+        if err != nil {
+            return nil, err
+        }
+        return withRepositoryPrefix(client, os.Getenv(RepositoryPrefixEnvVar))
+    }
+
+    cfg, err := getAWSConfigFunc()
+    if err != nil {
+        return nil, err
+    }
+    return withRepositoryPrefix(ecr.NewFromConfig(cfg), os.Getenv(RepositoryPrefixEnvVar))
+}
+
+// CreateECRClientWithEndpoint creates an ECR client that sends all requests to endpoint,
+// for LocalStack and other ECR compatible services.
+func CreateECRClientWithEndpoint(endpoint string) (*ecr.Client, error) {
     cfg, err := getAWSConfigFunc()
     if err != nil {
         return nil, err
     }
+
+    cfg.EndpointResolverWithOptions = aws.EndpointResolverWithOptionsFunc(
+        func(service, region string, options ...interface{}) (aws.Endpoint, error) {
+            if service == ecr.ServiceID {
+                return aws.Endpoint{
+                    URL:               endpoint,
+                    SigningRegion:     region,
+                    HostnameImmutable: true,
+                }, nil
+            }
+            // Fall back to the default resolution for other services
+            return aws.Endpoint{}, &aws.EndpointNotFoundError{}
+        })
     return ecr.NewFromConfig(cfg), nil
 }
 func MockGetAWSConfig() (aws.Config, error) {
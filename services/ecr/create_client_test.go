@@ -1,9 +1,14 @@
 package ecr
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -48,3 +53,52 @@ func TestCreateECRClient(t *testing.T) {
         })
     }
 }
+
+func TestCreateECRClientWithEndpoint(t *testing.T) {
+    // Stand in for LocalStack and record the requests it receives
+    var requests int
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+        w.Write([]byte(`{"repository": {"repositoryName": "testRepo"}}`))
+    }))
+    defer server.Close()
+
+    originalGetAWSConfigFunc := getAWSConfigFunc
+    getAWSConfigFunc = func() (aws.Config, error) {
+        return aws.Config{
+            Region:      "us-east-1",
+            Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+        }, nil
+    }
+    defer func() {
+        getAWSConfigFunc = originalGetAWSConfigFunc
+    }()
+
+    t.Run("ExplicitEndpoint", func(t *testing.T) {
+        requests = 0
+        client, err := CreateECRClientWithEndpoint(server.URL)
+        assert.NoError(t, err)
+
+        _, err = client.CreateRepository(context.Background(), &ecr.CreateRepositoryInput{RepositoryName: aws.String("testRepo")})
+        assert.NoError(t, err)
+        assert.Equal(t, 1, requests)
+    })
+
+    t.Run("EndpointFromEnv", func(t *testing.T) {
+        requests = 0
+        t.Setenv(ECREndpointEnvVar, server.URL)
+        client, err := CreateECRClient()
+        assert.NoError(t, err)
+
+        _, err = client.CreateRepository(context.Background(), &ecr.CreateRepositoryInput{RepositoryName: aws.String("testRepo")})
+        assert.NoError(t, err)
+        assert.Equal(t, 1, requests)
+    })
+
+    t.Run("ConfigError", func(t *testing.T) {
+        getAWSConfigFunc = MockGetAWSConfig
+        _, err := CreateECRClientWithEndpoint(server.URL)
+        assert.Error(t, err)
+    })
+}
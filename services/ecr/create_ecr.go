@@ -10,12 +10,26 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
 )
 
+// loadDefaultConfig is used by LoadAWSConfig and can be replaced in tests.
+var loadDefaultConfig = config.LoadDefaultConfig
+
 func LoadAWSConfig() (aws.Config, error) {
-	return config.LoadDefaultConfig(context.TODO())
+	return loadDefaultConfig(context.TODO())
 }
 
 type ECRClientInterface interface {
 	CreateRepository(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error)
+	GetAuthorizationToken(ctx context.Context, params *ecr.GetAuthorizationTokenInput, optFns ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error)
+	DeleteRepository(ctx context.Context, params *ecr.DeleteRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.DeleteRepositoryOutput, error)
+	DescribeRepositories(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error)
+	ListImages(ctx context.Context, params *ecr.ListImagesInput, optFns ...func(*ecr.Options)) (*ecr.ListImagesOutput, error)
+	BatchDeleteImage(ctx context.Context, params *ecr.BatchDeleteImageInput, optFns ...func(*ecr.Options)) (*ecr.BatchDeleteImageOutput, error)
+	GetRepositoryPolicy(ctx context.Context, params *ecr.GetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.GetRepositoryPolicyOutput, error)
+	SetRepositoryPolicy(ctx context.Context, params *ecr.SetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.SetRepositoryPolicyOutput, error)
+	StartImageScan(ctx context.Context, params *ecr.StartImageScanInput, optFns ...func(*ecr.Options)) (*ecr.StartImageScanOutput, error)
+	DescribeImageScanFindings(ctx context.Context, params *ecr.DescribeImageScanFindingsInput, optFns ...func(*ecr.Options)) (*ecr.DescribeImageScanFindingsOutput, error)
+	PutImageTagMutability(ctx context.Context, params *ecr.PutImageTagMutabilityInput, optFns ...func(*ecr.Options)) (*ecr.PutImageTagMutabilityOutput, error)
+	PutLifecyclePolicy(ctx context.Context, params *ecr.PutLifecyclePolicyInput, optFns ...func(*ecr.Options)) (*ecr.PutLifecyclePolicyOutput, error)
 }
 
 type Client struct {
@@ -27,7 +41,17 @@ func NewClient(cfg aws.Config) ECRClientInterface {
 }
 
 // CreateRepo creates a repository in Amazon ECR using the provided ECR client.
-func CreateRepo(repoName string, ecrClient ECRClientInterface) error {
+// The returned output holds the repository's URI, ARN and registry ID. A client created with an
+// ECRConfig.RepositoryPrefix creates the repository under that prefix. Every call emits an
+// "ecr.CreateRepository" span and is counted, see ConfigureTelemetry.
+func CreateRepo(repoName string, ecrClient ECRClientInterface) (*ecr.CreateRepositoryOutput, error) {
+	repoName = repositoryName(repoName, ecrClient)
+	ctx, span := startCreateRepositorySpan(context.Background(), repoName, ecrClient)
+	if err := ValidateECRRepoName(repoName); err != nil {
+		span.fail(err, errorTypeInvalidName)
+		return nil, err
+	}
+
 	input := &ecr.CreateRepositoryInput{
 		RepositoryName:     aws.String(repoName),
 		ImageTagMutability: types.ImageTagMutabilityImmutable,
@@ -36,12 +60,14 @@ func CreateRepo(repoName string, ecrClient ECRClientInterface) error {
 		},
 	}
 
-	_, err := ecrClient.CreateRepository(context.Background(), input)
+	output, err := ecrClient.CreateRepository(ctx, input)
 	if err != nil {
 		log.Printf("Failed to create repository: %v", err)
-		return err
+		span.fail(err, "")
+		return nil, err
 	}
+	span.succeed()
 
 	log.Printf("Repository %s created successfully.", repoName)
-	return nil
+	return output, nil
 }
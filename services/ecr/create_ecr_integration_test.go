@@ -0,0 +1,37 @@
+//go:build integration
+
+package ecr
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/lep13/AutoBuildGo/services/localstacktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRepo_LocalStack(t *testing.T) {
+	endpoint := localstacktest.Start(t)
+
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+	}
+	client := ecr.NewFromConfig(cfg, func(o *ecr.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+	})
+
+	output, err := CreateRepo("integration-repo", client)
+	assert.NoError(t, err)
+	assert.Equal(t, "integration-repo", aws.ToString(output.Repository.RepositoryName))
+
+	exists, err := RepoExists("integration-repo", client)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// Creating the same repository again must fail
+	_, err = CreateRepo("integration-repo", client)
+	assert.Error(t, err)
+}
@@ -5,13 +5,27 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
 	"github.com/stretchr/testify/assert"
 )
 
 // MockECRClient is a mock implementation of ECRClientInterface for testing.
 type MockECRClient struct {
-	CreateRepositoryFunc func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error)
+	CreateRepositoryFunc          func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error)
+	GetAuthorizationTokenFunc     func(ctx context.Context, params *ecr.GetAuthorizationTokenInput, optFns ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error)
+	DeleteRepositoryFunc          func(ctx context.Context, params *ecr.DeleteRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.DeleteRepositoryOutput, error)
+	DescribeRepositoriesFunc      func(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error)
+	ListImagesFunc                func(ctx context.Context, params *ecr.ListImagesInput, optFns ...func(*ecr.Options)) (*ecr.ListImagesOutput, error)
+	BatchDeleteImageFunc          func(ctx context.Context, params *ecr.BatchDeleteImageInput, optFns ...func(*ecr.Options)) (*ecr.BatchDeleteImageOutput, error)
+	GetRepositoryPolicyFunc       func(ctx context.Context, params *ecr.GetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.GetRepositoryPolicyOutput, error)
+	SetRepositoryPolicyFunc       func(ctx context.Context, params *ecr.SetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.SetRepositoryPolicyOutput, error)
+	StartImageScanFunc            func(ctx context.Context, params *ecr.StartImageScanInput, optFns ...func(*ecr.Options)) (*ecr.StartImageScanOutput, error)
+	DescribeImageScanFindingsFunc func(ctx context.Context, params *ecr.DescribeImageScanFindingsInput, optFns ...func(*ecr.Options)) (*ecr.DescribeImageScanFindingsOutput, error)
+	PutImageTagMutabilityFunc     func(ctx context.Context, params *ecr.PutImageTagMutabilityInput, optFns ...func(*ecr.Options)) (*ecr.PutImageTagMutabilityOutput, error)
+	PutLifecyclePolicyFunc        func(ctx context.Context, params *ecr.PutLifecyclePolicyInput, optFns ...func(*ecr.Options)) (*ecr.PutLifecyclePolicyOutput, error)
 }
 
 // CreateRepository mocks the CreateRepository method.
@@ -22,16 +36,136 @@ func (m *MockECRClient) CreateRepository(ctx context.Context, params *ecr.Create
 	return nil, nil
 }
 
+// GetAuthorizationToken mocks the GetAuthorizationToken method.
+func (m *MockECRClient) GetAuthorizationToken(ctx context.Context, params *ecr.GetAuthorizationTokenInput, optFns ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error) {
+	if m.GetAuthorizationTokenFunc != nil {
+		return m.GetAuthorizationTokenFunc(ctx, params, optFns...)
+	}
+	return nil, nil
+}
+
+// DeleteRepository mocks the DeleteRepository method.
+func (m *MockECRClient) DeleteRepository(ctx context.Context, params *ecr.DeleteRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.DeleteRepositoryOutput, error) {
+	if m.DeleteRepositoryFunc != nil {
+		return m.DeleteRepositoryFunc(ctx, params, optFns...)
+	}
+	return nil, nil
+}
+
+// DescribeRepositories mocks the DescribeRepositories method.
+func (m *MockECRClient) DescribeRepositories(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error) {
+	if m.DescribeRepositoriesFunc != nil {
+		return m.DescribeRepositoriesFunc(ctx, params, optFns...)
+	}
+	return nil, nil
+}
+
+// ListImages mocks the ListImages method.
+func (m *MockECRClient) ListImages(ctx context.Context, params *ecr.ListImagesInput, optFns ...func(*ecr.Options)) (*ecr.ListImagesOutput, error) {
+	if m.ListImagesFunc != nil {
+		return m.ListImagesFunc(ctx, params, optFns...)
+	}
+	return &ecr.ListImagesOutput{}, nil
+}
+
+// BatchDeleteImage mocks the BatchDeleteImage method.
+func (m *MockECRClient) BatchDeleteImage(ctx context.Context, params *ecr.BatchDeleteImageInput, optFns ...func(*ecr.Options)) (*ecr.BatchDeleteImageOutput, error) {
+	if m.BatchDeleteImageFunc != nil {
+		return m.BatchDeleteImageFunc(ctx, params, optFns...)
+	}
+	return &ecr.BatchDeleteImageOutput{}, nil
+}
+
+// GetRepositoryPolicy mocks the GetRepositoryPolicy method.
+func (m *MockECRClient) GetRepositoryPolicy(ctx context.Context, params *ecr.GetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.GetRepositoryPolicyOutput, error) {
+	if m.GetRepositoryPolicyFunc != nil {
+		return m.GetRepositoryPolicyFunc(ctx, params, optFns...)
+	}
+	return &ecr.GetRepositoryPolicyOutput{}, nil
+}
+
+// SetRepositoryPolicy mocks the SetRepositoryPolicy method.
+func (m *MockECRClient) SetRepositoryPolicy(ctx context.Context, params *ecr.SetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.SetRepositoryPolicyOutput, error) {
+	if m.SetRepositoryPolicyFunc != nil {
+		return m.SetRepositoryPolicyFunc(ctx, params, optFns...)
+	}
+	return &ecr.SetRepositoryPolicyOutput{}, nil
+}
+
+// StartImageScan mocks the StartImageScan method.
+func (m *MockECRClient) StartImageScan(ctx context.Context, params *ecr.StartImageScanInput, optFns ...func(*ecr.Options)) (*ecr.StartImageScanOutput, error) {
+	if m.StartImageScanFunc != nil {
+		return m.StartImageScanFunc(ctx, params, optFns...)
+	}
+	return &ecr.StartImageScanOutput{}, nil
+}
+
+// DescribeImageScanFindings mocks the DescribeImageScanFindings method.
+func (m *MockECRClient) DescribeImageScanFindings(ctx context.Context, params *ecr.DescribeImageScanFindingsInput, optFns ...func(*ecr.Options)) (*ecr.DescribeImageScanFindingsOutput, error) {
+	if m.DescribeImageScanFindingsFunc != nil {
+		return m.DescribeImageScanFindingsFunc(ctx, params, optFns...)
+	}
+	return &ecr.DescribeImageScanFindingsOutput{}, nil
+}
+
+// PutImageTagMutability mocks the PutImageTagMutability method.
+func (m *MockECRClient) PutImageTagMutability(ctx context.Context, params *ecr.PutImageTagMutabilityInput, optFns ...func(*ecr.Options)) (*ecr.PutImageTagMutabilityOutput, error) {
+	if m.PutImageTagMutabilityFunc != nil {
+		return m.PutImageTagMutabilityFunc(ctx, params, optFns...)
+	}
+	return &ecr.PutImageTagMutabilityOutput{}, nil
+}
+
+// PutLifecyclePolicy mocks the PutLifecyclePolicy method.
+func (m *MockECRClient) PutLifecyclePolicy(ctx context.Context, params *ecr.PutLifecyclePolicyInput, optFns ...func(*ecr.Options)) (*ecr.PutLifecyclePolicyOutput, error) {
+	if m.PutLifecyclePolicyFunc != nil {
+		return m.PutLifecyclePolicyFunc(ctx, params, optFns...)
+	}
+	return &ecr.PutLifecyclePolicyOutput{}, nil
+}
+
+func TestLoadAWSConfig_Success(t *testing.T) {
+	originalLoadDefaultConfig := loadDefaultConfig
+	defer func() { loadDefaultConfig = originalLoadDefaultConfig }()
+
+	loadDefaultConfig = func(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{Region: "us-west-2"}, nil
+	}
+
+	cfg, err := LoadAWSConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "us-west-2", cfg.Region)
+}
+
+func TestLoadAWSConfig_Failure(t *testing.T) {
+	originalLoadDefaultConfig := loadDefaultConfig
+	defer func() { loadDefaultConfig = originalLoadDefaultConfig }()
+
+	loadDefaultConfig = func(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{}, errors.New("failed to get shared config profile, default")
+	}
+
+	cfg, err := LoadAWSConfig()
+	assert.EqualError(t, err, "failed to get shared config profile, default")
+	assert.Equal(t, aws.Config{}, cfg)
+}
+
 func TestCreateRepo(t *testing.T) {
 	// Positive test case
 	t.Run("CreateRepository_Success", func(t *testing.T) {
 		mockClient := &MockECRClient{
 			CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
-				return &ecr.CreateRepositoryOutput{}, nil
+				return &ecr.CreateRepositoryOutput{
+					Repository: &types.Repository{
+						RepositoryName: params.RepositoryName,
+						RepositoryUri:  aws.String("123456789012.dkr.ecr.us-east-1.amazonaws.com/test-repo"),
+					},
+				}, nil
 			},
 		}
-		err := CreateRepo("testRepo", mockClient)
+		output, err := CreateRepo("test-repo", mockClient)
 		assert.NoError(t, err)
+		assert.Equal(t, "123456789012.dkr.ecr.us-east-1.amazonaws.com/test-repo", aws.ToString(output.Repository.RepositoryUri))
 	})
 
 	// Negative test case: Generic failure
@@ -41,7 +175,7 @@ func TestCreateRepo(t *testing.T) {
 				return nil, errors.New("some error message") // Replace this with the error you want to simulate
 			},
 		}
-		err := CreateRepo("testRepo", mockClient)
+		_, err := CreateRepo("test-repo", mockClient)
 		assert.Error(t, err)
 	})
 
@@ -52,7 +186,18 @@ func TestCreateRepo(t *testing.T) {
 				return nil, errors.New("repository already exists") // Simulate repository already exists error
 			},
 		}
-		err := CreateRepo("testRepo", mockClient)
+		_, err := CreateRepo("test-repo", mockClient)
 		assert.Error(t, err)
 	})
 }
+
+func TestCreateRepo_InvalidName(t *testing.T) {
+	mockClient := &MockECRClient{
+		CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+			t.Error("CreateRepository should not be called for an invalid name")
+			return nil, nil
+		},
+	}
+	_, err := CreateRepo("MyRepo", mockClient)
+	assert.EqualError(t, err, "ECR repository names must be lowercase, got 'MyRepo'")
+}
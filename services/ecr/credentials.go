@@ -0,0 +1,40 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// GetAWSCredentials resolves the credentials of the default AWS config, checking that
+// credentials are configured without calling any AWS service. ExpiresAt is set for temporary credentials.
+func GetAWSCredentials(ctx context.Context) (AWSCredentials, error) {
+	cfg, err := getAWSConfigFunc()
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	return retrieveCredentials(ctx, cfg)
+}
+
+// retrieveCredentials resolves the credentials of cfg like GetAWSCredentials.
+func retrieveCredentials(ctx context.Context, cfg aws.Config) (AWSCredentials, error) {
+	if cfg.Credentials == nil {
+		return AWSCredentials{}, errors.New("no AWS credentials configured")
+	}
+
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("failed to retrieve AWS credentials: %v", err)
+	}
+	result := AWSCredentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+	}
+	if creds.CanExpire {
+		result.ExpiresAt = creds.Expires
+	}
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAWSCredentials(t *testing.T) {
+	originalGetAWSConfigFunc := getAWSConfigFunc
+	defer func() { getAWSConfigFunc = originalGetAWSConfigFunc }()
+
+	t.Run("Success", func(t *testing.T) {
+		getAWSConfigFunc = func() (aws.Config, error) {
+			return aws.Config{Credentials: credentials.NewStaticCredentialsProvider("AKID", "secret", "session")}, nil
+		}
+
+		creds, err := GetAWSCredentials(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"}, creds)
+	})
+
+	t.Run("Temporary Credentials", func(t *testing.T) {
+		expires := time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC)
+		getAWSConfigFunc = func() (aws.Config, error) {
+			return aws.Config{Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: "ASIA", SecretAccessKey: "secret", SessionToken: "session", CanExpire: true, Expires: expires}, nil
+			})}, nil
+		}
+
+		creds, err := GetAWSCredentials(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, expires, creds.ExpiresAt)
+	})
+
+	t.Run("No Credentials", func(t *testing.T) {
+		getAWSConfigFunc = func() (aws.Config, error) { return aws.Config{}, nil }
+
+		_, err := GetAWSCredentials(context.Background())
+		assert.EqualError(t, err, "no AWS credentials configured")
+	})
+
+	t.Run("Retrieve Error", func(t *testing.T) {
+		getAWSConfigFunc = func() (aws.Config, error) {
+			return aws.Config{Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+				return aws.Credentials{}, errors.New("expired token")
+			})}, nil
+		}
+
+		_, err := GetAWSCredentials(context.Background())
+		assert.EqualError(t, err, "failed to retrieve AWS credentials: expired token")
+	})
+
+	t.Run("Config Error", func(t *testing.T) {
+		getAWSConfigFunc = MockGetAWSConfig
+
+		_, err := GetAWSCredentials(context.Background())
+		assert.EqualError(t, err, "mocked error")
+	})
+}
+
+func TestAWSCredentials_WillExpireSoon(t *testing.T) {
+	assert.False(t, AWSCredentials{}.WillExpireSoon(time.Hour), "credentials without an expiry never expire")
+	assert.True(t, AWSCredentials{ExpiresAt: time.Now().Add(5 * time.Minute)}.WillExpireSoon(10*time.Minute))
+	assert.False(t, AWSCredentials{ExpiresAt: time.Now().Add(time.Hour)}.WillExpireSoon(10*time.Minute))
+	assert.True(t, AWSCredentials{ExpiresAt: time.Now().Add(-time.Minute)}.WillExpireSoon(0), "expired credentials")
+}
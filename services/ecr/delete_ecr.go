@@ -0,0 +1,101 @@
+package ecr
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// batchDeleteImageLimit is the maximum number of images BatchDeleteImage accepts per call.
+const batchDeleteImageLimit = 100
+
+// DeleteRepoOptions controls how DeleteRepoWithOptions deletes a repository.
+type DeleteRepoOptions struct {
+	// Force deletes the repository even if it still contains images.
+	Force bool
+	// DrainFirst deletes all images before deleting the repository, so it can be deleted without Force.
+	DrainFirst bool
+}
+
+// DeleteRepo deletes a repository in Amazon ECR using the provided ECR client.
+// A client created with an ECRConfig.RepositoryPrefix deletes the repository under that prefix.
+// When force is true the repository is deleted even if it still contains images.
+func DeleteRepo(repoName string, force bool, ecrClient ECRClientInterface) error {
+	return DeleteRepoWithOptions(repoName, DeleteRepoOptions{Force: force}, ecrClient)
+}
+
+// DeleteRepoWithOptions deletes a repository in Amazon ECR using the provided options.
+func DeleteRepoWithOptions(repoName string, opts DeleteRepoOptions, ecrClient ECRClientInterface) error {
+	if opts.DrainFirst && !opts.Force {
+		deleted, err := DeleteAllImages(repoName, ecrClient)
+		if err != nil {
+			return err
+		}
+		log.Printf("Deleted %d images from repository %s.", deleted, repoName)
+	}
+
+	repoName = repositoryName(repoName, ecrClient)
+	input := &ecr.DeleteRepositoryInput{
+		RepositoryName: aws.String(repoName),
+		Force:          opts.Force,
+	}
+
+	_, err := ecrClient.DeleteRepository(context.Background(), input)
+	if err != nil {
+		log.Printf("Failed to delete repository: %v", err)
+		return err
+	}
+
+	log.Printf("Repository %s deleted successfully.", repoName)
+	return nil
+}
+
+// DeleteAllImages deletes every image in the repository and returns the number of images deleted.
+func DeleteAllImages(repoName string, ecrClient ECRClientInterface) (int, error) {
+	ctx := context.Background()
+	repoName = repositoryName(repoName, ecrClient)
+
+	// List every image first so deleting does not invalidate the pagination token
+	var imageIDs []types.ImageIdentifier
+	var nextToken *string
+	for {
+		output, err := ecrClient.ListImages(ctx, &ecr.ListImagesInput{
+			RepositoryName: aws.String(repoName),
+			NextToken:      nextToken,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list images: %v", err)
+		}
+		imageIDs = append(imageIDs, output.ImageIds...)
+
+		nextToken = output.NextToken
+		if nextToken == nil {
+			break
+		}
+	}
+
+	deleted := 0
+	for start := 0; start < len(imageIDs); start += batchDeleteImageLimit {
+		end := min(start+batchDeleteImageLimit, len(imageIDs))
+
+		output, err := ecrClient.BatchDeleteImage(ctx, &ecr.BatchDeleteImageInput{
+			RepositoryName: aws.String(repoName),
+			ImageIds:       imageIDs[start:end],
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete images: %v", err)
+		}
+		deleted += len(output.ImageIds)
+
+		if len(output.Failures) > 0 {
+			failure := output.Failures[0]
+			return deleted, fmt.Errorf("failed to delete %d images: %s", len(output.Failures), aws.ToString(failure.FailureReason))
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,153 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteRepo(t *testing.T) {
+	// Positive test case
+	t.Run("DeleteRepository_Success", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			DeleteRepositoryFunc: func(ctx context.Context, params *ecr.DeleteRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.DeleteRepositoryOutput, error) {
+				assert.Equal(t, "testRepo", *params.RepositoryName)
+				assert.False(t, params.Force)
+				return &ecr.DeleteRepositoryOutput{}, nil
+			},
+		}
+		err := DeleteRepo("testRepo", false, mockClient)
+		assert.NoError(t, err)
+	})
+
+	// Negative test case: Generic failure
+	t.Run("DeleteRepository_Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			DeleteRepositoryFunc: func(ctx context.Context, params *ecr.DeleteRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.DeleteRepositoryOutput, error) {
+				return nil, errors.New("some error message")
+			},
+		}
+		err := DeleteRepo("testRepo", false, mockClient)
+		assert.Error(t, err)
+	})
+
+	// Negative test case: the repository does not exist
+	t.Run("DeleteRepository_NotFound", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			DeleteRepositoryFunc: func(ctx context.Context, params *ecr.DeleteRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.DeleteRepositoryOutput, error) {
+				return nil, &types.RepositoryNotFoundException{Message: aws.String("repository testRepo not found")}
+			},
+		}
+		err := DeleteRepo("testRepo", false, mockClient)
+		var notFound *types.RepositoryNotFoundException
+		assert.True(t, errors.As(err, &notFound), "expected RepositoryNotFoundException, got %v", err)
+	})
+
+	// A repository with images is only deleted with force
+	t.Run("DeleteRepository_Force", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			DeleteRepositoryFunc: func(ctx context.Context, params *ecr.DeleteRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.DeleteRepositoryOutput, error) {
+				if !params.Force {
+					return nil, &types.RepositoryNotEmptyException{Message: aws.String("repository testRepo still contains images")}
+				}
+				return &ecr.DeleteRepositoryOutput{}, nil
+			},
+		}
+		var notEmpty *types.RepositoryNotEmptyException
+		assert.True(t, errors.As(DeleteRepo("testRepo", false, mockClient), &notEmpty), "expected RepositoryNotEmptyException without force")
+		assert.NoError(t, DeleteRepo("testRepo", true, mockClient))
+	})
+}
+
+func TestDeleteAllImages(t *testing.T) {
+	// Two pages of 150 images in total, which need two BatchDeleteImage calls
+	pages := map[string]*ecr.ListImagesOutput{
+		"":       {ImageIds: imageIDs(0, 120), NextToken: aws.String("page-2")},
+		"page-2": {ImageIds: imageIDs(120, 150)},
+	}
+
+	// Positive test case
+	t.Run("DeleteAllImages_Success", func(t *testing.T) {
+		var batchSizes []int
+		mockClient := &MockECRClient{
+			ListImagesFunc: func(ctx context.Context, params *ecr.ListImagesInput, optFns ...func(*ecr.Options)) (*ecr.ListImagesOutput, error) {
+				return pages[aws.ToString(params.NextToken)], nil
+			},
+			BatchDeleteImageFunc: func(ctx context.Context, params *ecr.BatchDeleteImageInput, optFns ...func(*ecr.Options)) (*ecr.BatchDeleteImageOutput, error) {
+				batchSizes = append(batchSizes, len(params.ImageIds))
+				return &ecr.BatchDeleteImageOutput{ImageIds: params.ImageIds}, nil
+			},
+		}
+		deleted, err := DeleteAllImages("testRepo", mockClient)
+		assert.NoError(t, err)
+		assert.Equal(t, 150, deleted)
+		assert.Equal(t, []int{100, 50}, batchSizes)
+	})
+
+	// Negative test case: some images could not be deleted
+	t.Run("DeleteAllImages_Failures", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			ListImagesFunc: func(ctx context.Context, params *ecr.ListImagesInput, optFns ...func(*ecr.Options)) (*ecr.ListImagesOutput, error) {
+				return &ecr.ListImagesOutput{ImageIds: imageIDs(0, 2)}, nil
+			},
+			BatchDeleteImageFunc: func(ctx context.Context, params *ecr.BatchDeleteImageInput, optFns ...func(*ecr.Options)) (*ecr.BatchDeleteImageOutput, error) {
+				return &ecr.BatchDeleteImageOutput{
+					ImageIds: params.ImageIds[:1],
+					Failures: []types.ImageFailure{{FailureReason: aws.String("image is in use")}},
+				}, nil
+			},
+		}
+		deleted, err := DeleteAllImages("testRepo", mockClient)
+		assert.EqualError(t, err, "failed to delete 1 images: image is in use")
+		assert.Equal(t, 1, deleted)
+	})
+
+	// Negative test case: listing fails
+	t.Run("DeleteAllImages_ListFailure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			ListImagesFunc: func(ctx context.Context, params *ecr.ListImagesInput, optFns ...func(*ecr.Options)) (*ecr.ListImagesOutput, error) {
+				return nil, errors.New("some error message")
+			},
+		}
+		_, err := DeleteAllImages("testRepo", mockClient)
+		assert.Error(t, err)
+	})
+}
+
+func TestDeleteRepoWithOptions_DrainFirst(t *testing.T) {
+	var steps []string
+	mockClient := &MockECRClient{
+		ListImagesFunc: func(ctx context.Context, params *ecr.ListImagesInput, optFns ...func(*ecr.Options)) (*ecr.ListImagesOutput, error) {
+			steps = append(steps, "list")
+			return &ecr.ListImagesOutput{ImageIds: imageIDs(0, 3)}, nil
+		},
+		BatchDeleteImageFunc: func(ctx context.Context, params *ecr.BatchDeleteImageInput, optFns ...func(*ecr.Options)) (*ecr.BatchDeleteImageOutput, error) {
+			steps = append(steps, "batch-delete")
+			return &ecr.BatchDeleteImageOutput{ImageIds: params.ImageIds}, nil
+		},
+		DeleteRepositoryFunc: func(ctx context.Context, params *ecr.DeleteRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.DeleteRepositoryOutput, error) {
+			steps = append(steps, "delete-repository")
+			assert.False(t, params.Force)
+			return &ecr.DeleteRepositoryOutput{}, nil
+		},
+	}
+
+	err := DeleteRepoWithOptions("testRepo", DeleteRepoOptions{DrainFirst: true}, mockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"list", "batch-delete", "delete-repository"}, steps)
+}
+
+// imageIDs returns image identifiers with digests sha256:<from> to sha256:<to-1>.
+func imageIDs(from, to int) []types.ImageIdentifier {
+	ids := make([]types.ImageIdentifier, 0, to-from)
+	for i := from; i < to; i++ {
+		ids = append(ids, types.ImageIdentifier{ImageDigest: aws.String(fmt.Sprintf("sha256:%d", i))})
+	}
+	return ids
+}
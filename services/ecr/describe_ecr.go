@@ -0,0 +1,40 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// RepoExists reports whether a repository with the given name exists in Amazon ECR.
+func RepoExists(repoName string, ecrClient ECRClientInterface) (bool, error) {
+	input := &ecr.DescribeRepositoriesInput{
+		RepositoryNames: []string{repositoryName(repoName, ecrClient)},
+	}
+
+	_, err := ecrClient.DescribeRepositories(context.Background(), input)
+	if err != nil {
+		var notFound *types.RepositoryNotFoundException
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Ping checks that ECR can be reached with the client's credentials by listing at most one repository.
+func Ping(ctx context.Context, ecrClient ECRClientInterface) error {
+	_, err := ecrClient.DescribeRepositories(ctx, &ecr.DescribeRepositoriesInput{
+		MaxResults: aws.Int32(1),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reach ECR: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoExists(t *testing.T) {
+	// Positive test case
+	t.Run("DescribeRepositories_Found", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			DescribeRepositoriesFunc: func(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error) {
+				assert.Equal(t, []string{"testRepo"}, params.RepositoryNames)
+				return &ecr.DescribeRepositoriesOutput{Repositories: []types.Repository{{}}}, nil
+			},
+		}
+		exists, err := RepoExists("testRepo", mockClient)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	// Repository not found is not an error
+	t.Run("DescribeRepositories_NotFound", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			DescribeRepositoriesFunc: func(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error) {
+				return nil, &types.RepositoryNotFoundException{}
+			},
+		}
+		exists, err := RepoExists("testRepo", mockClient)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	// Negative test case: Generic failure
+	t.Run("DescribeRepositories_Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			DescribeRepositoriesFunc: func(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error) {
+				return nil, errors.New("some error message")
+			},
+		}
+		_, err := RepoExists("testRepo", mockClient)
+		assert.Error(t, err)
+	})
+}
+
+func TestPing(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			DescribeRepositoriesFunc: func(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error) {
+				assert.Equal(t, int32(1), *params.MaxResults)
+				assert.Empty(t, params.RepositoryNames)
+				return &ecr.DescribeRepositoriesOutput{}, nil
+			},
+		}
+		assert.NoError(t, Ping(context.Background(), mockClient))
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			DescribeRepositoriesFunc: func(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error) {
+				return nil, errors.New("AccessDeniedException")
+			},
+		}
+		assert.EqualError(t, Ping(context.Background(), mockClient), "failed to reach ECR: AccessDeniedException")
+	})
+}
@@ -0,0 +1,132 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// ECRConfig holds the settings NewClientFromConfig builds an ECR client from.
+type ECRConfig struct {
+	Region string
+	// Endpoint sends all requests to this URL instead of AWS, e.g. LocalStack. Empty uses AWS.
+	Endpoint string
+	// MaxRetries is the number of times a failed request is retried. Zero keeps the SDK default.
+	MaxRetries int
+	// LogLevel is one of "" or "off", "retries", "requests" or "debug".
+	LogLevel    string
+	Credentials AWSCredentials
+	// RepositoryPrefix namespaces the repositories the functions of this package use with the returned
+	// client, e.g. "team" makes CreateRepo create "team/service" for "service". Empty uses the name as given.
+	RepositoryPrefix string
+}
+
+// NewClientFromConfig creates an ECR client from cfg alone, without reading the
+// environment or shared config files like config.LoadDefaultConfig does.
+func NewClientFromConfig(cfg ECRConfig) (ECRClientInterface, error) {
+	if cfg.Region == "" {
+		return nil, errors.New("region must not be empty")
+	}
+	if cfg.Credentials.AccessKeyID == "" || cfg.Credentials.SecretAccessKey == "" {
+		return nil, errors.New("access key ID and secret access key must not be empty")
+	}
+	if cfg.MaxRetries < 0 {
+		return nil, fmt.Errorf("max retries must not be negative, got %d", cfg.MaxRetries)
+	}
+	logMode, err := parseLogLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+	awsCfg := aws.Config{
+		Region: cfg.Region,
+		Credentials: credentials.NewStaticCredentialsProvider(
+			cfg.Credentials.AccessKeyID, cfg.Credentials.SecretAccessKey, cfg.Credentials.SessionToken),
+	}
+
+	opts := []ECRClientOption{WithLogMode(logMode)}
+	if cfg.Endpoint != "" {
+		opts = append(opts, WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.MaxRetries > 0 {
+		opts = append(opts, WithMaxRetries(cfg.MaxRetries))
+	}
+	return withRepositoryPrefix(NewClientWithOptions(awsCfg, opts...), cfg.RepositoryPrefix)
+}
+
+// RepositoryPrefixEnvVar optionally sets the repository prefix of the clients returned by
+// CreateECRClient, CreateECRClientFromEnv, CreateECRClientWithRole and the multi-region functions.
+const RepositoryPrefixEnvVar = "AUTOBUILD_ECR_REPOSITORY_PREFIX"
+
+// CreateECRClientFromEnv creates an ECR client with NewClientFromConfig from the region and credentials
+// of the default AWS config, AUTOBUILD_ECR_ENDPOINT and AUTOBUILD_ECR_REPOSITORY_PREFIX.
+// The credentials are resolved once, so temporary credentials need a new client once they expire.
+func CreateECRClientFromEnv() (ECRClientInterface, error) {
+	cfg, err := getAWSConfigFunc()
+	if err != nil {
+		return nil, err
+	}
+	creds, err := retrieveCredentials(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientFromConfig(ECRConfig{
+		Region:           cfg.Region,
+		Endpoint:         os.Getenv(ECREndpointEnvVar),
+		Credentials:      creds,
+		RepositoryPrefix: os.Getenv(RepositoryPrefixEnvVar),
+	})
+}
+
+// prefixedClient is an ECR client that carries the ECRConfig.RepositoryPrefix. Its methods pass
+// repository names through unchanged; the package functions add the prefix with repositoryName.
+type prefixedClient struct {
+	ECRClientInterface
+	prefix string
+}
+
+// RepositoryPrefix returns the prefix the package functions prepend to repository names.
+func (c *prefixedClient) RepositoryPrefix() string {
+	return c.prefix
+}
+
+// withRepositoryPrefix wraps client in a prefixedClient carrying prefix, or returns it unchanged
+// when prefix is empty. Every constructor of this package applies its prefix through it.
+func withRepositoryPrefix(client ECRClientInterface, prefix string) (ECRClientInterface, error) {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return client, nil
+	}
+	if err := ValidateECRRepoName(prefix); err != nil {
+		return nil, fmt.Errorf("invalid repository prefix: %w", err)
+	}
+	return &prefixedClient{ECRClientInterface: client, prefix: prefix}, nil
+}
+
+// repositoryName returns repoName with the repository prefix of ecrClient, if it has one.
+func repositoryName(repoName string, ecrClient ECRClientInterface) string {
+	if prefixed, ok := ecrClient.(interface{ RepositoryPrefix() string }); ok && prefixed.RepositoryPrefix() != "" {
+		return prefixed.RepositoryPrefix() + "/" + repoName
+	}
+	return repoName
+}
+
+// parseLogLevel maps an ECRConfig.LogLevel to the SDK log mode.
+func parseLogLevel(level string) (aws.ClientLogMode, error) {
+	switch strings.ToLower(level) {
+	case "", "off":
+		return 0, nil
+	case "retries":
+		return aws.LogRetries, nil
+	case "requests":
+		return aws.LogRetries | aws.LogRequest, nil
+	case "debug":
+		return aws.LogRetries | aws.LogRequestWithBody | aws.LogResponseWithBody | aws.LogSigning, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", level)
+	}
+}
@@ -0,0 +1,233 @@
+package ecr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var testCredentials = AWSCredentials{AccessKeyID: "test", SecretAccessKey: "test"}
+
+func TestNewClientFromConfig(t *testing.T) {
+	// Fail every request so the number of attempts shows the retry setting
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"__type": "ServerException", "message": "unavailable"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientFromConfig(ECRConfig{
+		Region:      "eu-west-1",
+		Endpoint:    server.URL,
+		MaxRetries:  2,
+		LogLevel:    "retries",
+		Credentials: testCredentials,
+	})
+	assert.NoError(t, err)
+
+	_, err = client.CreateRepository(context.Background(), &ecr.CreateRepositoryInput{RepositoryName: aws.String("testRepo")})
+	assert.Error(t, err)
+	assert.Equal(t, 3, requests)
+
+	options := client.(*ecr.Client).Options()
+	assert.Equal(t, "eu-west-1", options.Region)
+	assert.Equal(t, server.URL, aws.ToString(options.BaseEndpoint))
+	assert.Equal(t, aws.LogRetries, options.ClientLogMode)
+}
+
+func TestNewClientFromConfig_Defaults(t *testing.T) {
+	client, err := NewClientFromConfig(ECRConfig{Region: "us-east-1", Credentials: testCredentials})
+	assert.NoError(t, err)
+
+	options := client.(*ecr.Client).Options()
+	assert.Nil(t, options.BaseEndpoint)
+	assert.Equal(t, aws.ClientLogMode(0), options.ClientLogMode)
+	assert.Equal(t, 0, options.RetryMaxAttempts)
+}
+
+func TestNewClientFromConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      ECRConfig
+		expected string
+	}{
+		{
+			name:     "Missing Region",
+			cfg:      ECRConfig{Credentials: testCredentials},
+			expected: "region must not be empty",
+		},
+		{
+			name:     "Missing Credentials",
+			cfg:      ECRConfig{Region: "us-east-1"},
+			expected: "access key ID and secret access key must not be empty",
+		},
+		{
+			name:     "Negative Retries",
+			cfg:      ECRConfig{Region: "us-east-1", MaxRetries: -1, Credentials: testCredentials},
+			expected: "max retries must not be negative, got -1",
+		},
+		{
+			name:     "Unknown Log Level",
+			cfg:      ECRConfig{Region: "us-east-1", LogLevel: "verbose", Credentials: testCredentials},
+			expected: `unknown log level "verbose"`,
+		},
+		{
+			name:     "Invalid Repository Prefix",
+			cfg:      ECRConfig{Region: "us-east-1", RepositoryPrefix: "Team", Credentials: testCredentials},
+			expected: "invalid repository prefix: ECR repository names must be lowercase, got 'Team'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClientFromConfig(tt.cfg)
+			assert.Nil(t, client)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestNewClientFromConfig_RepositoryPrefix(t *testing.T) {
+	var repositoryName string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input struct{ RepositoryName string }
+		json.NewDecoder(r.Body).Decode(&input)
+		repositoryName = input.RepositoryName
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		fmt.Fprintf(w, `{"repository": {"repositoryName": %q}}`, input.RepositoryName)
+	}))
+	defer server.Close()
+
+	client, err := NewClientFromConfig(ECRConfig{
+		Region:           "us-east-1",
+		Endpoint:         server.URL,
+		RepositoryPrefix: "team/",
+		Credentials:      testCredentials,
+	})
+	assert.NoError(t, err)
+
+	output, err := CreateRepo("service", client)
+	assert.NoError(t, err)
+	assert.Equal(t, "team/service", repositoryName)
+	assert.Equal(t, "team/service", aws.ToString(output.Repository.RepositoryName))
+}
+
+func TestCreateRepo_RepositoryPrefix(t *testing.T) {
+	client := &prefixedClient{ECRClientInterface: &InMemoryECRClient{}, prefix: "team"}
+
+	_, err := CreateRepo("service", client)
+	assert.NoError(t, err)
+	_, found := client.ECRClientInterface.(*InMemoryECRClient).Repository("team/service")
+	assert.True(t, found, "expected the repository to be created under the prefix")
+
+	_, err = CreateRepo("Service", client)
+	assert.EqualError(t, err, "ECR repository names must be lowercase, got 'team/Service'")
+}
+
+func TestRepositoryPrefix_AppliedByRepositoryFunctions(t *testing.T) {
+	inMemory := &InMemoryECRClient{}
+	client := &prefixedClient{ECRClientInterface: inMemory, prefix: "team"}
+
+	_, err := CreateRepo("service", client)
+	assert.NoError(t, err)
+
+	exists, err := RepoExists("service", client)
+	assert.NoError(t, err)
+	assert.True(t, exists, "expected RepoExists to find the prefixed repository")
+
+	assert.NoError(t, UpdateImageTagMutability("service", false, client))
+	repo, _ := inMemory.Repository("team/service")
+	assert.Equal(t, types.ImageTagMutabilityMutable, repo.ImageTagMutability)
+
+	assert.NoError(t, SetLifecyclePolicy("service", `{"rules": []}`, client))
+	_, err = CreateRepoWithLifecycle("other", `{"rules": []}`, client)
+	assert.NoError(t, err)
+
+	assert.NoError(t, DeleteRepo("service", false, client))
+	_, found := inMemory.Repository("team/service")
+	assert.False(t, found, "expected DeleteRepo to delete the prefixed repository")
+}
+
+func TestCreateECRClientFromEnv(t *testing.T) {
+	originalGetAWSConfigFunc := getAWSConfigFunc
+	defer func() { getAWSConfigFunc = originalGetAWSConfigFunc }()
+	getAWSConfigFunc = func() (aws.Config, error) {
+		return aws.Config{
+			Region:      "eu-west-1",
+			Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+		}, nil
+	}
+
+	t.Setenv(RepositoryPrefixEnvVar, "team")
+	client, err := CreateECRClientFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "team/service", repositoryName("service", client))
+	assert.Equal(t, "eu-west-1", clientRegion(client))
+
+	t.Setenv(RepositoryPrefixEnvVar, "")
+	client, err = CreateECRClientFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "service", repositoryName("service", client))
+
+	getAWSConfigFunc = MockGetAWSConfig
+	_, err = CreateECRClientFromEnv()
+	assert.EqualError(t, err, "mocked error")
+}
+
+func TestConstructorsApplyRepositoryPrefix(t *testing.T) {
+	originalGetAWSConfigFunc, originalLoader := getAWSConfigFunc, globalAWSConfigLoader
+	defer func() { getAWSConfigFunc, globalAWSConfigLoader = originalGetAWSConfigFunc, originalLoader }()
+	getAWSConfigFunc = func() (aws.Config, error) {
+		return aws.Config{
+			Region:      "eu-west-1",
+			Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+		}, nil
+	}
+	globalAWSConfigLoader = stsConfigLoader{endpoint: "http://localhost"}
+	t.Setenv(RepositoryPrefixEnvVar, "team")
+
+	t.Run("CreateECRClient", func(t *testing.T) {
+		client, err := CreateECRClient()
+		assert.NoError(t, err)
+		assert.Equal(t, "team/service", repositoryName("service", client))
+	})
+
+	t.Run("CreateECRClient With Endpoint", func(t *testing.T) {
+		t.Setenv(ECREndpointEnvVar, "http://localhost:4566")
+		client, err := CreateECRClient()
+		assert.NoError(t, err)
+		assert.Equal(t, "team/service", repositoryName("service", client))
+	})
+
+	t.Run("CreateECRClientWithRole", func(t *testing.T) {
+		client, err := CreateECRClientWithRole("arn:aws:iam::123456789012:role/ecr-admin", "")
+		assert.NoError(t, err)
+		assert.Equal(t, "team/service", repositoryName("service", client))
+	})
+
+	t.Run("Region Clients", func(t *testing.T) {
+		clients, err := newRegionClients(context.Background(), []string{"us-east-1", "eu-west-1"})
+		assert.NoError(t, err)
+		for region, client := range clients {
+			assert.Equal(t, "team/service", repositoryName("service", client), region)
+		}
+	})
+
+	t.Run("Invalid Prefix", func(t *testing.T) {
+		t.Setenv(RepositoryPrefixEnvVar, "Team!")
+		_, err := CreateECRClient()
+		assert.ErrorContains(t, err, "invalid repository prefix")
+	})
+}
@@ -1,8 +1,21 @@
 package ecr
 
+import "time"
+
 // AWSCredentials represents AWS credentials.
 type AWSCredentials struct {
 	AccessKeyID     string
 	SecretAccessKey string
 	SessionToken    string
+	// ExpiresAt is when temporary credentials expire, zero for credentials that do not expire.
+	ExpiresAt time.Time
+}
+
+// WillExpireSoon reports whether the credentials expire within the given duration.
+// Credentials without an expiry never expire soon.
+func (c AWSCredentials) WillExpireSoon(within time.Duration) bool {
+	if c.ExpiresAt.IsZero() {
+		return false
+	}
+	return time.Until(c.ExpiresAt) <= within
 }
@@ -0,0 +1,106 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// scanPollInterval is the time DescribeImageScanFindings waits between polls of an unfinished scan.
+const scanPollInterval = 5 * time.Second
+
+// ScanFindings is the result of a completed image scan.
+type ScanFindings struct {
+	// SeverityCounts maps each severity, e.g. CRITICAL, to the number of findings with it.
+	SeverityCounts map[string]int32
+	Findings       []types.ImageScanFinding
+}
+
+// Count returns the number of findings with the given severity.
+func (f ScanFindings) Count(severity types.FindingSeverity) int32 {
+	return f.SeverityCounts[string(severity)]
+}
+
+// StartImageScan starts a vulnerability scan of the image tagged imageTag in the repository.
+func StartImageScan(ctx context.Context, repoName, imageTag string, client ECRClientInterface) error {
+	repoName = repositoryName(repoName, client)
+	_, err := client.StartImageScan(ctx, &ecr.StartImageScanInput{
+		RepositoryName: aws.String(repoName),
+		ImageId:        &types.ImageIdentifier{ImageTag: aws.String(imageTag)},
+	})
+	if err != nil {
+		log.Printf("Failed to start image scan: %v", err)
+		return err
+	}
+
+	log.Printf("Image scan of %s:%s started.", repoName, imageTag)
+	return nil
+}
+
+// DescribeImageScanFindings waits until the scan of the image tagged imageTag completes and returns its findings.
+// It stops waiting when ctx is done and returns an error when the scan fails.
+func DescribeImageScanFindings(ctx context.Context, repoName, imageTag string, client ECRClientInterface) (ScanFindings, error) {
+	repoName = repositoryName(repoName, client)
+	for {
+		findings, done, err := describeImageScanFindings(ctx, repoName, imageTag, client)
+		if err != nil || done {
+			return findings, err
+		}
+
+		sleepFunc(scanPollInterval)
+		if err := ctx.Err(); err != nil {
+			return ScanFindings{}, fmt.Errorf("image scan of %s:%s did not complete: %w", repoName, imageTag, err)
+		}
+	}
+}
+
+// describeImageScanFindings fetches every page of the scan findings, reporting done once the scan completed.
+func describeImageScanFindings(ctx context.Context, repoName, imageTag string, client ECRClientInterface) (ScanFindings, bool, error) {
+	findings := ScanFindings{SeverityCounts: map[string]int32{}}
+	var nextToken *string
+	for {
+		output, err := client.DescribeImageScanFindings(ctx, &ecr.DescribeImageScanFindingsInput{
+			RepositoryName: aws.String(repoName),
+			ImageId:        &types.ImageIdentifier{ImageTag: aws.String(imageTag)},
+			NextToken:      nextToken,
+		})
+		if err != nil {
+			// A scan that was just started may not be visible yet
+			var notFound *types.ScanNotFoundException
+			if errors.As(err, &notFound) {
+				return ScanFindings{}, false, nil
+			}
+			return ScanFindings{}, false, fmt.Errorf("failed to describe image scan findings: %v", err)
+		}
+
+		if output.ImageScanStatus != nil {
+			switch output.ImageScanStatus.Status {
+			case types.ScanStatusInProgress, types.ScanStatusPending:
+				return ScanFindings{}, false, nil
+			case types.ScanStatusComplete, types.ScanStatusActive:
+			default:
+				return ScanFindings{}, false, fmt.Errorf("image scan of %s:%s ended with status %s: %s",
+					repoName, imageTag, output.ImageScanStatus.Status, aws.ToString(output.ImageScanStatus.Description))
+			}
+		}
+
+		if output.ImageScanFindings != nil {
+			// The severity counts cover every finding, so they are the same on each page
+			for severity, count := range output.ImageScanFindings.FindingSeverityCounts {
+				findings.SeverityCounts[severity] = count
+			}
+			findings.Findings = append(findings.Findings, output.ImageScanFindings.Findings...)
+		}
+
+		nextToken = output.NextToken
+		if nextToken == nil {
+			return findings, true, nil
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartImageScan(t *testing.T) {
+	t.Run("StartImageScan_Success", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			StartImageScanFunc: func(ctx context.Context, params *ecr.StartImageScanInput, optFns ...func(*ecr.Options)) (*ecr.StartImageScanOutput, error) {
+				assert.Equal(t, "test-repo", aws.ToString(params.RepositoryName))
+				assert.Equal(t, "v1.0.0", aws.ToString(params.ImageId.ImageTag))
+				return &ecr.StartImageScanOutput{}, nil
+			},
+		}
+		assert.NoError(t, StartImageScan(context.Background(), "test-repo", "v1.0.0", mockClient))
+	})
+
+	t.Run("StartImageScan_Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			StartImageScanFunc: func(ctx context.Context, params *ecr.StartImageScanInput, optFns ...func(*ecr.Options)) (*ecr.StartImageScanOutput, error) {
+				return nil, &types.LimitExceededException{Message: aws.String("one scan per image per day")}
+			},
+		}
+		assert.Error(t, StartImageScan(context.Background(), "test-repo", "v1.0.0", mockClient))
+	})
+}
+
+func TestDescribeImageScanFindings(t *testing.T) {
+	originalSleepFunc := sleepFunc
+	var sleeps []time.Duration
+	sleepFunc = func(d time.Duration) { sleeps = append(sleeps, d) }
+	defer func() { sleepFunc = originalSleepFunc }()
+
+	// The scan is not visible, then in progress, then complete with two pages of findings
+	responses := []func() (*ecr.DescribeImageScanFindingsOutput, error){
+		func() (*ecr.DescribeImageScanFindingsOutput, error) {
+			return nil, &types.ScanNotFoundException{}
+		},
+		func() (*ecr.DescribeImageScanFindingsOutput, error) {
+			return &ecr.DescribeImageScanFindingsOutput{ImageScanStatus: &types.ImageScanStatus{Status: types.ScanStatusInProgress}}, nil
+		},
+		func() (*ecr.DescribeImageScanFindingsOutput, error) {
+			return &ecr.DescribeImageScanFindingsOutput{
+				ImageScanStatus: &types.ImageScanStatus{Status: types.ScanStatusComplete},
+				ImageScanFindings: &types.ImageScanFindings{
+					FindingSeverityCounts: map[string]int32{"CRITICAL": 1, "LOW": 1},
+					Findings:              []types.ImageScanFinding{{Name: aws.String("CVE-2024-0001"), Severity: types.FindingSeverityCritical}},
+				},
+				NextToken: aws.String("page-2"),
+			}, nil
+		},
+		func() (*ecr.DescribeImageScanFindingsOutput, error) {
+			return &ecr.DescribeImageScanFindingsOutput{
+				ImageScanStatus: &types.ImageScanStatus{Status: types.ScanStatusComplete},
+				ImageScanFindings: &types.ImageScanFindings{
+					FindingSeverityCounts: map[string]int32{"CRITICAL": 1, "LOW": 1},
+					Findings:              []types.ImageScanFinding{{Name: aws.String("CVE-2024-0002"), Severity: types.FindingSeverityLow}},
+				},
+			}, nil
+		},
+	}
+	calls := 0
+	mockClient := &MockECRClient{
+		DescribeImageScanFindingsFunc: func(ctx context.Context, params *ecr.DescribeImageScanFindingsInput, optFns ...func(*ecr.Options)) (*ecr.DescribeImageScanFindingsOutput, error) {
+			assert.Equal(t, "v1.0.0", aws.ToString(params.ImageId.ImageTag))
+			response := responses[calls]
+			calls++
+			return response()
+		},
+	}
+
+	findings, err := DescribeImageScanFindings(context.Background(), "test-repo", "v1.0.0", mockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), findings.Count(types.FindingSeverityCritical))
+	assert.Len(t, findings.Findings, 2)
+	assert.Equal(t, []time.Duration{scanPollInterval, scanPollInterval}, sleeps)
+}
+
+func TestDescribeImageScanFindings_Errors(t *testing.T) {
+	originalSleepFunc := sleepFunc
+	sleepFunc = func(d time.Duration) {}
+	defer func() { sleepFunc = originalSleepFunc }()
+
+	t.Run("Scan_Failed", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			DescribeImageScanFindingsFunc: func(ctx context.Context, params *ecr.DescribeImageScanFindingsInput, optFns ...func(*ecr.Options)) (*ecr.DescribeImageScanFindingsOutput, error) {
+				return &ecr.DescribeImageScanFindingsOutput{ImageScanStatus: &types.ImageScanStatus{
+					Status:      types.ScanStatusUnsupportedImage,
+					Description: aws.String("UnsupportedImageError"),
+				}}, nil
+			},
+		}
+		_, err := DescribeImageScanFindings(context.Background(), "test-repo", "v1.0.0", mockClient)
+		assert.EqualError(t, err, "image scan of test-repo:v1.0.0 ended with status UNSUPPORTED_IMAGE: UnsupportedImageError")
+	})
+
+	t.Run("Describe_Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			DescribeImageScanFindingsFunc: func(ctx context.Context, params *ecr.DescribeImageScanFindingsInput, optFns ...func(*ecr.Options)) (*ecr.DescribeImageScanFindingsOutput, error) {
+				return nil, errors.New("some error message")
+			},
+		}
+		_, err := DescribeImageScanFindings(context.Background(), "test-repo", "v1.0.0", mockClient)
+		assert.EqualError(t, err, "failed to describe image scan findings: some error message")
+	})
+
+	t.Run("Context_Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		mockClient := &MockECRClient{
+			DescribeImageScanFindingsFunc: func(ctx context.Context, params *ecr.DescribeImageScanFindingsInput, optFns ...func(*ecr.Options)) (*ecr.DescribeImageScanFindingsOutput, error) {
+				cancel()
+				return &ecr.DescribeImageScanFindingsOutput{ImageScanStatus: &types.ImageScanStatus{Status: types.ScanStatusInProgress}}, nil
+			},
+		}
+		_, err := DescribeImageScanFindings(ctx, "test-repo", "v1.0.0", mockClient)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
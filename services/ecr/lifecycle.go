@@ -0,0 +1,61 @@
+package ecr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+)
+
+// ErrInvalidLifecyclePolicy is returned for a lifecycle policy that is not a JSON object.
+var ErrInvalidLifecyclePolicy = errors.New("lifecycle policy must be a JSON object")
+
+// SetLifecyclePolicy sets the lifecycle policy of an existing repository, e.g. to expire untagged images.
+// The policy is checked to be JSON before calling ECR; ECR validates the rules themselves.
+func SetLifecyclePolicy(repoName string, policy string, ecrClient ECRClientInterface) error {
+	if err := validateLifecyclePolicy(policy); err != nil {
+		return err
+	}
+	repoName = repositoryName(repoName, ecrClient)
+
+	_, err := ecrClient.PutLifecyclePolicy(context.Background(), &ecr.PutLifecyclePolicyInput{
+		RepositoryName:      aws.String(repoName),
+		LifecyclePolicyText: aws.String(policy),
+	})
+	if err != nil {
+		log.Printf("Failed to set lifecycle policy: %v", err)
+		return err
+	}
+
+	log.Printf("Lifecycle policy of repository %s set successfully.", repoName)
+	return nil
+}
+
+// CreateRepoWithLifecycle creates the repository like CreateRepo and sets its lifecycle policy.
+// An invalid policy is rejected before the repository is created.
+func CreateRepoWithLifecycle(repoName string, policy string, ecrClient ECRClientInterface) (*ecr.CreateRepositoryOutput, error) {
+	if err := validateLifecyclePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	output, err := CreateRepo(repoName, ecrClient)
+	if err != nil {
+		return nil, err
+	}
+	if err := SetLifecyclePolicy(repoName, policy, ecrClient); err != nil {
+		return output, err
+	}
+	return output, nil
+}
+
+// validateLifecyclePolicy makes sure policy is a JSON object.
+func validateLifecyclePolicy(policy string) error {
+	var document map[string]any
+	if err := json.Unmarshal([]byte(policy), &document); err != nil {
+		return ErrInvalidLifecyclePolicy
+	}
+	return nil
+}
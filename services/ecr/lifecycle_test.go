@@ -0,0 +1,118 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testLifecyclePolicy = `{"rules":[{"rulePriority":1,"selection":{"tagStatus":"untagged","countType":"sinceImagePushed","countUnit":"days","countNumber":14},"action":{"type":"expire"}}]}`
+
+func TestSetLifecyclePolicy(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			PutLifecyclePolicyFunc: func(ctx context.Context, params *ecr.PutLifecyclePolicyInput, optFns ...func(*ecr.Options)) (*ecr.PutLifecyclePolicyOutput, error) {
+				assert.Equal(t, "testRepo", aws.ToString(params.RepositoryName))
+				assert.Equal(t, testLifecyclePolicy, aws.ToString(params.LifecyclePolicyText))
+				return &ecr.PutLifecyclePolicyOutput{}, nil
+			},
+		}
+		assert.NoError(t, SetLifecyclePolicy("testRepo", testLifecyclePolicy, mockClient))
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			PutLifecyclePolicyFunc: func(ctx context.Context, params *ecr.PutLifecyclePolicyInput, optFns ...func(*ecr.Options)) (*ecr.PutLifecyclePolicyOutput, error) {
+				return nil, errors.New("some error message")
+			},
+		}
+		assert.EqualError(t, SetLifecyclePolicy("testRepo", testLifecyclePolicy, mockClient), "some error message")
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			PutLifecyclePolicyFunc: func(ctx context.Context, params *ecr.PutLifecyclePolicyInput, optFns ...func(*ecr.Options)) (*ecr.PutLifecyclePolicyOutput, error) {
+				t.Error("PutLifecyclePolicy should not be called with an invalid policy")
+				return nil, nil
+			},
+		}
+		for _, policy := range []string{"", "{rules", `["rules"]`} {
+			assert.ErrorIs(t, SetLifecyclePolicy("testRepo", policy, mockClient), ErrInvalidLifecyclePolicy)
+		}
+	})
+}
+
+func TestCreateRepoWithLifecycle(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		var calls []string
+		mockClient := &MockECRClient{
+			CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+				calls = append(calls, "CreateRepository")
+				return &ecr.CreateRepositoryOutput{Repository: &types.Repository{RepositoryName: params.RepositoryName}}, nil
+			},
+			PutLifecyclePolicyFunc: func(ctx context.Context, params *ecr.PutLifecyclePolicyInput, optFns ...func(*ecr.Options)) (*ecr.PutLifecyclePolicyOutput, error) {
+				calls = append(calls, "PutLifecyclePolicy")
+				return &ecr.PutLifecyclePolicyOutput{}, nil
+			},
+		}
+		output, err := CreateRepoWithLifecycle("test-repo", testLifecyclePolicy, mockClient)
+		assert.NoError(t, err)
+		assert.Equal(t, "test-repo", aws.ToString(output.Repository.RepositoryName))
+		assert.Equal(t, []string{"CreateRepository", "PutLifecyclePolicy"}, calls)
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+				t.Error("CreateRepository should not be called with an invalid policy")
+				return nil, nil
+			},
+		}
+		_, err := CreateRepoWithLifecycle("test-repo", "not json", mockClient)
+		assert.ErrorIs(t, err, ErrInvalidLifecyclePolicy)
+	})
+
+	t.Run("Create Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+				return nil, errors.New("create failed")
+			},
+			PutLifecyclePolicyFunc: func(ctx context.Context, params *ecr.PutLifecyclePolicyInput, optFns ...func(*ecr.Options)) (*ecr.PutLifecyclePolicyOutput, error) {
+				t.Error("PutLifecyclePolicy should not be called when the repository was not created")
+				return nil, nil
+			},
+		}
+		_, err := CreateRepoWithLifecycle("test-repo", testLifecyclePolicy, mockClient)
+		assert.Error(t, err)
+	})
+
+	t.Run("Policy Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+				return &ecr.CreateRepositoryOutput{Repository: &types.Repository{RepositoryName: params.RepositoryName}}, nil
+			},
+			PutLifecyclePolicyFunc: func(ctx context.Context, params *ecr.PutLifecyclePolicyInput, optFns ...func(*ecr.Options)) (*ecr.PutLifecyclePolicyOutput, error) {
+				return nil, errors.New("some error message")
+			},
+		}
+		output, err := CreateRepoWithLifecycle("test-repo", testLifecyclePolicy, mockClient)
+		assert.EqualError(t, err, "some error message")
+		assert.NotNil(t, output, "expected the created repository to be returned")
+	})
+}
+
+func TestInMemoryECRClient_LifecyclePolicy(t *testing.T) {
+	client := &InMemoryECRClient{}
+	_, err := CreateRepoWithLifecycle("test-repo", testLifecyclePolicy, client)
+	assert.NoError(t, err)
+	assert.Equal(t, testLifecyclePolicy, client.LifecyclePolicy("test-repo"))
+
+	err = SetLifecyclePolicy("missing-repo", testLifecyclePolicy, client)
+	var notFound *types.RepositoryNotFoundException
+	assert.True(t, errors.As(err, &notFound), "expected RepositoryNotFoundException, got %v", err)
+}
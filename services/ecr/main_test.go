@@ -0,0 +1,16 @@
+package ecr
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+// TestMain runs the tests with GOMAXPROCS set to 4, so the scheduling under the race detector is
+// alike across machines. It does not bound the t.Parallel tests: the -parallel flag does, and its
+// default is taken from GOMAXPROCS before TestMain runs. Tests that replace package-level mocks
+// therefore must not call t.Parallel.
+func TestMain(m *testing.M) {
+	runtime.GOMAXPROCS(4)
+	os.Exit(m.Run())
+}
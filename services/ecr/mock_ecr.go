@@ -0,0 +1,338 @@
+package ecr
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// inMemoryRegistryID and inMemoryRegion are used in the URIs and ARNs of InMemoryECRClient repositories.
+const (
+	inMemoryRegistryID = "123456789012"
+	inMemoryRegion     = "us-east-1"
+)
+
+var _ ECRClientInterface = (*InMemoryECRClient)(nil)
+
+// InMemoryECRClient is an ECRClientInterface that keeps repositories in memory, for tests that
+// need to check what was created rather than stub individual calls. The zero value is ready to use.
+type InMemoryECRClient struct {
+	mu           sync.Mutex
+	repositories map[string]*inMemoryRepository
+}
+
+type inMemoryRepository struct {
+	repository      types.Repository
+	policy          string
+	lifecyclePolicy string
+	images          []types.ImageIdentifier
+}
+
+// Repository returns the repository created with name, if any.
+func (c *InMemoryECRClient) Repository(name string) (types.Repository, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	repo, ok := c.repositories[name]
+	if !ok {
+		return types.Repository{}, false
+	}
+	return repo.repository, true
+}
+
+// PutImage adds an image tagged tag to the repository so image listing and deletion can be tested.
+func (c *InMemoryECRClient) PutImage(repoName, tag string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	repo, ok := c.repositories[repoName]
+	if !ok {
+		return repositoryNotFound(repoName)
+	}
+	repo.images = append(repo.images, types.ImageIdentifier{
+		ImageTag:    aws.String(tag),
+		ImageDigest: aws.String(fmt.Sprintf("sha256:%064d", len(repo.images)+1)),
+	})
+	return nil
+}
+
+// AssertRepositoryExists fails t when no repository named name was created.
+func (c *InMemoryECRClient) AssertRepositoryExists(t *testing.T, name string) {
+	t.Helper()
+	if _, ok := c.Repository(name); !ok {
+		t.Errorf("expected ECR repository %q to exist, got %v", name, c.repositoryNames())
+	}
+}
+
+// AssertRepositoryCount fails t unless exactly n repositories exist.
+func (c *InMemoryECRClient) AssertRepositoryCount(t *testing.T, n int) {
+	t.Helper()
+	if names := c.repositoryNames(); len(names) != n {
+		t.Errorf("expected %d ECR repositories, got %d: %v", n, len(names), names)
+	}
+}
+
+// repositoryNames returns the sorted names of all repositories.
+func (c *InMemoryECRClient) repositoryNames() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.repositories))
+	for name := range c.repositories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *InMemoryECRClient) CreateRepository(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := aws.ToString(params.RepositoryName)
+	if _, ok := c.repositories[name]; ok {
+		return nil, &types.RepositoryAlreadyExistsException{Message: aws.String(fmt.Sprintf("The repository with name '%s' already exists", name))}
+	}
+	if c.repositories == nil {
+		c.repositories = map[string]*inMemoryRepository{}
+	}
+
+	repo := types.Repository{
+		RepositoryName:             aws.String(name),
+		RegistryId:                 aws.String(inMemoryRegistryID),
+		RepositoryArn:              aws.String(fmt.Sprintf("arn:aws:ecr:%s:%s:repository/%s", inMemoryRegion, inMemoryRegistryID, name)),
+		RepositoryUri:              aws.String(fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s", inMemoryRegistryID, inMemoryRegion, name)),
+		ImageTagMutability:         params.ImageTagMutability,
+		ImageScanningConfiguration: params.ImageScanningConfiguration,
+		EncryptionConfiguration:    params.EncryptionConfiguration,
+		CreatedAt:                  aws.Time(time.Now()),
+	}
+	c.repositories[name] = &inMemoryRepository{repository: repo}
+	return &ecr.CreateRepositoryOutput{Repository: &repo}, nil
+}
+
+func (c *InMemoryECRClient) GetAuthorizationToken(ctx context.Context, params *ecr.GetAuthorizationTokenInput, optFns ...func(*ecr.Options)) (*ecr.GetAuthorizationTokenOutput, error) {
+	return &ecr.GetAuthorizationTokenOutput{
+		AuthorizationData: []types.AuthorizationData{{
+			AuthorizationToken: aws.String(base64.StdEncoding.EncodeToString([]byte("AWS:in-memory"))),
+			ProxyEndpoint:      aws.String(fmt.Sprintf("https://%s.dkr.ecr.%s.amazonaws.com", inMemoryRegistryID, inMemoryRegion)),
+			ExpiresAt:          aws.Time(time.Now().Add(12 * time.Hour)),
+		}},
+	}, nil
+}
+
+func (c *InMemoryECRClient) DeleteRepository(ctx context.Context, params *ecr.DeleteRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.DeleteRepositoryOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := aws.ToString(params.RepositoryName)
+	repo, ok := c.repositories[name]
+	if !ok {
+		return nil, repositoryNotFound(name)
+	}
+	if len(repo.images) > 0 && !params.Force {
+		return nil, &types.RepositoryNotEmptyException{Message: aws.String(fmt.Sprintf("The repository with name '%s' cannot be deleted because it still contains images", name))}
+	}
+	delete(c.repositories, name)
+	return &ecr.DeleteRepositoryOutput{Repository: &repo.repository}, nil
+}
+
+func (c *InMemoryECRClient) DescribeRepositories(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	output := &ecr.DescribeRepositoriesOutput{}
+	if len(params.RepositoryNames) == 0 {
+		for _, repo := range c.repositories {
+			output.Repositories = append(output.Repositories, repo.repository)
+		}
+		sort.Slice(output.Repositories, func(i, j int) bool {
+			return aws.ToString(output.Repositories[i].RepositoryName) < aws.ToString(output.Repositories[j].RepositoryName)
+		})
+		return output, nil
+	}
+	for _, name := range params.RepositoryNames {
+		repo, ok := c.repositories[name]
+		if !ok {
+			return nil, repositoryNotFound(name)
+		}
+		output.Repositories = append(output.Repositories, repo.repository)
+	}
+	return output, nil
+}
+
+func (c *InMemoryECRClient) ListImages(ctx context.Context, params *ecr.ListImagesInput, optFns ...func(*ecr.Options)) (*ecr.ListImagesOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	repo, ok := c.repositories[aws.ToString(params.RepositoryName)]
+	if !ok {
+		return nil, repositoryNotFound(aws.ToString(params.RepositoryName))
+	}
+	return &ecr.ListImagesOutput{ImageIds: append([]types.ImageIdentifier(nil), repo.images...)}, nil
+}
+
+func (c *InMemoryECRClient) BatchDeleteImage(ctx context.Context, params *ecr.BatchDeleteImageInput, optFns ...func(*ecr.Options)) (*ecr.BatchDeleteImageOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	repo, ok := c.repositories[aws.ToString(params.RepositoryName)]
+	if !ok {
+		return nil, repositoryNotFound(aws.ToString(params.RepositoryName))
+	}
+
+	output := &ecr.BatchDeleteImageOutput{}
+	for _, id := range params.ImageIds {
+		index := -1
+		for i, image := range repo.images {
+			if imageMatches(image, id) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			output.Failures = append(output.Failures, types.ImageFailure{
+				ImageId:       &id,
+				FailureCode:   types.ImageFailureCodeImageNotFound,
+				FailureReason: aws.String("Requested image not found"),
+			})
+			continue
+		}
+		output.ImageIds = append(output.ImageIds, repo.images[index])
+		repo.images = append(repo.images[:index], repo.images[index+1:]...)
+	}
+	return output, nil
+}
+
+func (c *InMemoryECRClient) GetRepositoryPolicy(ctx context.Context, params *ecr.GetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.GetRepositoryPolicyOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := aws.ToString(params.RepositoryName)
+	repo, ok := c.repositories[name]
+	if !ok {
+		return nil, repositoryNotFound(name)
+	}
+	if repo.policy == "" {
+		return nil, &types.RepositoryPolicyNotFoundException{Message: aws.String(fmt.Sprintf("Repository policy does not exist for the repository with name '%s'", name))}
+	}
+	return &ecr.GetRepositoryPolicyOutput{
+		RepositoryName: aws.String(name),
+		RegistryId:     aws.String(inMemoryRegistryID),
+		PolicyText:     aws.String(repo.policy),
+	}, nil
+}
+
+func (c *InMemoryECRClient) SetRepositoryPolicy(ctx context.Context, params *ecr.SetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.SetRepositoryPolicyOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := aws.ToString(params.RepositoryName)
+	repo, ok := c.repositories[name]
+	if !ok {
+		return nil, repositoryNotFound(name)
+	}
+	repo.policy = aws.ToString(params.PolicyText)
+	return &ecr.SetRepositoryPolicyOutput{
+		RepositoryName: aws.String(name),
+		RegistryId:     aws.String(inMemoryRegistryID),
+		PolicyText:     params.PolicyText,
+	}, nil
+}
+
+// StartImageScan starts a scan that completes immediately without findings.
+func (c *InMemoryECRClient) StartImageScan(ctx context.Context, params *ecr.StartImageScanInput, optFns ...func(*ecr.Options)) (*ecr.StartImageScanOutput, error) {
+	if _, err := c.findImage(aws.ToString(params.RepositoryName), params.ImageId); err != nil {
+		return nil, err
+	}
+	return &ecr.StartImageScanOutput{
+		RepositoryName:  params.RepositoryName,
+		RegistryId:      aws.String(inMemoryRegistryID),
+		ImageId:         params.ImageId,
+		ImageScanStatus: &types.ImageScanStatus{Status: types.ScanStatusInProgress},
+	}, nil
+}
+
+// DescribeImageScanFindings reports a completed scan without findings for any existing image.
+func (c *InMemoryECRClient) DescribeImageScanFindings(ctx context.Context, params *ecr.DescribeImageScanFindingsInput, optFns ...func(*ecr.Options)) (*ecr.DescribeImageScanFindingsOutput, error) {
+	image, err := c.findImage(aws.ToString(params.RepositoryName), params.ImageId)
+	if err != nil {
+		return nil, err
+	}
+	return &ecr.DescribeImageScanFindingsOutput{
+		RepositoryName:    params.RepositoryName,
+		RegistryId:        aws.String(inMemoryRegistryID),
+		ImageId:           &image,
+		ImageScanStatus:   &types.ImageScanStatus{Status: types.ScanStatusComplete},
+		ImageScanFindings: &types.ImageScanFindings{FindingSeverityCounts: map[string]int32{}},
+	}, nil
+}
+
+// PutImageTagMutability changes the tag mutability of an existing repository.
+func (c *InMemoryECRClient) PutImageTagMutability(ctx context.Context, params *ecr.PutImageTagMutabilityInput, optFns ...func(*ecr.Options)) (*ecr.PutImageTagMutabilityOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := aws.ToString(params.RepositoryName)
+	repo, ok := c.repositories[name]
+	if !ok {
+		return nil, repositoryNotFound(name)
+	}
+	repo.repository.ImageTagMutability = params.ImageTagMutability
+	return &ecr.PutImageTagMutabilityOutput{
+		RepositoryName:     aws.String(name),
+		RegistryId:         aws.String(inMemoryRegistryID),
+		ImageTagMutability: params.ImageTagMutability,
+	}, nil
+}
+
+// PutLifecyclePolicy sets the lifecycle policy of an existing repository.
+func (c *InMemoryECRClient) PutLifecyclePolicy(ctx context.Context, params *ecr.PutLifecyclePolicyInput, optFns ...func(*ecr.Options)) (*ecr.PutLifecyclePolicyOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := aws.ToString(params.RepositoryName)
+	repo, ok := c.repositories[name]
+	if !ok {
+		return nil, repositoryNotFound(name)
+	}
+	repo.lifecyclePolicy = aws.ToString(params.LifecyclePolicyText)
+	return &ecr.PutLifecyclePolicyOutput{
+		RepositoryName:      aws.String(name),
+		RegistryId:          aws.String(inMemoryRegistryID),
+		LifecyclePolicyText: params.LifecyclePolicyText,
+	}, nil
+}
+
+// LifecyclePolicy returns the lifecycle policy set on the repository, empty when none is set.
+func (c *InMemoryECRClient) LifecyclePolicy(name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if repo, ok := c.repositories[name]; ok {
+		return repo.lifecyclePolicy
+	}
+	return ""
+}
+
+// findImage returns the image of the repository matching id by tag or digest.
+func (c *InMemoryECRClient) findImage(repoName string, id *types.ImageIdentifier) (types.ImageIdentifier, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	repo, ok := c.repositories[repoName]
+	if !ok {
+		return types.ImageIdentifier{}, repositoryNotFound(repoName)
+	}
+	for _, image := range repo.images {
+		if id != nil && imageMatches(image, *id) {
+			return image, nil
+		}
+	}
+	return types.ImageIdentifier{}, &types.ImageNotFoundException{Message: aws.String(fmt.Sprintf("The image does not exist in the repository with name '%s'", repoName))}
+}
+
+// imageMatches reports whether image has the tag or digest of id.
+func imageMatches(image, id types.ImageIdentifier) bool {
+	return (id.ImageTag != nil && aws.ToString(image.ImageTag) == aws.ToString(id.ImageTag)) ||
+		(id.ImageDigest != nil && aws.ToString(image.ImageDigest) == aws.ToString(id.ImageDigest))
+}
+
+// repositoryNotFound returns the error ECR returns for a missing repository.
+func repositoryNotFound(name string) error {
+	return &types.RepositoryNotFoundException{Message: aws.String(fmt.Sprintf("The repository with name '%s' does not exist", name))}
+}
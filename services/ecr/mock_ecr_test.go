@@ -0,0 +1,75 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryECRClient_CreateAndDelete(t *testing.T) {
+	client := &InMemoryECRClient{}
+
+	output, err := CreateRepo("test-repo", client)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789012.dkr.ecr.us-east-1.amazonaws.com/test-repo", aws.ToString(output.Repository.RepositoryUri))
+	client.AssertRepositoryExists(t, "test-repo")
+	client.AssertRepositoryCount(t, 1)
+
+	// CreateRepo asks for immutable tags and scan on push
+	repo, _ := client.Repository("test-repo")
+	assert.Equal(t, types.ImageTagMutabilityImmutable, repo.ImageTagMutability)
+	assert.True(t, repo.ImageScanningConfiguration.ScanOnPush)
+
+	_, err = CreateRepo("test-repo", client)
+	var alreadyExists *types.RepositoryAlreadyExistsException
+	assert.True(t, errors.As(err, &alreadyExists), "expected RepositoryAlreadyExistsException, got %v", err)
+
+	exists, err := RepoExists("test-repo", client)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.NoError(t, DeleteRepo("test-repo", false, client))
+	client.AssertRepositoryCount(t, 0)
+
+	exists, err = RepoExists("test-repo", client)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestInMemoryECRClient_Images(t *testing.T) {
+	client := &InMemoryECRClient{}
+	_, err := CreateRepo("test-repo", client)
+	assert.NoError(t, err)
+	assert.NoError(t, client.PutImage("test-repo", "v1.0.0"))
+	assert.NoError(t, client.PutImage("test-repo", "v1.1.0"))
+
+	// A repository with images is only deleted with Force or after draining it
+	var notEmpty *types.RepositoryNotEmptyException
+	assert.True(t, errors.As(DeleteRepo("test-repo", false, client), &notEmpty))
+
+	findings, err := DescribeImageScanFindings(context.Background(), "test-repo", "v1.0.0", client)
+	assert.NoError(t, err)
+	assert.Empty(t, findings.Findings)
+
+	assert.NoError(t, DeleteRepoWithOptions("test-repo", DeleteRepoOptions{DrainFirst: true}, client))
+	client.AssertRepositoryCount(t, 0)
+}
+
+func TestInMemoryECRClient_Policy(t *testing.T) {
+	client := &InMemoryECRClient{}
+	_, err := CreateRepo("test-repo", client)
+	assert.NoError(t, err)
+
+	policy, err := GetRepositoryPolicy("test-repo", client)
+	assert.NoError(t, err)
+	assert.Empty(t, policy)
+
+	assert.NoError(t, UpsertRepositoryPolicy("test-repo", pullPolicy, client))
+	policy, err = GetRepositoryPolicy("test-repo", client)
+	assert.NoError(t, err)
+	assert.JSONEq(t, pullPolicy, policy)
+}
@@ -0,0 +1,136 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+)
+
+// newRegionClientFunc builds the per-region clients of the multi-region functions and can be replaced in tests.
+var newRegionClientFunc = NewClientFromConfig
+
+// MultiRegionResult is returned by CreateRepoMultiRegion.
+type MultiRegionResult struct {
+	// Created holds the output of CreateRepo per region the repository was created in.
+	Created map[string]*ecr.CreateRepositoryOutput
+	// Existing lists the regions the repository already existed in, sorted.
+	Existing []string
+}
+
+// Consistent reports whether the repository existed in none or all of the regions before
+// CreateRepoMultiRegion ran, i.e. whether the name did not clash with a repository created separately.
+func (r MultiRegionResult) Consistent() bool {
+	return len(r.Existing) == 0 || len(r.Created) == 0
+}
+
+// CheckRepoExistsInRegions checks concurrently whether repoName exists in each region, with clients
+// built by NewClientFromConfig from the default AWS credentials and AUTOBUILD_ECR_ENDPOINT.
+// The returned map holds the regions that could be checked; the errors of the others are joined.
+func CheckRepoExistsInRegions(repoName string, regions []string) (map[string]bool, error) {
+	clients, err := newRegionClients(context.Background(), regions)
+	if err != nil {
+		return nil, err
+	}
+	return repoExistsInRegions(repoName, clients)
+}
+
+// CreateRepoMultiRegion creates repoName in every region it does not exist in yet, so the name
+// refers to the same repository everywhere. Regions that already have it are reported in Existing
+// and logged when the others do not. An error is returned when a region cannot be checked, before
+// anything is created, and otherwise joins the errors of the regions the repository could not be created in.
+func CreateRepoMultiRegion(repoName string, regions []string) (MultiRegionResult, error) {
+	clients, err := newRegionClients(context.Background(), regions)
+	if err != nil {
+		return MultiRegionResult{}, err
+	}
+	exists, err := repoExistsInRegions(repoName, clients)
+	if err != nil {
+		return MultiRegionResult{}, err
+	}
+
+	result := MultiRegionResult{Created: map[string]*ecr.CreateRepositoryOutput{}}
+	var missing []string
+	for region, found := range exists {
+		if found {
+			result.Existing = append(result.Existing, region)
+		} else {
+			missing = append(missing, region)
+		}
+	}
+	sort.Strings(result.Existing)
+	sort.Strings(missing)
+	if len(result.Existing) > 0 && len(missing) > 0 {
+		log.Printf("Repository %s already exists in %v but not in %v", repoName, result.Existing, missing)
+	}
+
+	var errs []error
+	for _, region := range missing {
+		output, err := CreateRepo(repoName, clients[region])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("region %s: %w", region, err))
+			continue
+		}
+		result.Created[region] = output
+	}
+	return result, errors.Join(errs...)
+}
+
+// newRegionClients builds a client per region, resolving the default credentials once.
+// The clients use the repository prefix in AUTOBUILD_ECR_REPOSITORY_PREFIX.
+func newRegionClients(ctx context.Context, regions []string) (map[string]ECRClientInterface, error) {
+	if len(regions) == 0 {
+		return nil, errors.New("at least one region is required")
+	}
+	creds, err := GetAWSCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make(map[string]ECRClientInterface, len(regions))
+	for _, region := range regions {
+		client, err := newRegionClientFunc(ECRConfig{
+			Region:           region,
+			Endpoint:         os.Getenv(ECREndpointEnvVar),
+			Credentials:      creds,
+			RepositoryPrefix: os.Getenv(RepositoryPrefixEnvVar),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("region %s: %w", region, err)
+		}
+		clients[region] = client
+	}
+	return clients, nil
+}
+
+// repoExistsInRegions calls RepoExists with the client of every region concurrently.
+func repoExistsInRegions(repoName string, clients map[string]ECRClientInterface) (map[string]bool, error) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		exists = make(map[string]bool, len(clients))
+		errs   []error
+	)
+	for region, client := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found, err := RepoExists(repoName, client)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("region %s: %w", region, err))
+				return
+			}
+			exists[region] = found
+		}()
+	}
+	wg.Wait()
+	return exists, errors.Join(errs...)
+}
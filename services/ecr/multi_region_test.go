@@ -0,0 +1,127 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/stretchr/testify/assert"
+)
+
+// useRegionClients makes the multi-region functions use clients instead of building SDK clients.
+func useRegionClients(t *testing.T, clients map[string]ECRClientInterface) {
+	originalGetAWSConfigFunc, originalNewRegionClient := getAWSConfigFunc, newRegionClientFunc
+	t.Cleanup(func() { getAWSConfigFunc, newRegionClientFunc = originalGetAWSConfigFunc, originalNewRegionClient })
+
+	getAWSConfigFunc = func() (aws.Config, error) {
+		return aws.Config{Credentials: credentials.NewStaticCredentialsProvider("AKID", "secret", "")}, nil
+	}
+	newRegionClientFunc = func(cfg ECRConfig) (ECRClientInterface, error) {
+		assert.Equal(t, "AKID", cfg.Credentials.AccessKeyID)
+		client, ok := clients[cfg.Region]
+		if !ok {
+			return nil, errors.New("unknown region")
+		}
+		return client, nil
+	}
+}
+
+func TestCheckRepoExistsInRegions(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		west := &InMemoryECRClient{}
+		_, err := CreateRepo("test-repo", west)
+		assert.NoError(t, err)
+		useRegionClients(t, map[string]ECRClientInterface{"us-east-1": &InMemoryECRClient{}, "eu-west-1": west})
+
+		exists, err := CheckRepoExistsInRegions("test-repo", []string{"us-east-1", "eu-west-1"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"us-east-1": false, "eu-west-1": true}, exists)
+	})
+
+	t.Run("Region Failure", func(t *testing.T) {
+		failing := &MockECRClient{
+			DescribeRepositoriesFunc: func(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error) {
+				return nil, errors.New("access denied")
+			},
+		}
+		useRegionClients(t, map[string]ECRClientInterface{"us-east-1": &InMemoryECRClient{}, "eu-west-1": failing})
+
+		exists, err := CheckRepoExistsInRegions("test-repo", []string{"us-east-1", "eu-west-1"})
+		assert.EqualError(t, err, "region eu-west-1: access denied")
+		assert.Equal(t, map[string]bool{"us-east-1": false}, exists)
+	})
+
+	t.Run("Client Failure", func(t *testing.T) {
+		useRegionClients(t, map[string]ECRClientInterface{})
+
+		_, err := CheckRepoExistsInRegions("test-repo", []string{"us-east-1"})
+		assert.EqualError(t, err, "region us-east-1: unknown region")
+	})
+
+	t.Run("No Regions", func(t *testing.T) {
+		_, err := CheckRepoExistsInRegions("test-repo", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestCreateRepoMultiRegion(t *testing.T) {
+	t.Run("Creates Missing Regions", func(t *testing.T) {
+		east, west := &InMemoryECRClient{}, &InMemoryECRClient{}
+		_, err := CreateRepo("test-repo", west)
+		assert.NoError(t, err)
+		useRegionClients(t, map[string]ECRClientInterface{"us-east-1": east, "eu-west-1": west})
+
+		result, err := CreateRepoMultiRegion("test-repo", []string{"us-east-1", "eu-west-1"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"eu-west-1"}, result.Existing)
+		assert.Contains(t, result.Created, "us-east-1")
+		assert.Len(t, result.Created, 1)
+		assert.False(t, result.Consistent())
+		east.AssertRepositoryExists(t, "test-repo")
+	})
+
+	t.Run("New Everywhere", func(t *testing.T) {
+		east, west := &InMemoryECRClient{}, &InMemoryECRClient{}
+		useRegionClients(t, map[string]ECRClientInterface{"us-east-1": east, "eu-west-1": west})
+
+		result, err := CreateRepoMultiRegion("test-repo", []string{"us-east-1", "eu-west-1"})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Existing)
+		assert.Len(t, result.Created, 2)
+		assert.True(t, result.Consistent())
+	})
+
+	t.Run("Check Failure Creates Nothing", func(t *testing.T) {
+		east := &InMemoryECRClient{}
+		failing := &MockECRClient{
+			DescribeRepositoriesFunc: func(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error) {
+				return nil, errors.New("access denied")
+			},
+		}
+		useRegionClients(t, map[string]ECRClientInterface{"us-east-1": east, "eu-west-1": failing})
+
+		_, err := CreateRepoMultiRegion("test-repo", []string{"us-east-1", "eu-west-1"})
+		assert.EqualError(t, err, "region eu-west-1: access denied")
+		_, created := east.Repository("test-repo")
+		assert.False(t, created)
+	})
+
+	t.Run("Create Failure", func(t *testing.T) {
+		failing := &MockECRClient{
+			DescribeRepositoriesFunc: func(ctx context.Context, params *ecr.DescribeRepositoriesInput, optFns ...func(*ecr.Options)) (*ecr.DescribeRepositoriesOutput, error) {
+				return (&InMemoryECRClient{}).DescribeRepositories(ctx, params, optFns...)
+			},
+			CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+				return nil, errors.New("limit exceeded")
+			},
+		}
+		useRegionClients(t, map[string]ECRClientInterface{"us-east-1": &InMemoryECRClient{}, "eu-west-1": failing})
+
+		result, err := CreateRepoMultiRegion("test-repo", []string{"us-east-1", "eu-west-1"})
+		assert.EqualError(t, err, "region eu-west-1: limit exceeded")
+		assert.Contains(t, result.Created, "us-east-1")
+	})
+}
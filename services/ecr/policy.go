@@ -0,0 +1,124 @@
+package ecr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// GetRepositoryPolicy returns the JSON policy of the repository, or an empty string when it has none.
+func GetRepositoryPolicy(repoName string, ecrClient ECRClientInterface) (string, error) {
+	output, err := ecrClient.GetRepositoryPolicy(context.Background(), &ecr.GetRepositoryPolicyInput{
+		RepositoryName: aws.String(repositoryName(repoName, ecrClient)),
+	})
+	if err != nil {
+		var notFound *types.RepositoryPolicyNotFoundException
+		if errors.As(err, &notFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return aws.ToString(output.PolicyText), nil
+}
+
+// UpsertRepositoryPolicy merges policy into the repository's existing policy.
+// Statements of policy replace existing statements with the same Sid and are appended otherwise,
+// so statements added by others are kept.
+func UpsertRepositoryPolicy(repoName string, policy string, ecrClient ECRClientInterface) error {
+	existing, err := GetRepositoryPolicy(repoName, ecrClient)
+	if err != nil {
+		return fmt.Errorf("failed to get repository policy: %v", err)
+	}
+
+	merged := policy
+	if existing != "" {
+		merged, err = mergePolicies(existing, policy)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = ecrClient.SetRepositoryPolicy(context.Background(), &ecr.SetRepositoryPolicyInput{
+		RepositoryName: aws.String(repositoryName(repoName, ecrClient)),
+		PolicyText:     aws.String(merged),
+	})
+	if err != nil {
+		log.Printf("Failed to set repository policy: %v", err)
+		return err
+	}
+
+	log.Printf("Policy of repository %s updated successfully.", repoName)
+	return nil
+}
+
+// mergePolicies adds the statements of policy to existing, replacing statements with the same Sid.
+// Other top level fields, such as Version, are taken from policy.
+func mergePolicies(existing, policy string) (string, error) {
+	var base, update map[string]any
+	if err := json.Unmarshal([]byte(existing), &base); err != nil {
+		return "", fmt.Errorf("failed to parse existing repository policy: %v", err)
+	}
+	if err := json.Unmarshal([]byte(policy), &update); err != nil {
+		return "", fmt.Errorf("failed to parse repository policy: %v", err)
+	}
+
+	statements := policyStatements(base)
+	for _, statement := range policyStatements(update) {
+		if i := statementIndex(statements, statementSid(statement)); i >= 0 {
+			statements[i] = statement
+		} else {
+			statements = append(statements, statement)
+		}
+	}
+
+	for key, value := range update {
+		base[key] = value
+	}
+	base["Statement"] = statements
+
+	merged, err := json.Marshal(base)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode repository policy: %v", err)
+	}
+	return string(merged), nil
+}
+
+// policyStatements returns the Statement of policy as a list, as it may also be a single statement.
+func policyStatements(policy map[string]any) []any {
+	switch statement := policy["Statement"].(type) {
+	case []any:
+		return statement
+	case nil:
+		return nil
+	default:
+		return []any{statement}
+	}
+}
+
+// statementSid returns the Sid of statement, or an empty string when it has none.
+func statementSid(statement any) string {
+	fields, _ := statement.(map[string]any)
+	sid, _ := fields["Sid"].(string)
+	return sid
+}
+
+// statementIndex returns the index of the statement with the given Sid, or -1.
+// Statements without a Sid never match.
+func statementIndex(statements []any, sid string) int {
+	if sid == "" {
+		return -1
+	}
+	for i, statement := range statements {
+		if statementSid(statement) == sid {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,128 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const pullPolicy = `{"Version":"2012-10-17","Statement":[{"Sid":"AllowPull","Effect":"Allow","Principal":{"AWS":"arn:aws:iam::111111111111:root"},"Action":["ecr:BatchGetImage"]}]}`
+
+func TestGetRepositoryPolicy(t *testing.T) {
+	t.Run("GetRepositoryPolicy_Found", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			GetRepositoryPolicyFunc: func(ctx context.Context, params *ecr.GetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.GetRepositoryPolicyOutput, error) {
+				assert.Equal(t, "test-repo", aws.ToString(params.RepositoryName))
+				return &ecr.GetRepositoryPolicyOutput{PolicyText: aws.String(pullPolicy)}, nil
+			},
+		}
+		policy, err := GetRepositoryPolicy("test-repo", mockClient)
+		assert.NoError(t, err)
+		assert.Equal(t, pullPolicy, policy)
+	})
+
+	// A repository without a policy is not an error
+	t.Run("GetRepositoryPolicy_NotFound", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			GetRepositoryPolicyFunc: func(ctx context.Context, params *ecr.GetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.GetRepositoryPolicyOutput, error) {
+				return nil, &types.RepositoryPolicyNotFoundException{}
+			},
+		}
+		policy, err := GetRepositoryPolicy("test-repo", mockClient)
+		assert.NoError(t, err)
+		assert.Empty(t, policy)
+	})
+
+	t.Run("GetRepositoryPolicy_Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			GetRepositoryPolicyFunc: func(ctx context.Context, params *ecr.GetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.GetRepositoryPolicyOutput, error) {
+				return nil, errors.New("some error message")
+			},
+		}
+		_, err := GetRepositoryPolicy("test-repo", mockClient)
+		assert.EqualError(t, err, "some error message")
+	})
+}
+
+func TestUpsertRepositoryPolicy(t *testing.T) {
+	pushPolicy := `{"Version":"2012-10-17","Statement":{"Sid":"AllowPush","Effect":"Allow","Principal":{"AWS":"arn:aws:iam::222222222222:root"},"Action":["ecr:PutImage"]}}`
+
+	tests := []struct {
+		name     string
+		existing string
+		policy   string
+		expected string
+	}{
+		{
+			name:     "No Existing Policy",
+			policy:   pullPolicy,
+			expected: pullPolicy,
+		},
+		{
+			name:     "Statement Appended",
+			existing: pullPolicy,
+			policy:   pushPolicy,
+			expected: `{"Version":"2012-10-17","Statement":[
+				{"Sid":"AllowPull","Effect":"Allow","Principal":{"AWS":"arn:aws:iam::111111111111:root"},"Action":["ecr:BatchGetImage"]},
+				{"Sid":"AllowPush","Effect":"Allow","Principal":{"AWS":"arn:aws:iam::222222222222:root"},"Action":["ecr:PutImage"]}]}`,
+		},
+		{
+			name:     "Statement Replaced By Sid",
+			existing: pullPolicy,
+			policy:   `{"Version":"2012-10-17","Statement":[{"Sid":"AllowPull","Effect":"Allow","Principal":"*","Action":["ecr:BatchGetImage"]}]}`,
+			expected: `{"Version":"2012-10-17","Statement":[{"Sid":"AllowPull","Effect":"Allow","Principal":"*","Action":["ecr:BatchGetImage"]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var setPolicy string
+			mockClient := &MockECRClient{
+				GetRepositoryPolicyFunc: func(ctx context.Context, params *ecr.GetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.GetRepositoryPolicyOutput, error) {
+					if tt.existing == "" {
+						return nil, &types.RepositoryPolicyNotFoundException{}
+					}
+					return &ecr.GetRepositoryPolicyOutput{PolicyText: aws.String(tt.existing)}, nil
+				},
+				SetRepositoryPolicyFunc: func(ctx context.Context, params *ecr.SetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.SetRepositoryPolicyOutput, error) {
+					assert.Equal(t, "test-repo", aws.ToString(params.RepositoryName))
+					setPolicy = aws.ToString(params.PolicyText)
+					return &ecr.SetRepositoryPolicyOutput{}, nil
+				},
+			}
+
+			err := UpsertRepositoryPolicy("test-repo", tt.policy, mockClient)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.expected, setPolicy)
+		})
+	}
+
+	t.Run("Invalid Existing Policy", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			GetRepositoryPolicyFunc: func(ctx context.Context, params *ecr.GetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.GetRepositoryPolicyOutput, error) {
+				return &ecr.GetRepositoryPolicyOutput{PolicyText: aws.String("not json")}, nil
+			},
+			SetRepositoryPolicyFunc: func(ctx context.Context, params *ecr.SetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.SetRepositoryPolicyOutput, error) {
+				t.Error("the policy must not be set when the existing policy cannot be merged")
+				return nil, nil
+			},
+		}
+		err := UpsertRepositoryPolicy("test-repo", pullPolicy, mockClient)
+		assert.ErrorContains(t, err, "failed to parse existing repository policy")
+	})
+
+	t.Run("GetRepositoryPolicy_Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			GetRepositoryPolicyFunc: func(ctx context.Context, params *ecr.GetRepositoryPolicyInput, optFns ...func(*ecr.Options)) (*ecr.GetRepositoryPolicyOutput, error) {
+				return nil, errors.New("some error message")
+			},
+		}
+		err := UpsertRepositoryPolicy("test-repo", pullPolicy, mockClient)
+		assert.EqualError(t, err, "failed to get repository policy: some error message")
+	})
+}
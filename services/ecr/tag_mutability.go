@@ -0,0 +1,31 @@
+package ecr
+
+import (
+	"context"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// UpdateImageTagMutability makes the tags of an existing repository immutable, or mutable again.
+func UpdateImageTagMutability(repoName string, immutable bool, ecrClient ECRClientInterface) error {
+	mutability := types.ImageTagMutabilityMutable
+	if immutable {
+		mutability = types.ImageTagMutabilityImmutable
+	}
+	repoName = repositoryName(repoName, ecrClient)
+
+	_, err := ecrClient.PutImageTagMutability(context.Background(), &ecr.PutImageTagMutabilityInput{
+		RepositoryName:     aws.String(repoName),
+		ImageTagMutability: mutability,
+	})
+	if err != nil {
+		log.Printf("Failed to update image tag mutability: %v", err)
+		return err
+	}
+
+	log.Printf("Image tag mutability of repository %s set to %s.", repoName, mutability)
+	return nil
+}
@@ -0,0 +1,59 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateImageTagMutability(t *testing.T) {
+	tests := []struct {
+		name       string
+		immutable  bool
+		mutability types.ImageTagMutability
+	}{
+		{name: "Immutable", immutable: true, mutability: types.ImageTagMutabilityImmutable},
+		{name: "Mutable", immutable: false, mutability: types.ImageTagMutabilityMutable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockECRClient{
+				PutImageTagMutabilityFunc: func(ctx context.Context, params *ecr.PutImageTagMutabilityInput, optFns ...func(*ecr.Options)) (*ecr.PutImageTagMutabilityOutput, error) {
+					assert.Equal(t, "testRepo", aws.ToString(params.RepositoryName))
+					assert.Equal(t, tt.mutability, params.ImageTagMutability)
+					return &ecr.PutImageTagMutabilityOutput{}, nil
+				},
+			}
+			assert.NoError(t, UpdateImageTagMutability("testRepo", tt.immutable, mockClient))
+		})
+	}
+
+	t.Run("Failure", func(t *testing.T) {
+		mockClient := &MockECRClient{
+			PutImageTagMutabilityFunc: func(ctx context.Context, params *ecr.PutImageTagMutabilityInput, optFns ...func(*ecr.Options)) (*ecr.PutImageTagMutabilityOutput, error) {
+				return nil, errors.New("some error message")
+			},
+		}
+		assert.EqualError(t, UpdateImageTagMutability("testRepo", true, mockClient), "some error message")
+	})
+}
+
+func TestInMemoryECRClient_TagMutability(t *testing.T) {
+	client := &InMemoryECRClient{}
+	_, err := CreateRepo("test-repo", client)
+	assert.NoError(t, err)
+
+	assert.NoError(t, UpdateImageTagMutability("test-repo", false, client))
+	repo, _ := client.Repository("test-repo")
+	assert.Equal(t, types.ImageTagMutabilityMutable, repo.ImageTagMutability)
+
+	err = UpdateImageTagMutability("missing-repo", true, client)
+	var notFound *types.RepositoryNotFoundException
+	assert.True(t, errors.As(err, &notFound), "expected RepositoryNotFoundException, got %v", err)
+}
@@ -0,0 +1,177 @@
+package ecr
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RepoCreationCounterName is the Prometheus counter of CreateRepo calls, labeled with their result.
+const RepoCreationCounterName = "ecr_repo_creation_total"
+
+// Values of the result label of the repository creation counter.
+const (
+	CreationResultSuccess = "success"
+	CreationResultError   = "error"
+)
+
+// createRepositorySpanName is the name of the span CreateRepo emits.
+const createRepositorySpanName = "ecr.CreateRepository"
+
+// tracerName identifies the spans of this package.
+const tracerName = "github.com/lep13/AutoBuildGo/services/ecr"
+
+// errorTypeInvalidName is the ecr.error_type of names rejected before calling ECR.
+const errorTypeInvalidName = "InvalidRepositoryName"
+
+// TelemetryOption configures the span and counter emitted by CreateRepo.
+type TelemetryOption func(*telemetrySettings)
+
+type telemetrySettings struct {
+	tracerProvider trace.TracerProvider
+	creations      *prometheus.CounterVec
+}
+
+// defaultRepoCreations is the creation counter registered with the default Prometheus registerer,
+// used unless ConfigureTelemetry is given WithRegisterer.
+var defaultRepoCreations = sync.OnceValue(func() *prometheus.CounterVec {
+	return registerRepoCreationCounter(prometheus.DefaultRegisterer)
+})
+
+// registerRepoCreationCounter registers the creation counter with reg, returning the one already
+// registered when there is one.
+func registerRepoCreationCounter(reg prometheus.Registerer) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: RepoCreationCounterName,
+		Help: "ECR repositories created by CreateRepo, by result.",
+	}, []string{"result"})
+	if err := reg.Register(counter); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if errors.As(err, &registered) {
+			return registered.ExistingCollector.(*prometheus.CounterVec)
+		}
+		panic(err)
+	}
+	return counter
+}
+
+// telemetry holds the settings applied by ConfigureTelemetry.
+var telemetry = struct {
+	sync.RWMutex
+	settings telemetrySettings
+}{}
+
+// WithTracerProvider creates the CreateRepo spans with tp instead of the global otel TracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) TelemetryOption {
+	return func(s *telemetrySettings) {
+		s.tracerProvider = tp
+	}
+}
+
+// WithRegisterer registers the ecr_repo_creation_total counter with reg instead of the default
+// Prometheus registerer, e.g. a prometheus.NewRegistry() in tests.
+func WithRegisterer(reg prometheus.Registerer) TelemetryOption {
+	return func(s *telemetrySettings) {
+		s.creations = registerRepoCreationCounter(reg)
+	}
+}
+
+// ConfigureTelemetry replaces the telemetry settings of the package. Without options, spans go to
+// the global otel TracerProvider, which discards them until an SDK is installed, and creations are
+// counted by ecr_repo_creation_total in the default Prometheus registerer.
+func ConfigureTelemetry(opts ...TelemetryOption) {
+	var settings telemetrySettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	telemetry.Lock()
+	telemetry.settings = settings
+	telemetry.Unlock()
+}
+
+// currentTelemetry returns the configured settings.
+func currentTelemetry() telemetrySettings {
+	telemetry.RLock()
+	defer telemetry.RUnlock()
+	return telemetry.settings
+}
+
+// createRepositorySpan is the span of one CreateRepo call.
+type createRepositorySpan struct {
+	trace.Span
+	creations *prometheus.CounterVec
+}
+
+// startCreateRepositorySpan starts the span of a CreateRepo call for repoName.
+func startCreateRepositorySpan(ctx context.Context, repoName string, ecrClient ECRClientInterface) (context.Context, *createRepositorySpan) {
+	settings := currentTelemetry()
+	tp := settings.tracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	ctx, span := tp.Tracer(tracerName).Start(ctx, createRepositorySpanName, trace.WithAttributes(
+		attribute.String("ecr.repo_name", repoName),
+		attribute.String("ecr.region", clientRegion(ecrClient)),
+	))
+	creations := settings.creations
+	if creations == nil {
+		creations = defaultRepoCreations()
+	}
+	return ctx, &createRepositorySpan{Span: span, creations: creations}
+}
+
+// succeed ends the span of a repository that was created.
+func (s *createRepositorySpan) succeed() {
+	s.SetAttributes(attribute.Bool("ecr.success", true))
+	s.End()
+	s.count(CreationResultSuccess)
+}
+
+// fail ends the span of a call that failed with err. errType is recorded as ecr.error_type;
+// when empty, the ECR error code of err is used.
+func (s *createRepositorySpan) fail(err error, errType string) {
+	if errType == "" {
+		errType = errorType(err)
+	}
+	s.SetAttributes(
+		attribute.Bool("ecr.success", false),
+		attribute.String("ecr.error_type", errType),
+	)
+	s.RecordError(err)
+	s.SetStatus(codes.Error, err.Error())
+	s.End()
+	s.count(CreationResultError)
+}
+
+func (s *createRepositorySpan) count(result string) {
+	s.creations.WithLabelValues(result).Inc()
+}
+
+// clientRegion returns the region of an SDK client, or "" for clients that do not have one, such as mocks.
+func clientRegion(ecrClient ECRClientInterface) string {
+	switch client := ecrClient.(type) {
+	case interface{ Options() ecr.Options }:
+		return client.Options().Region
+	case *prefixedClient:
+		return clientRegion(client.ECRClientInterface)
+	}
+	return ""
+}
+
+// errorType returns the ECR error code of err, e.g. RepositoryAlreadyExistsException.
+func errorType(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return "Unknown"
+}
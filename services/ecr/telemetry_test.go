@@ -0,0 +1,171 @@
+package ecr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordedSpan keeps the name, attributes and status of a span.
+type recordedSpan struct {
+	noop.Span
+	name       string
+	attributes map[attribute.Key]attribute.Value
+	status     codes.Code
+	ended      bool
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, attr := range kv {
+		s.attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, description string) { s.status = code }
+
+func (s *recordedSpan) End(options ...trace.SpanEndOption) { s.ended = true }
+
+// recordingTracerProvider records the spans started by its tracers.
+type recordingTracerProvider struct {
+	embedded.TracerProvider
+	spans []*recordedSpan
+}
+
+func (tp *recordingTracerProvider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{tp: tp}
+}
+
+type recordingTracer struct {
+	embedded.Tracer
+	tp *recordingTracerProvider
+}
+
+func (tr recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &recordedSpan{name: name, attributes: map[attribute.Key]attribute.Value{}}
+	config := trace.NewSpanStartConfig(opts...)
+	span.SetAttributes(config.Attributes()...)
+	tr.tp.spans = append(tr.tp.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// useTelemetry records the spans of CreateRepo and counts the creations in a new registry until the test ends.
+func useTelemetry(t *testing.T) (*recordingTracerProvider, *prometheus.Registry) {
+	tp := &recordingTracerProvider{}
+	reg := prometheus.NewRegistry()
+	ConfigureTelemetry(WithTracerProvider(tp), WithRegisterer(reg))
+	t.Cleanup(func() { ConfigureTelemetry() })
+	return tp, reg
+}
+
+// creationCounts returns the ecr_repo_creation_total values gathered from reg by result.
+func creationCounts(t *testing.T, reg prometheus.Gatherer) map[string]float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	counts := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != RepoCreationCounterName {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "result" {
+					counts[label.GetValue()] = metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return counts
+}
+
+func TestCreateRepoTelemetry(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		tp, reg := useTelemetry(t)
+		mockClient := &MockECRClient{
+			CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+				assert.Same(t, tp.spans[0], trace.SpanFromContext(ctx), "CreateRepository should run in the span")
+				return &ecr.CreateRepositoryOutput{Repository: &types.Repository{RepositoryName: params.RepositoryName}}, nil
+			},
+		}
+
+		_, err := CreateRepo("test-repo", mockClient)
+
+		assert.NoError(t, err)
+		if assert.Len(t, tp.spans, 1) {
+			span := tp.spans[0]
+			assert.Equal(t, "ecr.CreateRepository", span.name)
+			assert.Equal(t, "test-repo", span.attributes["ecr.repo_name"].AsString())
+			assert.True(t, span.attributes["ecr.success"].AsBool())
+			assert.NotContains(t, span.attributes, attribute.Key("ecr.error_type"))
+			assert.Equal(t, codes.Unset, span.status)
+			assert.True(t, span.ended)
+		}
+		assert.Equal(t, map[string]float64{CreationResultSuccess: 1}, creationCounts(t, reg))
+	})
+
+	t.Run("API Error", func(t *testing.T) {
+		tp, reg := useTelemetry(t)
+		mockClient := &MockECRClient{
+			CreateRepositoryFunc: func(ctx context.Context, params *ecr.CreateRepositoryInput, optFns ...func(*ecr.Options)) (*ecr.CreateRepositoryOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: "RepositoryAlreadyExistsException", Message: "already exists"}
+			},
+		}
+
+		_, err := CreateRepo("test-repo", mockClient)
+
+		assert.Error(t, err)
+		if assert.Len(t, tp.spans, 1) {
+			span := tp.spans[0]
+			assert.False(t, span.attributes["ecr.success"].AsBool())
+			assert.Equal(t, "RepositoryAlreadyExistsException", span.attributes["ecr.error_type"].AsString())
+			assert.Equal(t, codes.Error, span.status)
+			assert.True(t, span.ended)
+		}
+		assert.Equal(t, map[string]float64{CreationResultError: 1}, creationCounts(t, reg))
+	})
+
+	t.Run("Invalid Name", func(t *testing.T) {
+		tp, reg := useTelemetry(t)
+
+		_, err := CreateRepo("Test-Repo", &MockECRClient{})
+
+		assert.Error(t, err)
+		if assert.Len(t, tp.spans, 1) {
+			assert.Equal(t, "InvalidRepositoryName", tp.spans[0].attributes["ecr.error_type"].AsString())
+			assert.Equal(t, codes.Error, tp.spans[0].status)
+		}
+		assert.Equal(t, map[string]float64{CreationResultError: 1}, creationCounts(t, reg))
+	})
+
+	t.Run("Default Registerer", func(t *testing.T) {
+		ConfigureTelemetry()
+		before := creationCounts(t, prometheus.DefaultGatherer)[CreationResultError]
+
+		_, err := CreateRepo("Test-Repo", &MockECRClient{})
+
+		assert.Error(t, err)
+		assert.Equal(t, before+1, creationCounts(t, prometheus.DefaultGatherer)[CreationResultError])
+		// Registering again returns the counter that is already registered
+		assert.Same(t, defaultRepoCreations(), registerRepoCreationCounter(prometheus.DefaultRegisterer))
+	})
+
+	t.Run("Region", func(t *testing.T) {
+		client := ecr.NewFromConfig(aws.Config{Region: "eu-west-1"})
+
+		assert.Equal(t, "eu-west-1", clientRegion(client))
+		assert.Equal(t, "eu-west-1", clientRegion(&prefixedClient{ECRClientInterface: client, prefix: "team"}))
+		assert.Equal(t, "", clientRegion(&MockECRClient{}))
+	})
+}
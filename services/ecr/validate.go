@@ -0,0 +1,34 @@
+package ecr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ECR repository name length limits.
+const (
+	minECRRepoNameLength = 2
+	maxECRRepoNameLength = 256
+)
+
+// ecrRepoNamePattern is ECR's naming rule: lowercase letters and digits, separated by single
+// '.', '_' or '-' characters, with '/' separating namespaces.
+var ecrRepoNamePattern = regexp.MustCompile(`^(?:[a-z0-9]+(?:[._-][a-z0-9]+)*/)*[a-z0-9]+(?:[._-][a-z0-9]+)*$`)
+
+// ValidateECRRepoName checks name against ECR's repository naming rules, so callers get a
+// descriptive error instead of the API's generic validation error.
+func ValidateECRRepoName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("ECR repository name must not be empty")
+	case len(name) < minECRRepoNameLength || len(name) > maxECRRepoNameLength:
+		return fmt.Errorf("ECR repository names must be %d to %d characters long, got %d", minECRRepoNameLength, maxECRRepoNameLength, len(name))
+	case name != strings.ToLower(name):
+		return fmt.Errorf("ECR repository names must be lowercase, got '%s'", name)
+	case !ecrRepoNamePattern.MatchString(name):
+		return fmt.Errorf("ECR repository names must be letters and digits separated by '.', '_', '-' or '/', got '%s'", name)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package ecr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateECRRepoName(t *testing.T) {
+	tests := []struct {
+		name        string
+		repoName    string
+		expectedErr string
+	}{
+		{name: "Simple", repoName: "test-repo"},
+		{name: "Namespaced", repoName: "team/service.api_v2"},
+		{name: "Empty", repoName: "", expectedErr: "ECR repository name must not be empty"},
+		{name: "Too Short", repoName: "a", expectedErr: "ECR repository names must be 2 to 256 characters long, got 1"},
+		{name: "Too Long", repoName: strings.Repeat("a", 257), expectedErr: "ECR repository names must be 2 to 256 characters long, got 257"},
+		{name: "Uppercase", repoName: "MyRepo", expectedErr: "ECR repository names must be lowercase, got 'MyRepo'"},
+		{name: "Leading Separator", repoName: "-repo", expectedErr: "ECR repository names must be letters and digits separated by '.', '_', '-' or '/', got '-repo'"},
+		{name: "Double Separator", repoName: "my--repo", expectedErr: "ECR repository names must be letters and digits separated by '.', '_', '-' or '/', got 'my--repo'"},
+		{name: "Trailing Slash", repoName: "team/", expectedErr: "ECR repository names must be letters and digits separated by '.', '_', '-' or '/', got 'team/'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateECRRepoName(tt.repoName)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package gitsetup
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// AdminTokenEnvVar holds the bearer token required by the admin endpoints, /debug/pprof/ and
+// /update-clone-config. Every request to them is answered with 401 Unauthorized while it is unset.
+const AdminTokenEnvVar = "AUTOBUILD_ADMIN_TOKEN"
+
+// requireAdminToken answers 401 Unauthorized unless the request carries the AUTOBUILD_ADMIN_TOKEN
+// bearer token, and calls next otherwise.
+func requireAdminToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := os.Getenv(AdminTokenEnvVar)
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="autobuildgo"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
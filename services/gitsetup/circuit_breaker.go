@@ -0,0 +1,110 @@
+package gitsetup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// Circuit breaker defaults for GitHub API calls.
+const (
+	DefaultGitHubBreakerFailures     = 5
+	DefaultGitHubBreakerResetTimeout = 30 * time.Second
+
+	GitHubBreakerFailuresEnvVar     = "AUTOBUILD_GITHUB_BREAKER_FAILURES"
+	GitHubBreakerResetTimeoutEnvVar = "AUTOBUILD_GITHUB_BREAKER_RESET_TIMEOUT"
+)
+
+// gitHubBreaker guards CreateGitRepository and FetchGitHubUsername. It opens after
+// consecutive GitHub failures and lets a trial request through after the reset timeout.
+var gitHubBreaker = newGitHubBreaker(gitHubBreakerSettingsFromEnv())
+
+// newGitHubBreaker returns a circuit breaker that opens after failures consecutive failures
+// and stays open for resetTimeout.
+func newGitHubBreaker(failures uint32, resetTimeout time.Duration) *gobreaker.CircuitBreaker {
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:    "github",
+		Timeout: resetTimeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= failures
+		},
+		IsSuccessful: func(err error) bool {
+			return err == nil || !isGitHubFailure(err)
+		},
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			slog.Warn("circuit breaker state changed", "name", name, "from", from.String(), "to", to.String())
+		},
+	})
+}
+
+// gitHubBreakerSettingsFromEnv returns the failure threshold and reset timeout from the environment,
+// falling back to the defaults for unset or invalid values.
+func gitHubBreakerSettingsFromEnv() (uint32, time.Duration) {
+	failures := uint32(DefaultGitHubBreakerFailures)
+	if value := os.Getenv(GitHubBreakerFailuresEnvVar); value != "" {
+		if n, err := strconv.ParseUint(value, 10, 32); err == nil && n > 0 {
+			failures = uint32(n)
+		} else {
+			slog.Warn("ignoring invalid circuit breaker failure threshold", "env", GitHubBreakerFailuresEnvVar, "value", value)
+		}
+	}
+
+	resetTimeout := DefaultGitHubBreakerResetTimeout
+	if value := os.Getenv(GitHubBreakerResetTimeoutEnvVar); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			resetTimeout = d
+		} else {
+			slog.Warn("ignoring invalid circuit breaker reset timeout", "env", GitHubBreakerResetTimeoutEnvVar, "value", value)
+		}
+	}
+
+	return failures, resetTimeout
+}
+
+// isGitHubFailure reports whether err means GitHub itself is unhealthy: a 5xx response,
+// a timeout or a failed connection. Client errors such as 404 or 422 do not count, and neither
+// do requests ended by the caller's context being canceled or reaching its own deadline.
+func isGitHubFailure(err error) bool {
+	var gitHubErr *GitHubError
+	if errors.As(err, &gitHubErr) {
+		return gitHubErr.StatusCode >= http.StatusInternalServerError
+	}
+	if errors.Is(err, ErrRequestTimeout) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// gitHubAvailable reports whether the GitHub circuit breaker lets requests through.
+func gitHubAvailable() bool {
+	return gitHubBreaker.State() != gobreaker.StateOpen
+}
+
+// withGitHubBreaker calls fn through the GitHub circuit breaker, returning ErrGitHubUnavailable
+// without calling fn while the breaker is open.
+func withGitHubBreaker[T any](fn func() (T, error)) (T, error) {
+	result, err := gitHubBreaker.Execute(func() (interface{}, error) {
+		return fn()
+	})
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		var zero T
+		return zero, fmt.Errorf("%w: %v", ErrGitHubUnavailable, err)
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result.(T), nil
+}
@@ -0,0 +1,179 @@
+package gitsetup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	awsECR "github.com/aws/aws-sdk-go-v2/service/ecr"
+	localECR "github.com/lep13/AutoBuildGo/services/ecr"
+)
+
+// useGitHubBreaker replaces the GitHub circuit breaker for the duration of the test.
+func useGitHubBreaker(t *testing.T, failures uint32, resetTimeout time.Duration) {
+	originalBreaker := gitHubBreaker
+	gitHubBreaker = newGitHubBreaker(failures, resetTimeout)
+	t.Cleanup(func() { gitHubBreaker = originalBreaker })
+}
+
+func TestIsGitHubFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "Server Error", err: newGitHubError(http.StatusBadGateway, "bad gateway"), expected: true},
+		{name: "Client Error", err: newGitHubError(http.StatusUnprocessableEntity, "name already exists"), expected: false},
+		{name: "Timeout", err: fmt.Errorf("%w: POST /generate", ErrRequestTimeout), expected: true},
+		{name: "Connection Error", err: &url.Error{Op: "Post", URL: "https://api.github.com", Err: errors.New("connection refused")}, expected: true},
+		{name: "Secret Error", err: errors.New("error fetching secret token"), expected: false},
+		{name: "Caller Canceled", err: &url.Error{Op: "Post", URL: "https://api.github.com", Err: context.Canceled}, expected: false},
+		{name: "Caller Deadline", err: &url.Error{Op: "Post", URL: "https://api.github.com", Err: context.DeadlineExceeded}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isGitHubFailure(tt.err); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGitHubBreakerSettingsFromEnv(t *testing.T) {
+	t.Setenv(GitHubBreakerFailuresEnvVar, "3")
+	t.Setenv(GitHubBreakerResetTimeoutEnvVar, "1m")
+	failures, resetTimeout := gitHubBreakerSettingsFromEnv()
+	if failures != 3 || resetTimeout != time.Minute {
+		t.Errorf("expected 3 failures and 1m, got %d and %v", failures, resetTimeout)
+	}
+
+	t.Setenv(GitHubBreakerFailuresEnvVar, "-1")
+	t.Setenv(GitHubBreakerResetTimeoutEnvVar, "soon")
+	failures, resetTimeout = gitHubBreakerSettingsFromEnv()
+	if failures != DefaultGitHubBreakerFailures || resetTimeout != DefaultGitHubBreakerResetTimeout {
+		t.Errorf("expected the defaults for invalid values, got %d and %v", failures, resetTimeout)
+	}
+}
+
+func TestCreateGitRepository_CircuitBreaker(t *testing.T) {
+	useGitHubBreaker(t, 2, time.Hour)
+
+	calls := 0
+	client := &GitClient{
+		HTTPClient: &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+			},
+		},
+		FetchSecretFunc: mockFetchSecretFunc,
+	}
+	config := RepoConfig{
+		Name:        "test-repo",
+		TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.CreateGitRepository(config); errors.Is(err, ErrGitHubUnavailable) {
+			t.Fatalf("call %d: expected the breaker to be closed, got %v", i, err)
+		}
+	}
+
+	_, err := client.CreateGitRepository(config)
+	if !errors.Is(err, ErrGitHubUnavailable) {
+		t.Errorf("expected %v, got %v", ErrGitHubUnavailable, err)
+	}
+	if calls != 2 {
+		t.Errorf("expected GitHub to be called 2 times, got %d", calls)
+	}
+}
+
+func TestCreateGitRepository_CircuitBreakerIgnoresCallerContext(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  func() (context.Context, context.CancelFunc)
+	}{
+		{name: "Canceled", ctx: func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx, cancel
+		}},
+		{name: "Deadline Exceeded", ctx: func() (context.Context, context.CancelFunc) {
+			return context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useGitHubBreaker(t, 1, time.Hour)
+
+			calls := 0
+			client := &GitClient{
+				HTTPClient: NewTimeoutHTTPClient(&mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						calls++
+						return nil, &url.Error{Op: req.Method, URL: req.URL.String(), Err: req.Context().Err()}
+					},
+				}, time.Hour),
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+			config := RepoConfig{
+				Name:        "test-repo",
+				TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+			}
+
+			for i := 0; i < 3; i++ {
+				ctx, cancel := tt.ctx()
+				_, err := client.CreateGitRepositoryContext(ctx, config)
+				cancel()
+				if errors.Is(err, ErrGitHubUnavailable) || errors.Is(err, ErrRequestTimeout) {
+					t.Fatalf("call %d: expected the caller's context error, got %v", i, err)
+				}
+			}
+			if calls != 3 {
+				t.Errorf("expected GitHub to be called 3 times, got %d", calls)
+			}
+		})
+	}
+}
+
+func TestCreateRepoHandler_GitHubUnavailable(t *testing.T) {
+	seedSecretCache(t)
+	useGitHubBreaker(t, 1, time.Hour)
+
+	originalCreateECRClient, originalCreateRepo, originalNewGitClient := CreateECRClientFunc, CreateRepoFunc, NewGitClientFunc
+	defer func() {
+		CreateECRClientFunc, CreateRepoFunc, NewGitClientFunc = originalCreateECRClient, originalCreateRepo, originalNewGitClient
+	}()
+	ecrRepos := 0
+	CreateECRClientFunc = mockCreateECRClient
+	CreateRepoFunc = func(repoName string, client localECR.ECRClientInterface) (*awsECR.CreateRepositoryOutput, error) {
+		ecrRepos++
+		return mockCreateRepo(repoName, client)
+	}
+	NewGitClientFunc = mockNewGitClientError
+
+	for _, expectedStatus := range []int{http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		req := httptest.NewRequest(http.MethodPost, "/create-repo", strings.NewReader(`{"repo_name": "test-repo"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		CreateRepoHandler(w, req)
+
+		if w.Code != expectedStatus {
+			t.Errorf("expected status %d, got %d", expectedStatus, w.Code)
+		}
+	}
+
+	// The open breaker rejects the second request before its ECR repository is created
+	if ecrRepos != 1 {
+		t.Errorf("expected 1 ECR repository to be created, got %d", ecrRepos)
+	}
+}
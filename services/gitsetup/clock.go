@@ -0,0 +1,25 @@
+package gitsetup
+
+import (
+	"context"
+	"time"
+)
+
+// ClockFunc waits for the given duration.
+type ClockFunc func(time.Duration)
+
+// Clock is used for every wait in the package so tests can replace it with a no-op.
+var Clock ClockFunc = time.Sleep
+
+// timeAfter is used for the waits that end early when a context is done, so tests can replace it.
+var timeAfter = time.After
+
+// sleepContext waits for d, returning ctx's error when ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeAfter(d):
+		return nil
+	}
+}
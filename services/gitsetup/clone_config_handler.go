@@ -0,0 +1,124 @@
+package gitsetup
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultCloneConfig holds the CloneConfig returned by DefaultCloneConfig, changed by UpdateCloneConfigHandler.
+var defaultCloneConfig = struct {
+	sync.RWMutex
+	config CloneConfig
+}{config: CloneConfig{
+	ModulePathPattern: DefaultModulePathPattern,
+	InitialTag:        DefaultInitialTag,
+}}
+
+// CloneConfigUpdateRequest is the body of a PATCH /update-clone-config request.
+// Fields left out keep their current value.
+type CloneConfigUpdateRequest struct {
+	ModulePathPattern *string  `json:"module_path_pattern"`
+	Branch            *string  `json:"branch"`
+	PushBranch        *string  `json:"push_branch"`
+	InitialTag        *string  `json:"initial_tag"`
+	GPGKeyID          *string  `json:"gpg_key_id"`
+	RecurseSubmodules *bool    `json:"recurse_submodules"`
+	VerifyBuild       *bool    `json:"verify_build"`
+	SkipGoMod         *bool    `json:"skip_go_mod"`
+	ReadmeTemplate    *string  `json:"readme_template"`
+	GoEnv             []string `json:"go_env"`
+}
+
+// CloneConfigResponse is the effective CloneConfig returned by UpdateCloneConfigHandler.
+type CloneConfigResponse struct {
+	ModulePathPattern string   `json:"module_path_pattern"`
+	Branch            string   `json:"branch"`
+	PushBranch        string   `json:"push_branch"`
+	InitialTag        string   `json:"initial_tag"`
+	GPGKeyID          string   `json:"gpg_key_id"`
+	RecurseSubmodules bool     `json:"recurse_submodules"`
+	VerifyBuild       bool     `json:"verify_build"`
+	SkipGoMod         bool     `json:"skip_go_mod"`
+	ReadmeTemplate    string   `json:"readme_template"`
+	GoEnv             []string `json:"go_env"`
+}
+
+// UpdateCloneConfigHandler changes the defaults returned by DefaultCloneConfig, and so used by the
+// next repository set up, without restarting the server. It answers with the new defaults and logs
+// the old and new ones to HandlerLogger. RegisterRoutes serves it behind the admin token.
+func UpdateCloneConfigHandler(w http.ResponseWriter, r *http.Request) {
+	HandlerLogger.Info("UpdateCloneConfigHandler invoked")
+	var req CloneConfigUpdateRequest
+	if !decodeJSONRequestWithMethod(w, r, http.MethodPatch, cloneConfigUpdateSchema, &req) {
+		return
+	}
+
+	defaultCloneConfig.Lock()
+	previous := defaultCloneConfig.config
+	updated := req.apply(previous)
+	if err := validateCloneConfig(updated); err != nil {
+		defaultCloneConfig.Unlock()
+		HandlerLogger.Error("Rejected clone configuration update", err)
+		http.Error(w, "Invalid clone configuration: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defaultCloneConfig.config = updated
+	defaultCloneConfig.Unlock()
+	HandlerLogger.Info("Clone configuration updated", "old", newCloneConfigResponse(previous), "new", newCloneConfigResponse(updated))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newCloneConfigResponse(updated))
+}
+
+// apply returns config with the fields set in req replaced.
+func (req CloneConfigUpdateRequest) apply(config CloneConfig) CloneConfig {
+	setIfPresent(&config.ModulePathPattern, req.ModulePathPattern)
+	setIfPresent(&config.Branch, req.Branch)
+	setIfPresent(&config.PushBranch, req.PushBranch)
+	setIfPresent(&config.InitialTag, req.InitialTag)
+	setIfPresent(&config.GPGKeyID, req.GPGKeyID)
+	setIfPresent(&config.RecurseSubmodules, req.RecurseSubmodules)
+	setIfPresent(&config.VerifyBuild, req.VerifyBuild)
+	setIfPresent(&config.SkipGoMod, req.SkipGoMod)
+	setIfPresent(&config.ReadmeTemplate, req.ReadmeTemplate)
+	if req.GoEnv != nil {
+		config.GoEnv = slices.Clone(req.GoEnv)
+	}
+	return config
+}
+
+// setIfPresent sets *field to *value when value is not nil.
+func setIfPresent[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+// validateCloneConfig renders the templates of config with sample data, so a broken template is
+// rejected by the update instead of failing the next repository set up.
+func validateCloneConfig(config CloneConfig) error {
+	if _, err := renderModulePath(config.ModulePathPattern, ModulePathData{Username: "octocat", RepoName: "service"}); err != nil {
+		return err
+	}
+	_, err := renderReadme(config.ReadmeTemplate, ReadmeData{RepoName: "service", Owner: "octocat", CreatedAt: time.Now()})
+	return err
+}
+
+func newCloneConfigResponse(config CloneConfig) CloneConfigResponse {
+	return CloneConfigResponse{
+		ModulePathPattern: config.ModulePathPattern,
+		Branch:            config.Branch,
+		PushBranch:        config.PushBranch,
+		InitialTag:        config.InitialTag,
+		GPGKeyID:          config.GPGKeyID,
+		RecurseSubmodules: config.RecurseSubmodules,
+		VerifyBuild:       config.VerifyBuild,
+		SkipGoMod:         config.SkipGoMod,
+		ReadmeTemplate:    config.ReadmeTemplate,
+		GoEnv:             config.GoEnv,
+	}
+}
@@ -0,0 +1,164 @@
+package gitsetup
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// restoreCloneConfigDefaults puts the defaults back once a test that updates them ends.
+func restoreCloneConfigDefaults(t *testing.T) {
+	original := DefaultCloneConfig()
+	t.Cleanup(func() {
+		defaultCloneConfig.Lock()
+		defaultCloneConfig.config = original
+		defaultCloneConfig.Unlock()
+	})
+}
+
+func patchCloneConfig(body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/update-clone-config", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	UpdateCloneConfigHandler(w, req)
+	return w
+}
+
+func TestUpdateCloneConfigHandler(t *testing.T) {
+	restoreCloneConfigDefaults(t)
+
+	w := patchCloneConfig(`{"module_path_pattern": "go.example.com/{{.RepoName}}", "branch": "main", "verify_build": true, "go_env": ["GOFLAGS=-mod=mod"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response CloneConfigResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expected := CloneConfigResponse{
+		ModulePathPattern: "go.example.com/{{.RepoName}}",
+		Branch:            "main",
+		InitialTag:        DefaultInitialTag,
+		VerifyBuild:       true,
+		GoEnv:             []string{"GOFLAGS=-mod=mod"},
+	}
+	if !reflect.DeepEqual(response, expected) {
+		t.Errorf("expected %+v, got %+v", expected, response)
+	}
+
+	// Fields left out keep the values of the previous update
+	patchCloneConfig(`{"initial_tag": ""}`)
+	config := DefaultCloneConfig()
+	if config.ModulePathPattern != "go.example.com/{{.RepoName}}" || !config.VerifyBuild || config.InitialTag != "" {
+		t.Errorf("expected the updated defaults, got %+v", config)
+	}
+
+	// DefaultCloneConfig returns a copy callers can change
+	config.GoEnv[0] = "GOFLAGS=-mod=vendor"
+	if DefaultCloneConfig().GoEnv[0] != "GOFLAGS=-mod=mod" {
+		t.Error("expected changes to a returned CloneConfig not to affect the defaults")
+	}
+}
+
+func TestUpdateCloneConfigHandler_Invalid(t *testing.T) {
+	restoreCloneConfigDefaults(t)
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Wrong Method", method: http.MethodPost, body: `{}`, expectedStatus: http.StatusMethodNotAllowed, expectedBody: "Method not allowed"},
+		{name: "Unknown Field", body: `{"depth": 1}`, expectedStatus: http.StatusBadRequest, expectedBody: "Invalid request: (root): Additional property depth is not allowed"},
+		{name: "Wrong Type", body: `{"verify_build": "yes"}`, expectedStatus: http.StatusBadRequest, expectedBody: "Invalid request: verify_build: Invalid type"},
+		{name: "Broken Module Path Pattern", body: `{"module_path_pattern": "github.com/{{.Owner}}"}`, expectedStatus: http.StatusBadRequest, expectedBody: "Invalid clone configuration: invalid module path pattern"},
+		{name: "Broken README Template", body: `{"readme_template": "# {{.RepoName"}`, expectedStatus: http.StatusBadRequest, expectedBody: "Invalid clone configuration: invalid README template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodPatch
+			}
+			req := httptest.NewRequest(method, "/update-clone-config", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			UpdateCloneConfigHandler(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if !strings.HasPrefix(w.Body.String(), tt.expectedBody) {
+				t.Errorf("expected body starting with %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+
+	if config := DefaultCloneConfig(); !reflect.DeepEqual(config, CloneConfig{ModulePathPattern: DefaultModulePathPattern, InitialTag: DefaultInitialTag}) {
+		t.Errorf("expected rejected updates to leave the defaults unchanged, got %+v", config)
+	}
+}
+
+func TestUpdateCloneConfigHandler_LogsChange(t *testing.T) {
+	restoreCloneConfigDefaults(t)
+	var buf bytes.Buffer
+	useHandlerLogger(t, SlogOperationLogger{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+
+	if w := patchCloneConfig(`{"branch": "main"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	logged := buf.String()
+	if !strings.Contains(logged, `msg="Clone configuration updated"`) {
+		t.Fatalf("expected the update to be logged, got %q", logged)
+	}
+	if !strings.Contains(logged, "old=\"{ModulePathPattern:"+DefaultModulePathPattern+" Branch: ") ||
+		!strings.Contains(logged, "new=\"{ModulePathPattern:"+DefaultModulePathPattern+" Branch:main ") {
+		t.Errorf("expected the old and new branch to be logged, got %q", logged)
+	}
+}
+
+func TestRegisterRoutes_UpdateCloneConfigRequiresAdminToken(t *testing.T) {
+	restoreCloneConfigDefaults(t)
+	t.Setenv(AdminTokenEnvVar, "s3cr3t")
+	mux := RegisterRoutes(nil)
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "Missing Token", expectedStatus: http.StatusUnauthorized},
+		{name: "Wrong Token", authorization: "Bearer guess", expectedStatus: http.StatusUnauthorized},
+		{name: "Admin Token", authorization: "Bearer s3cr3t", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/update-clone-config", strings.NewReader(`{"branch": "main"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+
+	if DefaultCloneConfig().Branch != "main" {
+		t.Error("expected the authorized update to apply")
+	}
+}
@@ -1,18 +1,32 @@
 package gitsetup
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
+	"text/template"
 )
 
 // GitHubService interface
 type GitHubService interface {
 	FetchSecretToken() (string, error)
-	FetchGitHubUsername(token string) (string, error)
+	FetchGitHubUsername(ctx context.Context, token string) (string, error)
+}
+
+// GitHubServiceWithAPIURL is implemented by GitHubServices that can look up the username on
+// another GitHub host, e.g. GitHub Enterprise.
+type GitHubServiceWithAPIURL interface {
+	GitHubService
+	// FetchGitHubUsernameFromAPI is FetchGitHubUsername against the GitHub API at apiURL.
+	FetchGitHubUsernameFromAPI(ctx context.Context, token, apiURL string) (string, error)
 }
 
 // DefaultGitHubService struct
@@ -22,8 +36,27 @@ func (d DefaultGitHubService) FetchSecretToken() (string, error) {
 	return FetchSecretToken() // Using the function defined in fetchsecrets.go
 }
 
-func (d DefaultGitHubService) FetchGitHubUsername(token string) (string, error) {
-	return FetchGitHubUsername(token)
+// FetchGitHubUsername returns the username the token belongs to, cached per token for
+// AUTOBUILD_USERNAME_CACHE_TTL (one hour by default).
+func (d DefaultGitHubService) FetchGitHubUsername(ctx context.Context, token string) (string, error) {
+	return cachedGitHubUsername(token, func(token string) (string, error) {
+		return FetchGitHubUsername(ctx, token)
+	})
+}
+
+// FetchGitHubUsernameFromAPI is FetchGitHubUsername against the GitHub API at apiURL.
+func (d DefaultGitHubService) FetchGitHubUsernameFromAPI(ctx context.Context, token, apiURL string) (string, error) {
+	return cachedGitHubUsername(token, func(token string) (string, error) {
+		return FetchGitHubUsername(ctx, token, apiURL+"/user")
+	})
+}
+
+// fetchUsernameFromAPI fetches the username through service, from apiURL when service supports other hosts.
+func fetchUsernameFromAPI(ctx context.Context, service GitHubService, token, apiURL string) (string, error) {
+	if withAPIURL, ok := service.(GitHubServiceWithAPIURL); ok && apiURL != gitHubAPIURL {
+		return withAPIURL.FetchGitHubUsernameFromAPI(ctx, token, apiURL)
+	}
+	return service.FetchGitHubUsername(ctx, token)
 }
 
 // Global variables to allow mocking in tests
@@ -33,6 +66,7 @@ var (
 	readFile                    = os.ReadFile
 	writeFile                   = os.WriteFile
 	chdir                       = os.Chdir
+	getwd                       = os.Getwd
 	mkdirTemp                   = os.MkdirTemp
 	removeAll                   = os.RemoveAll
 )
@@ -42,107 +76,478 @@ var httpClient = &http.Client{}
 
 // CloneAndPushRepo clones the repository, updates the go.mod file, and pushes the changes back to GitHub.
 func CloneAndPushRepo(repoName string) error {
+	return CloneAndPushRepoWithConfig(repoName, DefaultCloneConfig())
+}
+
+// CloneAndPushRepoWithConfig is CloneAndPushRepo with a custom CloneConfig.
+// The repository is cloned into a new temporary directory, which is removed afterwards even on failure.
+func CloneAndPushRepoWithConfig(repoName string, cloneConfig CloneConfig) error {
+	return CloneAndPushRepoWithConfigContext(context.Background(), repoName, cloneConfig)
+}
+
+// CloneAndPushRepoWithConfigContext is CloneAndPushRepoWithConfig with a context. It cancels the
+// GitHub API calls, and no further git or go command is started once it is done; ctx.Err() is returned
+// instead. A command that is already running is not interrupted.
+func CloneAndPushRepoWithConfigContext(ctx context.Context, repoName string, cloneConfig CloneConfig) (err error) {
 	// Fetch GitHub token
 	token, err := gitHubService.FetchSecretToken()
 	if err != nil {
-		return fmt.Errorf("error fetching GitHub token: %v", err)
+		return fmt.Errorf("%w: %v", ErrFetchingToken, err)
 	}
 
 	// Fetch GitHub username
-	username, err := gitHubService.FetchGitHubUsername(token)
+	webURL, apiURL := gitHubURLs(cloneConfig.GitHubHost)
+	username, err := fetchUsernameFromAPI(ctx, gitHubService, token, apiURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFetchingUsername, err)
+	}
+
+	// Render the module path before touching the file system
+	modulePath, err := renderModulePath(cloneConfig.ModulePathPattern, ModulePathData{
+		Username: username,
+		RepoName: repoName,
+	})
+	if err != nil {
+		return err
+	}
+	readme, err := renderReadme(cloneConfig.ReadmeTemplate, ReadmeData{
+		RepoName:  repoName,
+		Owner:     username,
+		ECRUri:    cloneConfig.ECRRepositoryURI,
+		CreatedAt: timeNow(),
+	})
 	if err != nil {
-		return fmt.Errorf("error fetching GitHub username: %v", err)
+		return err
 	}
 
-	// Clone the repository
-	repoURL := fmt.Sprintf("https://%s@github.com/%s/%s.git", token, username, repoName)
-	cmd := execCommand("git", "clone", repoURL)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("error cloning repository: %v", err)
+	// Work in a temporary directory so a failure never leaves a clone in the working directory
+	originalDir, err := getwd()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrGettingWorkingDir, err)
+	}
+	tempDir, err := mkdirTemp("", "autobuildgo-*")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCreatingTempDir, err)
+	}
+	defer func() {
+		if cleanupErr := cleanupClone(originalDir, tempDir); err == nil {
+			err = cleanupErr
+		}
+	}()
+	if err := chdir(tempDir); err != nil {
+		return fmt.Errorf("%w to temporary directory: %v", ErrChangingDirectory, err)
+	}
+
+	// Clone the repository. The token goes in a header passed through the environment instead of the
+	// URL or the command line, so git neither logs it nor stores it in .git/config. The remote keeps
+	// the HTTPS URL, since the clone is removed after the push and no one uses it afterwards.
+	repoURL := fmt.Sprintf("%s/%s/%s.git", webURL, username, repoName)
+	authEnv := gitAuthEnv(webURL+"/", token)
+	cloneArgs := []string{"clone"}
+	if cloneConfig.Branch != "" {
+		cloneArgs = append(cloneArgs, "--branch", cloneConfig.Branch)
+	}
+	if cloneConfig.RecurseSubmodules {
+		cloneArgs = append(cloneArgs, "--recurse-submodules")
+	}
+	executor := cloneConfig.Executor
+	if executor == nil {
+		executor = defaultExecutor
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := runWithEnv(executor, authEnv, "git", append(cloneArgs, repoURL)...); err != nil {
+		return fmt.Errorf("%w: %v", ErrCloningRepository, err)
 	}
 
 	// Change directory to the cloned repository
 	if err := chdir(repoName); err != nil {
-		return fmt.Errorf("error changing directory to cloned repository: %v", err)
+		return fmt.Errorf("%w to cloned repository: %v", ErrChangingDirectory, err)
+	}
+
+	// Make sure nested submodules are checked out as well
+	if cloneConfig.RecurseSubmodules {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := runWithEnv(executor, authEnv, "git", "submodule", "update", "--init", "--recursive"); err != nil {
+			return fmt.Errorf("%w: %v", ErrUpdatingSubmodules, err)
+		}
 	}
 
-	// Update go.mod file
+	// Apply the overrides the template ships for this repository
+	buildConfig, err := loadRepoBuildConfig()
+	if err != nil {
+		return err
+	}
+
+	// Update go.mod file, creating it when the template is not a Go module
 	goModFile := "go.mod"
+	commitMessage := "Update go.mod module path"
+	if buildConfig.CommitMessage != "" {
+		commitMessage = buildConfig.CommitMessage
+	}
+	var modifiedFiles []string
+	hasGoMod := true
 	input, err := readFile(goModFile)
-	if err != nil {
-		return fmt.Errorf("error reading go.mod file: %v", err)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && cloneConfig.SkipGoMod:
+		// Leave the repository without a go.mod, but still apply the other changes below
+		hasGoMod = false
+		if buildConfig.CommitMessage == "" {
+			commitMessage = "Update repository files"
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := runWithEnv(executor, cloneConfig.GoEnv, "go", "mod", "init", modulePath); err != nil {
+			return fmt.Errorf("%w: %v", ErrCreatingGoMod, err)
+		}
+		if buildConfig.CommitMessage == "" {
+			commitMessage = "Add go.mod"
+		}
+	case err != nil:
+		return fmt.Errorf("%w: %v", ErrReadingGoMod, err)
+	default:
+		modifiedFiles, err = updateModulePath(goModFile, input, modulePath)
+		if err != nil {
+			return err
+		}
+		if cloneConfig.RecurseSubmodules {
+			modifiedFiles, err = commitSubmoduleChanges(ctx, executor, modifiedFiles, commitMessage)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Set the go directive and add the files requested in .autobuild.yaml
+	if buildConfig.GoVersion != "" && hasGoMod {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := runWithEnv(executor, cloneConfig.GoEnv, "go", "mod", "edit", "-go="+buildConfig.GoVersion); err != nil {
+			return fmt.Errorf("%w to %s: %v", ErrSettingGoVersion, buildConfig.GoVersion, err)
+		}
+	}
+	modifiedFiles = append(modifiedFiles, buildConfig.ExtraFiles...)
+
+	// Replace the generic README of the template
+	if cloneConfig.ReadmeTemplate != "" {
+		if err := writeFile("README.md", readme, 0644); err != nil {
+			return fmt.Errorf("%w: %v", ErrWritingReadme, err)
+		}
+		modifiedFiles = append(modifiedFiles, "README.md")
+	}
+
+	// Make sure the module still compiles with the new module path
+	if cloneConfig.VerifyBuild {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := runWithEnv(executor, cloneConfig.GoEnv, "go", "build", "./..."); err != nil {
+			return fmt.Errorf("%w: %v", ErrVerifyingBuild, err)
+		}
+	}
+
+	addFiles := modifiedFiles
+	if hasGoMod {
+		addFiles = append([]string{goModFile}, modifiedFiles...)
+	}
+	if len(addFiles) == 0 && cloneConfig.InitialTag == "" {
+		// Nothing to commit or tag, so there is nothing to push either
+		return nil
+	}
+
+	// Commit and push the changes as one pipeline, which stops at the first failing command. The local
+	// commands only change the temporary clone, which is removed afterwards, so only the pushed tag is rolled back.
+	pipeline := NewCommandPipeline(executor)
+
+	// Sign the commit through git config, so the commit command is the same either way
+	if cloneConfig.GPGKeyID != "" {
+		pipeline.AddStep(PipelineStep{Name: "git", Args: []string{"config", "user.signingkey", cloneConfig.GPGKeyID}, Err: ErrConfiguringSigning})
+		pipeline.AddStep(PipelineStep{Name: "git", Args: []string{"config", "commit.gpgsign", "true"}, Err: ErrConfiguringSigning})
+	}
+
+	if len(addFiles) > 0 {
+		pipeline.AddStep(PipelineStep{Name: "git", Args: append([]string{"add"}, addFiles...), Err: ErrAddingFiles})
+		pipeline.AddStep(PipelineStep{Name: "git", Args: []string{"commit", "-m", commitMessage}, Err: ErrCommitting})
+	}
+
+	// Tag the commit so the module can be required by version. The tag is pushed before the branch,
+	// and deleted from GitHub again when the branch push fails, so it never points at a commit the branch lacks.
+	if tag := cloneConfig.InitialTag; tag != "" {
+		pipeline.AddStep(PipelineStep{Name: "git", Args: []string{"tag", tag}, Err: fmt.Errorf("%w %s", ErrCreatingTag, tag)})
+		pipeline.AddStep(PipelineStep{
+			Name: "git",
+			Args: []string{"push", "origin", tag},
+			Env:  authEnv,
+			Err:  fmt.Errorf("%w %s", ErrPushingTag, tag),
+			Rollback: func() error {
+				return runWithEnv(executor, authEnv, "git", "push", "origin", ":refs/tags/"+tag)
+			},
+		})
 	}
 
-	lines := strings.Split(string(input), "\n")
+	pushArgs := []string{"push"}
+	if cloneConfig.RecurseSubmodules {
+		pushArgs = append(pushArgs, "--recurse-submodules=on-demand")
+	}
+	if cloneConfig.PushBranch != "" {
+		pushArgs = append(pushArgs, "origin", "HEAD:"+cloneConfig.PushBranch)
+	}
+	pipeline.AddStep(PipelineStep{Name: "git", Args: pushArgs, Env: authEnv, Err: ErrPushing})
+
+	return pipeline.Execute(ctx)
+}
+
+// gitAuthHeader returns the HTTP header git sends to authenticate to GitHub with token.
+// GitHub accepts tokens over HTTPS as the password of a basic auth user named x-access-token.
+func gitAuthHeader(token string) string {
+	return "Authorization: basic " + base64.StdEncoding.EncodeToString([]byte("x-access-token:"+token))
+}
+
+// gitAuthEnv returns the environment that makes git send the auth header of token to gitHubURL.
+// The header is set through GIT_CONFIG_* variables, which keeps it off the command line, and only
+// for URLs under gitHubURL, so submodules hosted elsewhere never receive it.
+func gitAuthEnv(gitHubURL, token string) []string {
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http." + gitHubURL + ".extraHeader",
+		"GIT_CONFIG_VALUE_0=" + gitAuthHeader(token),
+	}
+}
+
+// updateModulePath replaces the module path in the go.mod file, including its replace directives, and
+// in the imports of the template module, returning the Go files that were modified.
+func updateModulePath(goModFile string, input []byte, modulePath string) ([]string, error) {
+	// Work on \n line endings and restore Windows line endings when writing the file back
+	content := string(input)
+	lineEnding := "\n"
+	if strings.Contains(content, "\r\n") {
+		lineEnding = "\r\n"
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+
+	var oldModulePath string
+	lines := strings.Split(content, "\n")
 	for i, line := range lines {
 		if strings.HasPrefix(line, "module") {
-			lines[i] = fmt.Sprintf("module github.com/%s/%s", username, repoName)
+			oldModulePath = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module")), `"`)
+			lines[i] = "module " + modulePath
 			break
 		}
 	}
-	output := strings.Join(lines, "\n")
+	rewriteReplaceDirectives(lines, oldModulePath, modulePath)
+	output := strings.Join(lines, lineEnding)
 	if err := writeFile(goModFile, []byte(output), 0644); err != nil {
-		return fmt.Errorf("error writing to go.mod file: %v", err)
+		return nil, fmt.Errorf("%w: %v", ErrWritingGoMod, err)
+	}
+
+	// Point imports of the template module at the new module path
+	return rewriteImportPaths(".", oldModulePath, modulePath)
+}
+
+// goModPathPattern matches the words of a go.mod line, which may be quoted.
+var goModPathPattern = regexp.MustCompile(`"[^"]*"|[^\s"]+`)
+
+// rewriteReplaceDirectives replaces oldModulePath, and the paths of its packages, with modulePath in
+// the replace directives of the go.mod lines, both on single replace lines and in replace blocks.
+func rewriteReplaceDirectives(lines []string, oldModulePath, modulePath string) {
+	if oldModulePath == "" || oldModulePath == modulePath {
+		return
+	}
+
+	inBlock := false
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case inBlock && len(fields) > 0 && fields[0] == ")":
+			inBlock = false
+			continue
+		case inBlock:
+		case len(fields) > 0 && (fields[0] == "replace(" || fields[0] == "replace" && len(fields) > 1 && fields[1] == "("):
+			inBlock = true
+			continue
+		case len(fields) > 0 && fields[0] == "replace":
+		default:
+			continue
+		}
+
+		// Stop at a comment so it is left as written
+		code, comment, _ := strings.Cut(line, "//")
+		code = goModPathPattern.ReplaceAllStringFunc(code, func(word string) string {
+			path := strings.Trim(word, `"`)
+			if path != oldModulePath && !strings.HasPrefix(path, oldModulePath+"/") {
+				return word
+			}
+			return strings.Replace(word, oldModulePath, modulePath, 1)
+		})
+		if strings.Contains(line, "//") {
+			code += "//" + comment
+		}
+		lines[i] = code
 	}
+}
 
-	// Commit and push changes
-	cmd = execCommand("git", "add", goModFile)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("error adding go.mod file to git: %v", err)
+// commitSubmoduleChanges commits the modified files that belong to a submodule inside that submodule.
+// It returns the files to add in the cloned repository, with each changed submodule in place of its files.
+// No command is started once ctx is done.
+func commitSubmoduleChanges(ctx context.Context, executor CommandExecutor, modifiedFiles []string, commitMessage string) ([]string, error) {
+	submodules, err := submodulePaths()
+	if err != nil {
+		return nil, err
 	}
 
-	cmd = execCommand("git", "commit", "-m", "Update go.mod module path")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("error committing changes: %v", err)
+	var files, changedSubmodules []string
+	submoduleFiles := map[string][]string{}
+	for _, file := range modifiedFiles {
+		submodule := submoduleOf(submodules, file)
+		if submodule == "" {
+			files = append(files, file)
+			continue
+		}
+		if _, ok := submoduleFiles[submodule]; !ok {
+			changedSubmodules = append(changedSubmodules, submodule)
+		}
+		submoduleFiles[submodule] = append(submoduleFiles[submodule], strings.TrimPrefix(file, submodule+"/"))
 	}
 
-	cmd = execCommand("git", "push")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("error pushing changes: %v", err)
+	for _, submodule := range changedSubmodules {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		addArgs := append([]string{"-C", submodule, "add"}, submoduleFiles[submodule]...)
+		if err := executor.Run("git", addArgs...); err != nil {
+			return nil, fmt.Errorf("%w in submodule %s: %v", ErrAddingFiles, submodule, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := executor.Run("git", "-C", submodule, "commit", "-m", commitMessage); err != nil {
+			return nil, fmt.Errorf("%w in submodule %s: %v", ErrCommitting, submodule, err)
+		}
+		files = append(files, submodule)
 	}
+	return files, nil
+}
 
+// submodulePaths returns the submodule paths listed in .gitmodules, or nil when there is none.
+func submodulePaths() ([]string, error) {
+	data, err := readFile(".gitmodules")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: error reading .gitmodules: %v", ErrUpdatingSubmodules, err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && strings.TrimSpace(key) == "path" {
+			paths = append(paths, strings.TrimSuffix(strings.TrimSpace(value), "/"))
+		}
+	}
+	return paths, nil
+}
+
+// submoduleOf returns the submodule containing file, or "" when it belongs to the cloned repository itself.
+func submoduleOf(submodules []string, file string) string {
+	for _, submodule := range submodules {
+		if strings.HasPrefix(file, submodule+"/") {
+			return submodule
+		}
+	}
+	return ""
+}
+
+// cleanupClone changes back to originalDir and removes the temporary directory holding the clone.
+func cleanupClone(originalDir, tempDir string) error {
 	// Go back to the previous directory
-	if err := chdir(".."); err != nil {
-		return fmt.Errorf("error changing back to the parent directory: %v", err)
+	if err := chdir(originalDir); err != nil {
+		return fmt.Errorf("%w back to the original directory: %v", ErrChangingDirectory, err)
 	}
 
-	// Remove the cloned repository
-	if err := removeAll(repoName); err != nil {
-		return fmt.Errorf("error removing the cloned repository: %v", err)
+	// Remove the temporary directory and the cloned repository in it
+	if err := removeAll(tempDir); err != nil {
+		return fmt.Errorf("%w: %v", ErrRemovingClone, err)
 	}
 
 	return nil
 }
 
-// FetchGitHubUsername fetches the GitHub username of the authenticated user.
-func FetchGitHubUsername(token string, url ...string) (string, error) {
-	requestURL := "https://api.github.com/user"
+// renderReadme renders the README template, returning nil when it is empty.
+func renderReadme(readmeTemplate string, data ReadmeData) ([]byte, error) {
+	if readmeTemplate == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New("readme").Option("missingkey=error").Parse(readmeTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error parsing: %v", ErrReadmeTemplate, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("%w: error rendering: %v", ErrReadmeTemplate, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderModulePath renders the module path pattern, falling back to DefaultModulePathPattern when empty.
+func renderModulePath(pattern string, data ModulePathData) (string, error) {
+	if pattern == "" {
+		pattern = DefaultModulePathPattern
+	}
+
+	tmpl, err := template.New("module-path").Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return "", fmt.Errorf("%w: error parsing: %v", ErrModulePathPattern, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%w: error rendering: %v", ErrModulePathPattern, err)
+	}
+
+	modulePath := strings.TrimSpace(buf.String())
+	if modulePath == "" {
+		return "", fmt.Errorf("%w: %q rendered an empty module path", ErrModulePathPattern, pattern)
+	}
+	return modulePath, nil
+}
+
+// FetchGitHubUsername fetches the GitHub username of the authenticated user through the GitHub circuit breaker.
+// The request is canceled when ctx is done.
+func FetchGitHubUsername(ctx context.Context, token string, url ...string) (string, error) {
+	requestURL := gitHubAPIURL + "/user"
 	if len(url) > 0 {
 		requestURL = url[0]
 	}
+	return withGitHubBreaker(func() (string, error) {
+		return fetchGitHubUsername(ctx, httpClient, token, requestURL)
+	})
+}
 
-	req, err := http.NewRequest("GET", requestURL, nil)
+// fetchGitHubUsername fetches the login of the user the token belongs to from requestURL.
+func fetchGitHubUsername(ctx context.Context, client HTTPClient, token, requestURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("Authorization", "token "+token)
 
-	resp, err := httpClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch GitHub username, status code: %d", resp.StatusCode)
+		return "", newGitHubError(resp.StatusCode, "failed to fetch GitHub username, status code: %d", resp.StatusCode)
 	}
 
 	var result struct {
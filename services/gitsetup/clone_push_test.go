@@ -0,0 +1,896 @@
+package gitsetup
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// cloneTestEnv replaces the file system and command globals used by CloneAndPushRepo.
+type cloneTestEnv struct {
+	executor *MockCommandExecutor
+	files    map[string][]byte
+	written  map[string][]byte
+	dirs     []string // directories passed to chdir, in order
+	removed  []string // paths passed to removeAll
+}
+
+func newCloneTestEnv(t *testing.T, goMod string) *cloneTestEnv {
+	env := &cloneTestEnv{
+		executor: &MockCommandExecutor{},
+		files:    map[string][]byte{"go.mod": []byte(goMod)},
+		written:  map[string][]byte{},
+	}
+
+	originalService, originalExecutor := gitHubService, defaultExecutor
+	originalRead, originalWrite := readFile, writeFile
+	originalChdir, originalRemoveAll := chdir, removeAll
+	originalGetwd, originalMkdirTemp := getwd, mkdirTemp
+	originalWalkDir := walkDir
+	t.Cleanup(func() {
+		gitHubService, defaultExecutor = originalService, originalExecutor
+		readFile, writeFile = originalRead, originalWrite
+		chdir, removeAll = originalChdir, originalRemoveAll
+		getwd, mkdirTemp = originalGetwd, originalMkdirTemp
+		walkDir = originalWalkDir
+	})
+
+	gitHubService = mockGitHubService{token: "mock_token", username: "octocat"}
+	defaultExecutor = env.executor
+	readFile = func(name string) ([]byte, error) {
+		data, ok := env.files[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return data, nil
+	}
+	writeFile = func(name string, data []byte, perm os.FileMode) error {
+		env.written[name] = data
+		return nil
+	}
+	chdir = func(dir string) error {
+		env.dirs = append(env.dirs, dir)
+		return nil
+	}
+	removeAll = func(path string) error {
+		env.removed = append(env.removed, path)
+		return nil
+	}
+	getwd = func() (string, error) { return "/work", nil }
+	mkdirTemp = func(dir, pattern string) (string, error) { return "/tmp/autobuildgo-1", nil }
+	walkDir = func(root string, fn fs.WalkDirFunc) error {
+		// Walk an in-memory copy of the files so the real working directory is never touched
+		mapFS := fstest.MapFS{}
+		for name, data := range env.files {
+			mapFS[name] = &fstest.MapFile{Data: data}
+		}
+		return fs.WalkDir(mapFS, root, fn)
+	}
+	return env
+}
+
+// mockAuthHeader is the header git authenticates with for the mock_token of newCloneTestEnv.
+var mockAuthHeader = gitAuthHeader("mock_token")
+
+func TestCloneAndPushRepo(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n\ngo 1.22\n")
+	env.executor.Commands = []CommandCall{
+		{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+		{Name: "git", Args: []string{"add", "go.mod"}},
+		{Name: "git", Args: []string{"commit", "-m", "Update go.mod module path"}},
+		{Name: "git", Args: []string{"tag", "v0.1.0"}},
+		{Name: "git", Args: []string{"push", "origin", "v0.1.0"}},
+		{Name: "git", Args: []string{"push"}},
+	}
+
+	if err := CloneAndPushRepo("test-repo"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expectedGoMod := "module github.com/octocat/test-repo\n\ngo 1.22\n"
+	if got := string(env.written["go.mod"]); got != expectedGoMod {
+		t.Errorf("expected go.mod %q, got %q", expectedGoMod, got)
+	}
+	env.executor.Verify(t)
+}
+
+func TestCloneAndPushRepo_TempDir(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+
+	if err := CloneAndPushRepo("test-repo"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expectedDirs := []string{"/tmp/autobuildgo-1", "test-repo", "/work"}
+	if strings.Join(env.dirs, ",") != strings.Join(expectedDirs, ",") {
+		t.Errorf("expected chdir calls %q, got %q", expectedDirs, env.dirs)
+	}
+	if len(env.removed) != 1 || env.removed[0] != "/tmp/autobuildgo-1" {
+		t.Errorf("expected the temporary directory to be removed, got %q", env.removed)
+	}
+}
+
+func TestCloneAndPushRepo_TempDirRemovedOnError(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	env.executor.Commands = []CommandCall{
+		{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}, ReturnErr: errors.New("exit status 128")},
+	}
+
+	err := CloneAndPushRepo("test-repo")
+	if !errors.Is(err, ErrCloningRepository) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(env.removed) != 1 || env.removed[0] != "/tmp/autobuildgo-1" {
+		t.Errorf("expected the temporary directory to be removed, got %q", env.removed)
+	}
+	if last := env.dirs[len(env.dirs)-1]; last != "/work" {
+		t.Errorf("expected to change back to /work, got %q", last)
+	}
+}
+
+func TestCloneAndPushRepo_TempDirError(t *testing.T) {
+	newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	mkdirTemp = func(dir, pattern string) (string, error) { return "", errors.New("no space left on device") }
+
+	err := CloneAndPushRepo("test-repo")
+	if !errors.Is(err, ErrCreatingTempDir) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCloneAndPushRepo_PushError(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	env.executor.Commands = []CommandCall{
+		{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+		{Name: "git", Args: []string{"add", "go.mod"}},
+		{Name: "git", Args: []string{"commit", "-m", "Update go.mod module path"}},
+		{Name: "git", Args: []string{"tag", "v0.1.0"}},
+		{Name: "git", Args: []string{"push", "origin", "v0.1.0"}},
+		{Name: "git", Args: []string{"push"}, ReturnErr: errors.New("exit status 1")},
+		{Name: "git", Args: []string{"push", "origin", ":refs/tags/v0.1.0"}},
+	}
+
+	err := CloneAndPushRepo("test-repo")
+	if !errors.Is(err, ErrPushing) || err.Error() != "error pushing changes: exit status 1" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	env.executor.Verify(t)
+}
+
+func TestCloneAndPushRepo_TokenNotInURL(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+
+	if err := CloneAndPushRepo("test-repo"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// Neither the token nor its base64 form may be on a command line
+	encoded := base64.StdEncoding.EncodeToString([]byte("x-access-token:mock_token"))
+	for _, call := range env.executor.Calls {
+		for _, arg := range call.Args {
+			if strings.Contains(arg, "mock_token") || strings.Contains(arg, encoded) {
+				t.Errorf("expected the token not to appear on the command line, got %q", call)
+			}
+		}
+	}
+	if mockAuthHeader != "Authorization: basic "+encoded {
+		t.Errorf("unexpected auth header %q", mockAuthHeader)
+	}
+
+	// The commands that reach GitHub get the header through the environment, scoped to github.com
+	expectedEnv := []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.https://github.com/.extraHeader",
+		"GIT_CONFIG_VALUE_0=" + mockAuthHeader,
+	}
+	for _, call := range env.executor.Calls {
+		remote := call.Args[0] == "clone" || call.Args[0] == "push"
+		if remote != reflect.DeepEqual(call.Env, expectedEnv) {
+			t.Errorf("expected the auth environment only for clone and push, got %q with %q", call, call.Env)
+		}
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_Executor(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	executor := &MockCommandExecutor{}
+
+	if err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{Executor: executor}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(executor.Calls) != 4 || len(env.executor.Calls) != 0 {
+		t.Errorf("expected the injected executor to run all commands, got %q and %q", executor.CommandLines(), env.executor.CommandLines())
+	}
+}
+
+func TestCloneAndPushRepo_WindowsLineEndings(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\r\n\r\ngo 1.22\r\n")
+
+	if err := CloneAndPushRepo("test-repo"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expectedGoMod := "module github.com/octocat/test-repo\r\n\r\ngo 1.22\r\n"
+	if got := string(env.written["go.mod"]); got != expectedGoMod {
+		t.Errorf("expected go.mod %q, got %q", expectedGoMod, got)
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_ModulePathPattern(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+
+	err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{ModulePathPattern: "go.mycompany.com/{{.RepoName}}"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if got := string(env.written["go.mod"]); got != "module go.mycompany.com/test-repo\n" {
+		t.Errorf("unexpected go.mod content: %q", got)
+	}
+}
+
+func TestCloneAndPushRepo_ReplaceDirectives(t *testing.T) {
+	goMod := `module github.com/template-owner/template-repo
+
+go 1.22
+
+require github.com/template-owner/template-repo-tools v1.0.0
+
+replace github.com/template-owner/template-repo/api => ./api // local API
+
+replace (
+	github.com/template-owner/template-repo/internal/tools => ../tools
+	example.com/logging v1.2.0 => "github.com/template-owner/template-repo/logging" v1.2.1
+	github.com/template-owner/template-repo-tools => ../template-repo-tools
+)
+`
+	env := newCloneTestEnv(t, goMod)
+
+	if err := CloneAndPushRepo("test-repo"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expected := `module github.com/octocat/test-repo
+
+go 1.22
+
+require github.com/template-owner/template-repo-tools v1.0.0
+
+replace github.com/octocat/test-repo/api => ./api // local API
+
+replace (
+	github.com/octocat/test-repo/internal/tools => ../tools
+	example.com/logging v1.2.0 => "github.com/octocat/test-repo/logging" v1.2.1
+	github.com/template-owner/template-repo-tools => ../template-repo-tools
+)
+`
+	if got := string(env.written["go.mod"]); got != expected {
+		t.Errorf("expected go.mod:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_Branch(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+
+	if err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{Branch: "master"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expected := "git clone --branch master https://github.com/octocat/test-repo.git"
+	if env.executor.CommandLines()[0] != expected {
+		t.Errorf("expected clone command %q, got %q", expected, env.executor.CommandLines()[0])
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_PushBranch(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+
+	if err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{PushBranch: "update-module-path"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expected := "git push origin HEAD:update-module-path"
+	if last := env.executor.CommandLines()[len(env.executor.CommandLines())-1]; last != expected {
+		t.Errorf("expected push command %q, got %q", expected, last)
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_InitialTagRollback(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	env.executor.Commands = []CommandCall{
+		{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+		{Name: "git", Args: []string{"add", "go.mod"}},
+		{Name: "git", Args: []string{"commit", "-m", "Update go.mod module path"}},
+		{Name: "git", Args: []string{"tag", "v1.0.0"}},
+		{Name: "git", Args: []string{"push", "origin", "v1.0.0"}},
+		{Name: "git", Args: []string{"push"}, ReturnErr: errors.New("rejected")},
+		// The branch push failed, so the tag is deleted from GitHub again
+		{Name: "git", Args: []string{"push", "origin", ":refs/tags/v1.0.0"}},
+	}
+
+	err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{InitialTag: "v1.0.0"})
+	if !errors.Is(err, ErrPushing) {
+		t.Errorf("expected %v, got: %v", ErrPushing, err)
+	}
+	env.executor.Verify(t)
+	if rollback := env.executor.Calls[len(env.executor.Calls)-1]; len(rollback.Env) == 0 {
+		t.Error("expected the tag to be deleted with the auth environment")
+	}
+}
+
+func TestCloneAndPushRepoWithConfigContext_Canceled(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := CloneAndPushRepoWithConfigContext(ctx, "test-repo", CloneConfig{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got: %v", context.Canceled, err)
+	}
+	if commands := env.executor.CommandLines(); len(commands) != 0 {
+		t.Errorf("expected no command to run once the context is canceled, got %q", commands)
+	}
+}
+
+// cancelingExecutor cancels a context once it has run the command after.
+type cancelingExecutor struct {
+	*MockCommandExecutor
+	after  string
+	cancel context.CancelFunc
+}
+
+func (e cancelingExecutor) Run(name string, args ...string) error {
+	return e.RunWithEnv(name, nil, args...)
+}
+
+func (e cancelingExecutor) RunWithEnv(name string, env []string, args ...string) error {
+	err := e.MockCommandExecutor.RunWithEnv(name, env, args...)
+	if call := (CommandCall{Name: name, Args: args}); call.String() == e.after {
+		e.cancel()
+	}
+	return err
+}
+
+func TestCloneAndPushRepoWithConfigContext_CanceledBetweenCommands(t *testing.T) {
+	tests := []struct {
+		name   string
+		goMod  string
+		after  string
+		config CloneConfig
+	}{
+		{name: "After Clone", after: "git clone https://github.com/octocat/test-repo.git"},
+		{name: "After Submodule Update", goMod: "module github.com/template-owner/template-repo\n", after: "git submodule update --init --recursive", config: CloneConfig{RecurseSubmodules: true}},
+		{name: "After Go Mod Init", after: "go mod init github.com/octocat/test-repo", config: CloneConfig{VerifyBuild: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newCloneTestEnv(t, tt.goMod)
+			if tt.goMod == "" {
+				delete(env.files, "go.mod")
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			tt.config.Executor = cancelingExecutor{MockCommandExecutor: env.executor, after: tt.after, cancel: cancel}
+
+			err := CloneAndPushRepoWithConfigContext(ctx, "test-repo", tt.config)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected %v, got: %v", context.Canceled, err)
+			}
+			commands := env.executor.CommandLines()
+			if last := commands[len(commands)-1]; last != tt.after {
+				t.Errorf("expected no command after %q, got %q", tt.after, commands)
+			}
+		})
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_InitialTag(t *testing.T) {
+	tests := []struct {
+		name         string
+		initialTag   string
+		expectedLast []string
+	}{
+		{name: "Custom Tag", initialTag: "v1.0.0", expectedLast: []string{"git tag v1.0.0", "git push origin v1.0.0", "git push"}},
+		{name: "No Tag", expectedLast: []string{"git add go.mod", "git commit -m Update go.mod module path", "git push"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+
+			if err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{InitialTag: tt.initialTag}); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			last := env.executor.CommandLines()[len(env.executor.CommandLines())-len(tt.expectedLast):]
+			if strings.Join(last, "\n") != strings.Join(tt.expectedLast, "\n") {
+				t.Errorf("expected commands to end with %q, got %q", tt.expectedLast, env.executor.CommandLines())
+			}
+		})
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_GPGKeyID(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	env.executor.Commands = []CommandCall{
+		{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+		{Name: "git", Args: []string{"config", "user.signingkey", "3AA5C34371567BD2"}},
+		{Name: "git", Args: []string{"config", "commit.gpgsign", "true"}},
+		{Name: "git", Args: []string{"add", "go.mod"}},
+		{Name: "git", Args: []string{"commit", "-m", "Update go.mod module path"}},
+		{Name: "git", Args: []string{"push"}},
+	}
+
+	if err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{GPGKeyID: "3AA5C34371567BD2"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	env.executor.Verify(t)
+}
+
+func TestCloneAndPushRepoWithConfig_VerifyBuild(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	env.executor.Commands = []CommandCall{
+		{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+		{Name: "go", Args: []string{"build", "./..."}},
+		{Name: "git", Args: []string{"add", "go.mod"}},
+		{Name: "git", Args: []string{"commit", "-m", "Update go.mod module path"}},
+		{Name: "git", Args: []string{"push"}},
+	}
+
+	if err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{VerifyBuild: true}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	env.executor.Verify(t)
+}
+
+func TestCloneAndPushRepoWithConfig_VerifyBuildError(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	env.executor.Commands = []CommandCall{
+		{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+		{Name: "go", Args: []string{"build", "./..."}, ReturnErr: errors.New("exit status 1")},
+	}
+
+	err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{VerifyBuild: true})
+	if !errors.Is(err, ErrVerifyingBuild) || err.Error() != "build verification failed: exit status 1" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	env.executor.Verify(t)
+}
+
+func TestCloneAndPushRepoWithConfig_GoEnv(t *testing.T) {
+	env := newCloneTestEnv(t, "")
+	delete(env.files, "go.mod")
+
+	goEnv := []string{"GOFLAGS=-mod=mod", "GONOSUMDB=go.mycompany.com"}
+	err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{VerifyBuild: true, GoEnv: goEnv})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	var goCommands int
+	for _, call := range env.executor.Calls {
+		if call.Name != "go" {
+			if strings.Contains(strings.Join(call.Env, " "), "GOFLAGS") {
+				t.Errorf("expected %q to run without the go variables, got %v", call, call.Env)
+			}
+			continue
+		}
+		goCommands++
+		if strings.Join(call.Env, " ") != strings.Join(goEnv, " ") {
+			t.Errorf("expected %q to run with %v, got %v", call, goEnv, call.Env)
+		}
+	}
+	if goCommands != 2 {
+		t.Errorf("expected go mod init and go build, got %v", env.executor.CommandLines())
+	}
+}
+
+func TestCloneAndPushRepo_RewritesImports(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	env.files["main.go"] = []byte(`package main
+
+import (
+	"fmt"
+
+	"github.com/template-owner/template-repo/services/api"
+)
+
+// Uses "github.com/template-owner/template-repo" in a comment, which is left alone.
+func main() { fmt.Println(api.Name) }
+`)
+	env.files["services/api/api.go"] = []byte("package api\n\nconst Name = \"api\"\n")
+	env.files["vendor/github.com/template-owner/template-repo/x.go"] = []byte("package x\n\nimport _ \"github.com/template-owner/template-repo\"\n")
+	// Fixtures under testdata are skipped, even when they do not parse
+	env.files["services/api/testdata/broken.go"] = []byte("package broken\n\nimport \"github.com/template-owner/template-repo\n")
+
+	if err := CloneAndPushRepo("test-repo"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expectedMain := `package main
+
+import (
+	"fmt"
+
+	"github.com/octocat/test-repo/services/api"
+)
+
+// Uses "github.com/template-owner/template-repo" in a comment, which is left alone.
+func main() { fmt.Println(api.Name) }
+`
+	if got := string(env.written["main.go"]); got != expectedMain {
+		t.Errorf("expected main.go %q, got %q", expectedMain, got)
+	}
+	if _, ok := env.written["services/api/api.go"]; ok {
+		t.Errorf("expected services/api/api.go to be left unchanged")
+	}
+	for name := range env.written {
+		if strings.HasPrefix(name, "vendor/") || strings.Contains(name, "/testdata/") {
+			t.Errorf("expected %s to be left unchanged", name)
+		}
+	}
+	if env.executor.CommandLines()[1] != "git add go.mod main.go" {
+		t.Errorf("expected modified files to be added, got %q", env.executor.CommandLines()[1])
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_RecurseSubmodules(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	env.files[".gitmodules"] = []byte("[submodule \"libs/shared\"]\n\tpath = libs/shared\n\turl = https://github.com/template-owner/shared.git\n")
+	env.files["main.go"] = []byte("package main\n\nimport _ \"github.com/template-owner/template-repo/libs/shared/log\"\n")
+	env.files["libs/shared/log/log.go"] = []byte("package log\n\nimport _ \"github.com/template-owner/template-repo/internal\"\n")
+
+	cloneConfig := DefaultCloneConfig()
+	cloneConfig.InitialTag = ""
+	cloneConfig.RecurseSubmodules = true
+	if err := CloneAndPushRepoWithConfig("test-repo", cloneConfig); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expectedCalls := []string{
+		"git clone --recurse-submodules https://github.com/octocat/test-repo.git",
+		"git submodule update --init --recursive",
+		"git -C libs/shared add log/log.go",
+		"git -C libs/shared commit -m Update go.mod module path",
+		"git add go.mod main.go libs/shared",
+		"git commit -m Update go.mod module path",
+		"git push --recurse-submodules=on-demand",
+	}
+	if got := env.executor.CommandLines(); strings.Join(got, "\n") != strings.Join(expectedCalls, "\n") {
+		t.Errorf("expected commands %q, got %q", expectedCalls, got)
+	}
+	expectedLog := "package log\n\nimport _ \"github.com/octocat/test-repo/internal\"\n"
+	if got := string(env.written["libs/shared/log/log.go"]); got != expectedLog {
+		t.Errorf("expected submodule file %q, got %q", expectedLog, got)
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_SubmoduleUpdateError(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	env.executor.Commands = []CommandCall{
+		{Name: "git", Args: []string{"clone", "--recurse-submodules", "https://github.com/octocat/test-repo.git"}},
+		{Name: "git", Args: []string{"submodule", "update", "--init", "--recursive"}, ReturnErr: errors.New("exit status 1")},
+	}
+
+	cloneConfig := DefaultCloneConfig()
+	cloneConfig.RecurseSubmodules = true
+	err := CloneAndPushRepoWithConfig("test-repo", cloneConfig)
+	if !errors.Is(err, ErrUpdatingSubmodules) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCloneAndPushRepo_MissingGoMod(t *testing.T) {
+	env := newCloneTestEnv(t, "")
+	delete(env.files, "go.mod")
+
+	if err := CloneAndPushRepo("test-repo"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expectedCalls := []string{
+		"git clone https://github.com/octocat/test-repo.git",
+		"go mod init github.com/octocat/test-repo",
+		"git add go.mod",
+		"git commit -m Add go.mod",
+		"git tag v0.1.0",
+		"git push origin v0.1.0",
+		"git push",
+	}
+	if strings.Join(env.executor.CommandLines(), "\n") != strings.Join(expectedCalls, "\n") {
+		t.Errorf("expected commands %q, got %q", expectedCalls, env.executor.CommandLines())
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_SkipGoMod(t *testing.T) {
+	env := newCloneTestEnv(t, "")
+	delete(env.files, "go.mod")
+
+	if err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{SkipGoMod: true}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(env.executor.CommandLines()) != 1 || len(env.written) != 0 {
+		t.Errorf("expected only the clone to run and nothing to be written, got commands %q", env.executor.CommandLines())
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_SkipGoModAppliesOtherChanges(t *testing.T) {
+	env := newCloneTestEnv(t, "")
+	delete(env.files, "go.mod")
+	env.files[RepoBuildConfigFile] = []byte("go_version: \"1.22\"\nextra_files:\n  - Dockerfile\n")
+	env.executor.Commands = []CommandCall{
+		{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+		{Name: "git", Args: []string{"add", "Dockerfile", "README.md"}},
+		{Name: "git", Args: []string{"commit", "-m", "Update repository files"}},
+		{Name: "git", Args: []string{"tag", "v0.1.0"}},
+		{Name: "git", Args: []string{"push", "origin", "v0.1.0"}},
+		{Name: "git", Args: []string{"push"}},
+	}
+
+	err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{
+		SkipGoMod:      true,
+		InitialTag:     "v0.1.0",
+		ReadmeTemplate: "# {{.RepoName}}\n",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	env.executor.Verify(t)
+
+	if got := string(env.written["README.md"]); got != "# test-repo\n" {
+		t.Errorf("expected README.md %q, got %q", "# test-repo\n", got)
+	}
+	if _, found := env.written["go.mod"]; found {
+		t.Error("expected no go.mod to be written")
+	}
+}
+
+func TestCloneAndPushRepo_UsernameError(t *testing.T) {
+	env := newCloneTestEnv(t, "")
+	gitHubService = mockGitHubService{token: "mock_token", usernameErr: errors.New("bad credentials")}
+
+	err := CloneAndPushRepo("test-repo")
+	if !errors.Is(err, ErrFetchingUsername) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(env.executor.CommandLines()) != 0 {
+		t.Errorf("expected no commands to run, got %q", env.executor.CommandLines())
+	}
+}
+
+func TestRenderModulePath(t *testing.T) {
+	data := ModulePathData{Username: "octocat", RepoName: "test-repo"}
+
+	tests := []struct {
+		name        string
+		pattern     string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "Default Pattern", pattern: "", expected: "github.com/octocat/test-repo"},
+		{name: "Vanity Import Path", pattern: "go.mycompany.com/{{.RepoName}}", expected: "go.mycompany.com/test-repo"},
+		{name: "Invalid Template", pattern: "github.com/{{.Username", expectedErr: true},
+		{name: "Unknown Field", pattern: "github.com/{{.Owner}}", expectedErr: true},
+		{name: "Empty Result", pattern: "{{if false}}x{{end}}", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modulePath, err := renderModulePath(tt.pattern, data)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedErr, err)
+			}
+			if modulePath != tt.expected {
+				t.Errorf("expected module path %q, got %q", tt.expected, modulePath)
+			}
+		})
+	}
+}
+
+func TestCloneAndPushRepo_ErrorsWrapSentinels(t *testing.T) {
+	tests := []struct {
+		name        string
+		commands    []CommandCall
+		expectedErr error
+	}{
+		{
+			name: "Commit",
+			commands: []CommandCall{
+				{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+				{Name: "git", Args: []string{"add", "go.mod"}},
+				{Name: "git", Args: []string{"commit", "-m", "Update go.mod module path"}, ReturnErr: errors.New("exit status 1")},
+			},
+			expectedErr: ErrCommitting,
+		},
+		{
+			name: "Tag",
+			commands: []CommandCall{
+				{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+				{Name: "git", Args: []string{"add", "go.mod"}},
+				{Name: "git", Args: []string{"commit", "-m", "Update go.mod module path"}},
+				{Name: "git", Args: []string{"tag", "v0.1.0"}, ReturnErr: errors.New("exit status 128")},
+			},
+			expectedErr: ErrCreatingTag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+			env.executor.Commands = tt.commands
+
+			if err := CloneAndPushRepo("test-repo"); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected %v, got: %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_RepoBuildConfig(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	env.files[RepoBuildConfigFile] = []byte(`go_version: "1.22"
+extra_files:
+  - README.md
+commit_message: Set up the service module
+lint: strict
+`)
+	env.executor.Commands = []CommandCall{
+		{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+		{Name: "go", Args: []string{"mod", "edit", "-go=1.22"}},
+		{Name: "git", Args: []string{"add", "go.mod", "README.md"}},
+		{Name: "git", Args: []string{"commit", "-m", "Set up the service module"}},
+		{Name: "git", Args: []string{"push"}},
+	}
+
+	// The unknown lint key is only a warning
+	if err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	env.executor.Verify(t)
+}
+
+func TestCloneAndPushRepoWithConfig_RepoBuildConfigErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		buildConfig string
+		commands    []CommandCall
+		expectedErr error
+	}{
+		{
+			name:        "Invalid YAML",
+			buildConfig: "extra_files: [README.md\n",
+			commands: []CommandCall{
+				{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+			},
+			expectedErr: ErrReadingBuildConfig,
+		},
+		{
+			name:        "Wrong Type",
+			buildConfig: "extra_files: README.md\n",
+			commands: []CommandCall{
+				{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+			},
+			expectedErr: ErrReadingBuildConfig,
+		},
+		{
+			name:        "Invalid Go Version",
+			buildConfig: "go_version: latest\n",
+			commands: []CommandCall{
+				{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+				{Name: "go", Args: []string{"mod", "edit", "-go=latest"}, ReturnErr: errors.New("exit status 1")},
+			},
+			expectedErr: ErrSettingGoVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+			env.files[RepoBuildConfigFile] = []byte(tt.buildConfig)
+			env.executor.Commands = tt.commands
+
+			err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected %v, got: %v", tt.expectedErr, err)
+			}
+			env.executor.Verify(t)
+		})
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_ReadmeTemplate(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	originalNow := timeNow
+	timeNow = func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { timeNow = originalNow }()
+
+	env.executor.Commands = []CommandCall{
+		{Name: "git", Args: []string{"clone", "https://github.com/octocat/test-repo.git"}},
+		{Name: "git", Args: []string{"add", "go.mod", "README.md"}},
+		{Name: "git", Args: []string{"commit", "-m", "Update go.mod module path"}},
+		{Name: "git", Args: []string{"push"}},
+	}
+
+	err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{
+		ReadmeTemplate:   "# {{.RepoName}}\n\nOwned by {{.Owner}}, image: {{.ECRUri}}, created {{.CreatedAt.Format \"2006-01-02\"}}\n",
+		ECRRepositoryURI: "123456789012.dkr.ecr.us-east-1.amazonaws.com/test-repo",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	env.executor.Verify(t)
+
+	expected := "# test-repo\n\nOwned by octocat, image: 123456789012.dkr.ecr.us-east-1.amazonaws.com/test-repo, created 2024-06-01\n"
+	if got := string(env.written["README.md"]); got != expected {
+		t.Errorf("expected README.md %q, got %q", expected, got)
+	}
+}
+
+func TestCloneAndPushRepoWithConfig_ReadmeTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		readmeTemplate string
+		expectedErr    string
+	}{
+		{name: "Parse Error", readmeTemplate: "# {{.RepoName", expectedErr: "invalid README template: error parsing"},
+		{name: "Unknown Field", readmeTemplate: "# {{.Name}}", expectedErr: "invalid README template: error rendering"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+
+			err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{ReadmeTemplate: tt.readmeTemplate})
+			if !errors.Is(err, ErrReadmeTemplate) || !strings.HasPrefix(err.Error(), tt.expectedErr) {
+				t.Errorf("expected %q, got: %v", tt.expectedErr, err)
+			}
+			// The template is rendered before anything is cloned
+			if len(env.executor.Calls) != 0 {
+				t.Errorf("expected no commands, got %v", env.executor.CommandLines())
+			}
+		})
+	}
+}
+
+// apiURLGitHubService is a mockGitHubService that records the API URL the username is fetched from.
+type apiURLGitHubService struct {
+	mockGitHubService
+	apiURL *string
+}
+
+func (m apiURLGitHubService) FetchGitHubUsernameFromAPI(ctx context.Context, token, apiURL string) (string, error) {
+	*m.apiURL = apiURL
+	return m.username, m.usernameErr
+}
+
+func TestCloneAndPushRepoWithConfig_GitHubHost(t *testing.T) {
+	env := newCloneTestEnv(t, "module github.com/template-owner/template-repo\n")
+	var apiURL string
+	gitHubService = apiURLGitHubService{mockGitHubService: mockGitHubService{token: "mock_token", username: "octocat"}, apiURL: &apiURL}
+
+	if err := CloneAndPushRepoWithConfig("test-repo", CloneConfig{GitHubHost: "github.example.com"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if apiURL != "https://github.example.com/api/v3" {
+		t.Errorf("expected the username to be fetched from the GitHub Enterprise API, got %q", apiURL)
+	}
+	clone := env.executor.Calls[0]
+	if clone.String() != "git clone https://github.example.com/octocat/test-repo.git" {
+		t.Errorf("expected the clone from github.example.com, got %q", clone)
+	}
+	if !slices.Contains(clone.Env, "GIT_CONFIG_KEY_0=http.https://github.example.com/.extraHeader") {
+		t.Errorf("expected the auth header to be scoped to github.example.com, got %q", clone.Env)
+	}
+}
@@ -0,0 +1,45 @@
+package gitsetup
+
+import "os"
+
+// CommandExecutor runs the external commands, such as git, used by CloneAndPushRepo.
+type CommandExecutor interface {
+	Run(name string, args ...string) error
+}
+
+// CommandExecutorWithEnv is implemented by executors that can add environment variables to a command,
+// e.g. GOFLAGS or GONOSUMDB for go commands that fetch private modules.
+type CommandExecutorWithEnv interface {
+	CommandExecutor
+	// RunWithEnv runs the command with env, in "KEY=value" form, added to the current environment.
+	RunWithEnv(name string, env []string, args ...string) error
+}
+
+// defaultExecutor is used when CloneConfig.Executor is nil and can be overridden in tests.
+var defaultExecutor CommandExecutor = ExecCommandExecutor{}
+
+// ExecCommandExecutor runs commands with os/exec, streaming their output to stdout and stderr.
+type ExecCommandExecutor struct{}
+
+func (e ExecCommandExecutor) Run(name string, args ...string) error {
+	return e.RunWithEnv(name, nil, args...)
+}
+
+func (ExecCommandExecutor) RunWithEnv(name string, env []string, args ...string) error {
+	cmd := execCommand(name, args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// runWithEnv runs the command with env through RunWithEnv when executor supports it.
+// Executors without environment support run the command with Run, and the variables are not set.
+func runWithEnv(executor CommandExecutor, env []string, name string, args ...string) error {
+	if withEnv, ok := executor.(CommandExecutorWithEnv); ok && len(env) > 0 {
+		return withEnv.RunWithEnv(name, env, args...)
+	}
+	return executor.Run(name, args...)
+}
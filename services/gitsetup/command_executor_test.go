@@ -0,0 +1,54 @@
+package gitsetup
+
+import "testing"
+
+func TestExecCommandExecutor(t *testing.T) {
+	executor := ExecCommandExecutor{}
+
+	if err := executor.Run("go", "version"); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if err := executor.Run("autobuildgo-command-that-does-not-exist"); err == nil {
+		t.Errorf("expected an error for a missing command")
+	}
+}
+
+func TestExecCommandExecutor_RunWithEnv(t *testing.T) {
+	executor := ExecCommandExecutor{}
+
+	// go refuses to run with an invalid GOFLAGS, which shows the variable reached the command
+	if err := executor.RunWithEnv("go", []string{"GOFLAGS=notaflag"}, "version"); err == nil {
+		t.Errorf("expected the invalid GOFLAGS to be passed to go")
+	}
+	if err := executor.RunWithEnv("go", nil, "version"); err != nil {
+		t.Errorf("expected no error without extra variables, got: %v", err)
+	}
+}
+
+// runOnlyExecutor is a CommandExecutor without environment support.
+type runOnlyExecutor struct {
+	calls []string
+}
+
+func (e *runOnlyExecutor) Run(name string, args ...string) error {
+	e.calls = append(e.calls, name)
+	return nil
+}
+
+func TestRunWithEnv(t *testing.T) {
+	withEnv := &MockCommandExecutor{}
+	if err := runWithEnv(withEnv, []string{"GONOSUMDB=example.com"}, "go", "build", "./..."); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(withEnv.Calls) != 1 || len(withEnv.Calls[0].Env) != 1 || withEnv.Calls[0].Env[0] != "GONOSUMDB=example.com" {
+		t.Errorf("expected the command to run with GONOSUMDB, got %+v", withEnv.Calls)
+	}
+
+	runOnly := &runOnlyExecutor{}
+	if err := runWithEnv(runOnly, []string{"GONOSUMDB=example.com"}, "go", "build", "./..."); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(runOnly.calls) != 1 {
+		t.Errorf("expected executors without environment support to use Run, got %v", runOnly.calls)
+	}
+}
@@ -0,0 +1,80 @@
+package gitsetup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// CommandPipeline runs a sequence of commands through a CommandExecutor, stopping at the first
+// failure and undoing the commands that already ran with their rollback functions.
+type CommandPipeline struct {
+	executor CommandExecutor
+	steps    []PipelineStep
+}
+
+// PipelineStep is one command of a CommandPipeline.
+type PipelineStep struct {
+	Name string
+	Args []string
+	// Env is added to the environment of the command when the executor implements CommandExecutorWithEnv.
+	Env []string
+	// Err wraps the error of a failed command, e.g. ErrCommitting. ErrRunningCommand is used when nil.
+	Err error
+	// Rollback undoes the command. It is called when a later step fails and may be nil.
+	Rollback func() error
+}
+
+// NewCommandPipeline returns an empty pipeline running its commands with executor, or
+// with the default executor when executor is nil.
+func NewCommandPipeline(executor CommandExecutor) *CommandPipeline {
+	if executor == nil {
+		executor = defaultExecutor
+	}
+	return &CommandPipeline{executor: executor}
+}
+
+// Add appends the command name with args to the pipeline. rollback may be nil.
+func (p *CommandPipeline) Add(name string, args []string, rollback func() error) *CommandPipeline {
+	return p.AddStep(PipelineStep{Name: name, Args: args, Rollback: rollback})
+}
+
+// AddStep appends step to the pipeline.
+func (p *CommandPipeline) AddStep(step PipelineStep) *CommandPipeline {
+	p.steps = append(p.steps, step)
+	return p
+}
+
+// Execute runs the commands in order. When a command fails, or ctx is done before the next command
+// starts, the rollback functions of the commands that succeeded run in reverse order and the error
+// is returned together with any rollback errors.
+func (p *CommandPipeline) Execute(ctx context.Context) error {
+	for i, step := range p.steps {
+		if err := ctx.Err(); err != nil {
+			return p.rollback(i, err)
+		}
+		if err := runWithEnv(p.executor, step.Env, step.Name, step.Args...); err != nil {
+			stepErr := step.Err
+			if stepErr == nil {
+				stepErr = fmt.Errorf("%w %s", ErrRunningCommand, step.Name)
+			}
+			return p.rollback(i, fmt.Errorf("%w: %v", stepErr, err))
+		}
+	}
+	return nil
+}
+
+// rollback calls the rollback functions of the first completed steps in reverse order,
+// returning err joined with the errors of the rollback functions.
+func (p *CommandPipeline) rollback(completed int, err error) error {
+	errs := []error{err}
+	for i := completed - 1; i >= 0; i-- {
+		if p.steps[i].Rollback == nil {
+			continue
+		}
+		if rollbackErr := p.steps[i].Rollback(); rollbackErr != nil {
+			errs = append(errs, fmt.Errorf("%w %s: %v", ErrRollingBack, p.steps[i].Name, rollbackErr))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,113 @@
+package gitsetup
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCommandPipeline_Execute(t *testing.T) {
+	executor := &MockCommandExecutor{Commands: []CommandCall{
+		{Name: "git", Args: []string{"add", "go.mod"}},
+		{Name: "go", Args: []string{"build", "./..."}},
+	}}
+	err := NewCommandPipeline(executor).
+		Add("git", []string{"add", "go.mod"}, nil).
+		AddStep(PipelineStep{Name: "go", Args: []string{"build", "./..."}, Env: []string{"GOFLAGS=-mod=mod"}}).
+		Execute(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	executor.Verify(t)
+	if env := executor.Calls[1].Env; len(env) != 1 || env[0] != "GOFLAGS=-mod=mod" {
+		t.Errorf("expected the step environment to be passed, got %v", env)
+	}
+}
+
+func TestCommandPipeline_RollsBackInReverseOrder(t *testing.T) {
+	executor := &MockCommandExecutor{Commands: []CommandCall{
+		{Name: "git", Args: []string{"tag", "v0.1.0"}},
+		{Name: "git", Args: []string{"commit"}},
+		{Name: "git", Args: []string{"push"}, ReturnErr: errors.New("exit status 1")},
+	}}
+	var rolledBack []string
+	rollback := func(name string) func() error {
+		return func() error {
+			rolledBack = append(rolledBack, name)
+			return nil
+		}
+	}
+
+	err := NewCommandPipeline(executor).
+		Add("git", []string{"tag", "v0.1.0"}, rollback("tag")).
+		Add("git", []string{"commit"}, rollback("commit")).
+		AddStep(PipelineStep{Name: "git", Args: []string{"push"}, Err: ErrPushing, Rollback: rollback("push")}).
+		Add("git", []string{"push", "--tags"}, rollback("push tags")).
+		Execute(context.Background())
+
+	if !errors.Is(err, ErrPushing) || err.Error() != "error pushing changes: exit status 1" {
+		t.Errorf("expected ErrPushing, got: %v", err)
+	}
+	executor.Verify(t)
+	if !reflect.DeepEqual(rolledBack, []string{"commit", "tag"}) {
+		t.Errorf("expected the completed steps to be rolled back in reverse order, got %v", rolledBack)
+	}
+}
+
+func TestCommandPipeline_RollbackError(t *testing.T) {
+	executor := &MockCommandExecutor{Commands: []CommandCall{
+		{Name: "git", Args: []string{"tag", "v0.1.0"}},
+		{Name: "git", Args: []string{"push", "origin", "v0.1.0"}, ReturnErr: errors.New("exit status 1")},
+	}}
+	err := NewCommandPipeline(executor).
+		Add("git", []string{"tag", "v0.1.0"}, func() error { return errors.New("tag not found") }).
+		Add("git", []string{"push", "origin", "v0.1.0"}, nil).
+		Execute(context.Background())
+
+	if !errors.Is(err, ErrRunningCommand) || !errors.Is(err, ErrRollingBack) {
+		t.Errorf("expected the command and rollback errors, got: %v", err)
+	}
+	executor.Verify(t)
+}
+
+func TestCommandPipeline_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	executor := &MockCommandExecutor{}
+	rolledBack := false
+	err := NewCommandPipeline(executor).
+		Add("git", []string{"commit"}, func() error {
+			rolledBack = true
+			return nil
+		}).
+		AddStep(PipelineStep{Name: "git", Args: []string{"push"}, Rollback: func() error {
+			t.Error("the step that did not run should not be rolled back")
+			return nil
+		}}).
+		Add("git", []string{"push", "--tags"}, nil).
+		Execute(&cancelingContext{Context: ctx, cancel: cancel, executor: executor})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+	if !reflect.DeepEqual(executor.CommandLines(), []string{"git commit"}) {
+		t.Errorf("expected only the first command to run, got %v", executor.CommandLines())
+	}
+	if !rolledBack {
+		t.Error("expected the first command to be rolled back")
+	}
+}
+
+// cancelingContext is canceled once executor has run a command.
+type cancelingContext struct {
+	context.Context
+	cancel   context.CancelFunc
+	executor *MockCommandExecutor
+}
+
+func (c *cancelingContext) Err() error {
+	if len(c.executor.Calls) > 0 {
+		c.cancel()
+	}
+	return c.Context.Err()
+}
@@ -0,0 +1,64 @@
+package gitsetup
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// CORSOriginsEnvVar lists the origins, comma separated, allowed to call the API from a browser.
+// "*" allows every origin. CORS headers are not sent when it is unset.
+const CORSOriginsEnvVar = "AUTOBUILD_CORS_ORIGINS"
+
+// Methods and request headers browsers may use for cross-origin requests.
+const (
+	corsAllowedMethods = "GET, POST, PATCH, OPTIONS"
+	corsAllowedHeaders = "Authorization, Content-Type, " + IncludeURLsHeader
+)
+
+// CORSMiddleware allows cross-origin requests from allowedOrigins, where "*" allows any origin.
+// Preflight OPTIONS requests are answered with 204 No Content without calling next.
+func CORSMiddleware(allowedOrigins []string) func(http.Handler) http.Handler {
+	allowAll := slices.Contains(allowedOrigins, "*")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin != "" && (allowAll || slices.Contains(allowedOrigins, origin)) {
+				if allowAll {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+					w.Header().Add("Vary", "Origin")
+				}
+				w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+				w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// corsOriginsFromEnv returns the origins listed in AUTOBUILD_CORS_ORIGINS, or nil when it is unset.
+func corsOriginsFromEnv() []string {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv(CORSOriginsEnvVar), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// serverHandler wraps mux with the middleware configured through the environment.
+// The settings are looked up per request, so a reload through SIGHUP applies to the next request.
+func serverHandler(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		settings().wrap(mux).ServeHTTP(w, r)
+	})
+}
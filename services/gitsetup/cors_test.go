@@ -0,0 +1,122 @@
+package gitsetup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		allowedOrigins []string
+		method         string
+		origin         string
+		preflight      bool
+		expectedStatus int
+		expectedOrigin string
+	}{
+		{
+			name:           "Allowed Origin",
+			allowedOrigins: []string{"https://ui.example.com"},
+			method:         http.MethodPost,
+			origin:         "https://ui.example.com",
+			expectedStatus: http.StatusOK,
+			expectedOrigin: "https://ui.example.com",
+		},
+		{
+			name:           "Other Origin",
+			allowedOrigins: []string{"https://ui.example.com"},
+			method:         http.MethodPost,
+			origin:         "https://evil.example.com",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Wildcard",
+			allowedOrigins: []string{"*"},
+			method:         http.MethodGet,
+			origin:         "https://any.example.com",
+			expectedStatus: http.StatusOK,
+			expectedOrigin: "*",
+		},
+		{
+			name:           "Preflight",
+			allowedOrigins: []string{"https://ui.example.com"},
+			method:         http.MethodOptions,
+			origin:         "https://ui.example.com",
+			preflight:      true,
+			expectedStatus: http.StatusNoContent,
+			expectedOrigin: "https://ui.example.com",
+		},
+		{
+			name:           "Same Origin Request",
+			allowedOrigins: []string{"*"},
+			method:         http.MethodPost,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/create-repo", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+
+			CORSMiddleware(tt.allowedOrigins)(next).ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.expectedOrigin {
+				t.Errorf("expected Access-Control-Allow-Origin %q, got %q", tt.expectedOrigin, got)
+			}
+			if tt.expectedOrigin != "" {
+				// PATCH is used by /ecr-repo and /update-clone-config, which needs the Authorization header
+				if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, PATCH, OPTIONS" {
+					t.Errorf("expected Access-Control-Allow-Methods %q, got %q", "GET, POST, PATCH, OPTIONS", got)
+				}
+				if got := w.Header().Get("Access-Control-Allow-Headers"); got != "Authorization, Content-Type, X-Include-URLs" {
+					t.Errorf("expected Access-Control-Allow-Headers %q, got %q", "Authorization, Content-Type, X-Include-URLs", got)
+				}
+			}
+		})
+	}
+}
+
+func TestServerHandler_CORSFromEnv(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          string
+		expectedOrigin string
+	}{
+		{name: "Unset", value: ""},
+		{name: "Listed", value: "https://admin.example.com, https://ui.example.com", expectedOrigin: "https://ui.example.com"},
+		{name: "Not Listed", value: "https://admin.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(CORSOriginsEnvVar, tt.value)
+			reloadSettings(t)
+			handler := serverHandler(RegisterRoutes(nil))
+
+			req := httptest.NewRequest(http.MethodGet, "/create-repo", nil)
+			req.Header.Set("Origin", "https://ui.example.com")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.expectedOrigin {
+				t.Errorf("expected Access-Control-Allow-Origin %q, got %q", tt.expectedOrigin, got)
+			}
+		})
+	}
+}
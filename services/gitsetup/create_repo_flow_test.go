@@ -0,0 +1,168 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	awsECR "github.com/aws/aws-sdk-go-v2/service/ecr"
+	localECR "github.com/lep13/AutoBuildGo/services/ecr"
+)
+
+// callRecorder records the calls made to the mocked wrapper functions and their arguments.
+type callRecorder struct {
+	mu    sync.Mutex
+	calls map[string][][]any
+}
+
+func (r *callRecorder) record(name string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.calls == nil {
+		r.calls = map[string][][]any{}
+	}
+	r.calls[name] = append(r.calls[name], args)
+}
+
+// expectOnce fails t unless name was called exactly once with args.
+func (r *callRecorder) expectOnce(t *testing.T, name string, args ...any) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls[name]
+	if len(calls) != 1 {
+		t.Errorf("expected %s to be called once, got %d calls", name, len(calls))
+		return
+	}
+	if !reflect.DeepEqual(calls[0], args) {
+		t.Errorf("expected %s to be called with %v, got %v", name, args, calls[0])
+	}
+}
+
+// flowRequestKey marks the request context of TestCreateRepoHandler_FullSuccessFlow.
+type flowRequestKey struct{}
+
+// expectRequestContext fails t unless ctx derives from the request context of the flow test.
+func expectRequestContext(t *testing.T, name string, ctx context.Context) {
+	t.Helper()
+	if ctx.Value(flowRequestKey{}) == nil {
+		t.Errorf("expected %s to get the request context", name)
+	}
+}
+
+func TestCreateRepoHandler_FullSuccessFlow(t *testing.T) {
+	seedSecretCache(t)
+
+	originalCreateECRClient, originalCreateRepo := CreateECRClientFunc, CreateRepoFunc
+	originalNewGitClient, originalCloneAndPush := NewGitClientFunc, CloneAndPushRepoFunc
+	originalCreateSecrets, originalPrefetch := CreateRepoSecretsFunc, PrefetchSecretsFunc
+	originalMetrics, originalClock := MetricsFunc, Clock
+	t.Cleanup(func() {
+		CreateECRClientFunc, CreateRepoFunc = originalCreateECRClient, originalCreateRepo
+		NewGitClientFunc, CloneAndPushRepoFunc = originalNewGitClient, originalCloneAndPush
+		CreateRepoSecretsFunc, PrefetchSecretsFunc = originalCreateSecrets, originalPrefetch
+		MetricsFunc, Clock = originalMetrics, originalClock
+	})
+
+	recorder := &callRecorder{}
+	ecrClient := &awsECR.Client{}
+	var gitHubRequest, descriptionRequest map[string]any
+
+	CreateECRClientFunc = func() (localECR.ECRClientInterface, error) {
+		recorder.record("CreateECRClientFunc")
+		return ecrClient, nil
+	}
+	CreateRepoFunc = func(repoName string, client localECR.ECRClientInterface) (*awsECR.CreateRepositoryOutput, error) {
+		recorder.record("CreateRepoFunc", repoName, client == localECR.ECRClientInterface(ecrClient))
+		return mockCreateRepo(repoName, client)
+	}
+	NewGitClientFunc = func() *GitClient {
+		recorder.record("NewGitClientFunc")
+		return &GitClient{
+			HTTPClient: &mockHTTPClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					expectRequestContext(t, "GitHub "+req.Method, req.Context())
+					if strings.Contains(req.URL.Path, "/git/refs/") {
+						recorder.record("GitHub ref", req.URL.String())
+						return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("{}"))}, nil
+					}
+					recorder.record("GitHub "+req.Method, req.URL.String())
+					switch req.Method {
+					case http.MethodGet:
+						return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+					case http.MethodPatch:
+						json.NewDecoder(req.Body).Decode(&descriptionRequest)
+						return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("{}"))}, nil
+					}
+					json.NewDecoder(req.Body).Decode(&gitHubRequest)
+					return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+				},
+			},
+			FetchSecretFunc: mockFetchSecretFunc,
+		}
+	}
+	CloneAndPushRepoFunc = func(ctx context.Context, repoName string, cloneConfig CloneConfig) error {
+		expectRequestContext(t, "CloneAndPushRepoFunc", ctx)
+		recorder.record("CloneAndPushRepoFunc", repoName, cloneConfig)
+		return nil
+	}
+	CreateRepoSecretsFunc = func(ctx context.Context, client HTTPClient, repoName string, secrets map[string]string) error {
+		expectRequestContext(t, "CreateRepoSecretsFunc", ctx)
+		recorder.record("CreateRepoSecretsFunc", repoName, secrets)
+		return nil
+	}
+	PrefetchSecretsFunc = func(ctx context.Context, keys ...string) error {
+		recorder.record("PrefetchSecretsFunc", keys)
+		return nil
+	}
+	MetricsFunc = func(ctx context.Context, metricName string, value float64, unit types.StandardUnit) error {
+		recorder.record("MetricsFunc", metricName, value, unit)
+		return nil
+	}
+	Clock = func(d time.Duration) { recorder.record("Clock", d) }
+
+	body := `{"repo_name": "test-repo", "description": "test description", "secrets": {"API_KEY": "s3cr3t"}}`
+	req := httptest.NewRequest(http.MethodPost, "/create-repo", bytes.NewBufferString(body))
+	req = req.WithContext(context.WithValue(req.Context(), flowRequestKey{}, true))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	CreateRepoHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := strings.TrimSpace(w.Body.String()); got != createdResponse {
+		t.Errorf("expected body %q, got %q", createdResponse, got)
+	}
+
+	recorder.expectOnce(t, "PrefetchSecretsFunc", []string{"GITHUB_TOKEN", "TEMPLATE_URL"})
+	recorder.expectOnce(t, "CreateECRClientFunc")
+	recorder.expectOnce(t, "CreateRepoFunc", "test-repo", true)
+	recorder.expectOnce(t, "NewGitClientFunc")
+	recorder.expectOnce(t, "GitHub POST", "https://api.github.com/repos/template-owner/template-repo/generate")
+	recorder.expectOnce(t, "GitHub PATCH", "https://api.github.com/repos/octocat/test-repo")
+	recorder.expectOnce(t, "GitHub ref", "https://api.github.com/repos/octocat/test-repo/git/refs/heads/main")
+	expectedCloneConfig := DefaultCloneConfig()
+	expectedCloneConfig.ECRRepositoryURI = "123456789012.dkr.ecr.us-east-1.amazonaws.com/test-repo"
+	recorder.expectOnce(t, "CloneAndPushRepoFunc", "test-repo", expectedCloneConfig)
+	recorder.expectOnce(t, "CreateRepoSecretsFunc", "test-repo", map[string]string{"API_KEY": "s3cr3t"})
+	recorder.expectOnce(t, "MetricsFunc", RepoCreationSuccessMetric, float64(1), types.StandardUnitCount)
+
+	expectedRequest := map[string]any{"name": "test-repo", "description": "test description", "private": true}
+	if !reflect.DeepEqual(gitHubRequest, expectedRequest) {
+		t.Errorf("expected GitHub request %v, got %v", expectedRequest, gitHubRequest)
+	}
+	if descriptionRequest["description"] != "test description" {
+		t.Errorf("expected the description to be set again, got %v", descriptionRequest)
+	}
+}
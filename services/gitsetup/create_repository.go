@@ -0,0 +1,12 @@
+package gitsetup
+
+// CreateGitRepository creates a GitHub repository from config using client for the API calls.
+// It is a shortcut for callers that already have an HTTPClient and do not need a GitClient.
+func CreateGitRepository(client HTTPClient, config RepoConfig) (RepoCreateResult, error) {
+	gitClient := &GitClient{
+		HTTPClient:      client,
+		FetchSecretFunc: FetchSecretToken,
+		BaseURL:         gitHubAPIURL,
+	}
+	return gitClient.CreateGitRepository(config)
+}
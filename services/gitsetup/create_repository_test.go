@@ -0,0 +1,31 @@
+package gitsetup
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestCreateGitRepositoryFunc(t *testing.T) {
+	seedSecretCache(t)
+
+	var authorization string
+	client := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			authorization = req.Header.Get("Authorization")
+			return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+		},
+	}
+
+	_, err := CreateGitRepository(client, RepoConfig{
+		Name:        "test-repo",
+		TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if authorization != "token test_github_token" {
+		t.Errorf("expected the token from Secrets Manager to be used, got %q", authorization)
+	}
+}
@@ -0,0 +1,56 @@
+package gitsetup
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// ECRRepoUpdateRequest is the body of a PATCH /ecr-repo request.
+type ECRRepoUpdateRequest struct {
+	RepoName  string `json:"repo_name"`
+	Immutable bool   `json:"immutable"`
+}
+
+// ECRRepoUpdateResponse is returned by UpdateECRRepoHandler on success.
+type ECRRepoUpdateResponse struct {
+	Message   string `json:"message"`
+	RepoName  string `json:"repo_name"`
+	Immutable bool   `json:"immutable"`
+}
+
+// UpdateECRRepoHandler changes the image tag mutability of an existing ECR repository.
+func UpdateECRRepoHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("UpdateECRRepoHandler invoked")
+	var req ECRRepoUpdateRequest
+	if !decodeJSONRequestWithMethod(w, r, http.MethodPatch, ecrRepoUpdateSchema, &req) {
+		return
+	}
+
+	ecrClient, err := CreateECRClientFunc()
+	if err != nil {
+		http.Error(w, "Failed to create ECR client: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := UpdateImageTagMutabilityFunc(req.RepoName, req.Immutable, ecrClient); err != nil {
+		var notFound *types.RepositoryNotFoundException
+		if errors.As(err, &notFound) {
+			http.Error(w, "ECR repository not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to update image tag mutability: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ECRRepoUpdateResponse{
+		Message:   "ECR repository updated successfully",
+		RepoName:  req.RepoName,
+		Immutable: req.Immutable,
+	})
+}
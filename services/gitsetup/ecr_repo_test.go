@@ -0,0 +1,140 @@
+package gitsetup
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	localECR "github.com/lep13/AutoBuildGo/services/ecr"
+)
+
+func TestUpdateECRRepoHandler(t *testing.T) {
+	originalUpdate := UpdateImageTagMutabilityFunc
+	defer func() { UpdateImageTagMutabilityFunc = originalUpdate }()
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		createECRFunc  func() (localECR.ECRClientInterface, error)
+		updateErr      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Make Immutable",
+			method:         http.MethodPatch,
+			body:           `{"repo_name": "test-repo", "immutable": true}`,
+			createECRFunc:  mockCreateECRClient,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"ECR repository updated successfully","repo_name":"test-repo","immutable":true}`,
+		},
+		{
+			name:           "Invalid Method",
+			method:         http.MethodPost,
+			body:           `{"repo_name": "test-repo", "immutable": true}`,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed",
+		},
+		{
+			name:           "Missing Immutable",
+			method:         http.MethodPatch,
+			body:           `{"repo_name": "test-repo"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request: (root): immutable is required",
+		},
+		{
+			name:           "Repository Not Found",
+			method:         http.MethodPatch,
+			body:           `{"repo_name": "test-repo", "immutable": false}`,
+			createECRFunc:  mockCreateECRClient,
+			updateErr:      &types.RepositoryNotFoundException{},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "ECR repository not found",
+		},
+		{
+			name:           "Update Error",
+			method:         http.MethodPatch,
+			body:           `{"repo_name": "test-repo", "immutable": false}`,
+			createECRFunc:  mockCreateECRClient,
+			updateErr:      errors.New("access denied"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to update image tag mutability: access denied",
+		},
+		{
+			name:           "Error Creating ECR Client",
+			method:         http.MethodPatch,
+			body:           `{"repo_name": "test-repo", "immutable": true}`,
+			createECRFunc:  mockCreateECRClientError,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to create ECR client: mock error creating ECR client",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			CreateECRClientFunc = tt.createECRFunc
+			var updated *bool
+			UpdateImageTagMutabilityFunc = func(repoName string, immutable bool, client localECR.ECRClientInterface) error {
+				updated = &immutable
+				return tt.updateErr
+			}
+
+			req := httptest.NewRequest(tt.method, "/ecr-repo", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			UpdateECRRepoHandler(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, body)
+			}
+			if tt.expectedStatus == http.StatusOK && (updated == nil || !*updated) {
+				t.Errorf("expected the repository to be made immutable")
+			}
+		})
+	}
+}
+
+func TestUpdateECRRepoHandler_SendsMutability(t *testing.T) {
+	originalCreateECRClient := CreateECRClientFunc
+	defer func() { CreateECRClientFunc = originalCreateECRClient }()
+
+	tests := []struct {
+		name               string
+		body               string
+		expectedMutability types.ImageTagMutability
+	}{
+		{name: "Immutable", body: `{"repo_name": "test-repo", "immutable": true}`, expectedMutability: types.ImageTagMutabilityImmutable},
+		{name: "Mutable", body: `{"repo_name": "test-repo", "immutable": false}`, expectedMutability: types.ImageTagMutabilityMutable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &localECR.InMemoryECRClient{}
+			if _, err := localECR.CreateRepo("test-repo", client); err != nil {
+				t.Fatalf("failed to create the repository: %v", err)
+			}
+			CreateECRClientFunc = func() (localECR.ECRClientInterface, error) { return client, nil }
+
+			req := httptest.NewRequest(http.MethodPatch, "/ecr-repo", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			UpdateECRRepoHandler(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			repo, _ := client.Repository("test-repo")
+			if repo.ImageTagMutability != tt.expectedMutability {
+				t.Errorf("expected ECR to get mutability %s, got %s", tt.expectedMutability, repo.ImageTagMutability)
+			}
+		})
+	}
+}
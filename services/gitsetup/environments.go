@@ -0,0 +1,108 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxEnvironmentReviewers is the number of required reviewers GitHub allows per environment.
+const maxEnvironmentReviewers = 6
+
+// EnvironmentConfig is a GitHub Actions deployment environment created by CreateGitRepository.
+type EnvironmentConfig struct {
+	Name string
+	// Reviewers must approve deployments to the environment. Entries are user logins,
+	// or "org/team-slug" for teams. GitHub allows up to six.
+	Reviewers []string
+}
+
+// environmentReviewer is a required reviewer as the environments API expects it.
+type environmentReviewer struct {
+	Type string `json:"type"`
+	ID   int64  `json:"id"`
+}
+
+// CreateEnvironment creates or updates the deployment environment env of owner/repo with
+// PUT /repos/{owner}/{repo}/environments/{environment_name}, requiring approval by its reviewers.
+func (client *GitClient) CreateEnvironment(ctx context.Context, owner, repo string, env EnvironmentConfig) error {
+	token, err := client.refreshToken(ctx)
+	if err != nil {
+		return err
+	}
+	return client.createEnvironment(ctx, token, owner, repo, env)
+}
+
+// createEnvironment creates env on owner/repoName, resolving the reviewers to their IDs first.
+func (client *GitClient) createEnvironment(ctx context.Context, token, owner, repoName string, env EnvironmentConfig) error {
+	payload := map[string]any{}
+	if len(env.Reviewers) > 0 {
+		reviewers := make([]environmentReviewer, len(env.Reviewers))
+		for i, reviewer := range env.Reviewers {
+			resolved, err := client.resolveReviewer(ctx, token, reviewer)
+			if err != nil {
+				return err
+			}
+			reviewers[i] = resolved
+		}
+		payload["reviewers"] = reviewers
+	}
+
+	resp, err := client.sendOwnerRepoRequest(ctx, token, http.MethodPut, owner, repoName, "/environments/"+url.PathEscape(env.Name), payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return newGitHubError(resp.StatusCode, "failed to create environment %s, status code: %d, response: %s", env.Name, resp.StatusCode, string(body))
+}
+
+// resolveReviewer looks up the ID of a user login with GET /users/{username}, or of an "org/team-slug"
+// with GET /orgs/{org}/teams/{team_slug}.
+func (client *GitClient) resolveReviewer(ctx context.Context, token, reviewer string) (environmentReviewer, error) {
+	reviewerType, path := "User", "/users/"+url.PathEscape(reviewer)
+	if org, team, ok := strings.Cut(reviewer, "/"); ok {
+		reviewerType, path = "Team", "/orgs/"+url.PathEscape(org)+"/teams/"+url.PathEscape(team)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.apiURL(path), nil)
+	if err != nil {
+		return environmentReviewer{}, err
+	}
+	req.Header.Set("Authorization", "token "+token)
+
+	resp, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return environmentReviewer{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return environmentReviewer{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return environmentReviewer{}, newGitHubError(resp.StatusCode, "failed to look up reviewer %s, status code: %d, response: %s", reviewer, resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&result); err != nil {
+		return environmentReviewer{}, fmt.Errorf("failed to decode reviewer %s: %v", reviewer, err)
+	}
+	return environmentReviewer{Type: reviewerType, ID: result.ID}, nil
+}
@@ -0,0 +1,144 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// environmentsGitHub answers the requests made while creating environments for octocat/test-repo
+// and records the body of every environment PUT by path.
+func environmentsGitHub(t *testing.T, putStatus int, puts map[string]map[string]any) *mockHTTPClient {
+	return &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			respond := func(status int, body string) (*http.Response, error) {
+				return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+			}
+			switch {
+			case req.Method == http.MethodPost:
+				return respond(http.StatusCreated, "")
+			case req.URL.Path == "/user":
+				return respond(http.StatusOK, `{"login": "octocat"}`)
+			case req.URL.Path == "/users/hubot":
+				return respond(http.StatusOK, `{"id": 42}`)
+			case req.URL.Path == "/users/ghost":
+				return respond(http.StatusNotFound, "Not Found")
+			case req.URL.Path == "/orgs/my-org/teams/release-managers":
+				return respond(http.StatusOK, `{"id": 7}`)
+			case req.Method == http.MethodPut:
+				var body map[string]any
+				json.NewDecoder(req.Body).Decode(&body)
+				puts[req.URL.EscapedPath()] = body
+				return respond(putStatus, "Validation Failed")
+			}
+			t.Errorf("unexpected request: %s %s", req.Method, req.URL)
+			return nil, errors.New("unexpected request")
+		},
+	}
+}
+
+func TestCreateEnvironment(t *testing.T) {
+	puts := map[string]map[string]any{}
+	gitHub := environmentsGitHub(t, http.StatusOK, puts)
+	client := &GitClient{
+		HTTPClient: &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Host != "github.example.com" || req.Header.Get("Authorization") != "token mock_token" {
+					t.Errorf("expected requests to the configured API with the client's token, got %s with %q", req.URL, req.Header.Get("Authorization"))
+				}
+				return gitHub.Do(req)
+			},
+		},
+		FetchSecretFunc: mockFetchSecretFunc,
+		BaseURL:         "https://github.example.com",
+	}
+
+	env := EnvironmentConfig{Name: "production", Reviewers: []string{"hubot", "my-org/release-managers"}}
+	if err := client.CreateEnvironment(context.Background(), "octocat", "test-repo", env); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expected := map[string]any{"reviewers": []any{
+		map[string]any{"type": "User", "id": float64(42)},
+		map[string]any{"type": "Team", "id": float64(7)},
+	}}
+	if body := puts["/repos/octocat/test-repo/environments/production"]; !reflect.DeepEqual(body, expected) {
+		t.Errorf("expected body %v, got %v", expected, body)
+	}
+}
+
+func TestCreateEnvironment_Errors(t *testing.T) {
+	tests := []struct {
+		name               string
+		reviewers          []string
+		putStatus          int
+		expectedErrMessage string
+	}{
+		{
+			name:               "Unknown Reviewer",
+			reviewers:          []string{"ghost"},
+			expectedErrMessage: "failed to look up reviewer ghost, status code: 404, response: Not Found",
+		},
+		{
+			name:               "Environment Rejected",
+			putStatus:          http.StatusUnprocessableEntity,
+			expectedErrMessage: "failed to create environment staging, status code: 422, response: Validation Failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &GitClient{
+				HTTPClient:      environmentsGitHub(t, tt.putStatus, map[string]map[string]any{}),
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+
+			err := client.CreateEnvironment(context.Background(), "octocat", "test-repo", EnvironmentConfig{Name: "staging", Reviewers: tt.reviewers})
+			if err == nil || err.Error() != tt.expectedErrMessage {
+				t.Errorf("expected error %q, got: %v", tt.expectedErrMessage, err)
+			}
+		})
+	}
+}
+
+func TestCreateGitRepository_Environments(t *testing.T) {
+	puts := map[string]map[string]any{}
+	client := &GitClient{
+		HTTPClient:      environmentsGitHub(t, http.StatusOK, puts),
+		FetchSecretFunc: mockFetchSecretFunc,
+	}
+
+	_, err := client.CreateGitRepository(RepoConfig{
+		Name:        "test-repo",
+		TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+		Environments: []EnvironmentConfig{
+			{Name: "production", Reviewers: []string{"hubot"}},
+			{Name: "dev/preview"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	for _, path := range []string{"/repos/octocat/test-repo/environments/production", "/repos/octocat/test-repo/environments/dev%2Fpreview"} {
+		if _, found := puts[path]; !found {
+			t.Errorf("expected a PUT to %s, got %v", path, puts)
+		}
+	}
+
+	client.HTTPClient = environmentsGitHub(t, http.StatusUnprocessableEntity, puts)
+	_, err = client.CreateGitRepository(RepoConfig{
+		Name:         "test-repo",
+		TemplateURL:  "https://api.github.com/repos/template-owner/template-repo/generate",
+		Environments: []EnvironmentConfig{{Name: "production"}},
+	})
+	expectedErr := "failed to create environments: failed to create environment production, status code: 422, response: Validation Failed"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("expected error %q, got: %v", expectedErr, err)
+	}
+}
@@ -0,0 +1,57 @@
+package gitsetup
+
+import "errors"
+
+// Sentinel errors of the gitsetup package. Errors returned by the package wrap them,
+// so callers can check for them with errors.Is instead of comparing messages.
+var (
+	// ErrEmptyRepoName is returned when a repository name is empty.
+	ErrEmptyRepoName = errors.New("repo name must not be empty")
+	// ErrGitHubUnavailable is returned without calling GitHub while the circuit breaker is open.
+	ErrGitHubUnavailable = errors.New("GitHub API temporarily unavailable")
+	// ErrRequestTimeout is returned by TimeoutHTTPClient when a request exceeds its deadline.
+	ErrRequestTimeout = errors.New("request timed out")
+	// ErrResponseBodyTooLarge is returned when a response body is larger than MaxResponseBodyBytes.
+	ErrResponseBodyTooLarge = errors.New("response body exceeded maximum size")
+	// ErrUnsupportedRepoSetting is returned for a RepoConfig.Extra key that cannot be applied to a repository.
+	ErrUnsupportedRepoSetting = errors.New("unsupported repository setting")
+	// ErrReadingUserConfig is returned when the UserConfigFile cannot be read or parsed.
+	ErrReadingUserConfig = errors.New("error reading ~/" + UserConfigFile)
+
+	// Secrets Manager errors.
+	ErrLoadingAWSConfig    = errors.New("error loading AWS config")
+	ErrFetchingSecret      = errors.New("error fetching secret value")
+	ErrUnmarshallingSecret = errors.New("error unmarshalling secret value")
+	ErrSecretKeyNotFound   = errors.New("secret key not found")
+	// ErrSecretTampered is returned when a secret value does not match its expected checksum.
+	ErrSecretTampered = errors.New("secret value does not match the expected checksum")
+
+	// CloneAndPushRepo errors.
+	ErrFetchingToken      = errors.New("error fetching GitHub token")
+	ErrFetchingUsername   = errors.New("error fetching GitHub username")
+	ErrGettingWorkingDir  = errors.New("error getting the working directory")
+	ErrCreatingTempDir    = errors.New("error creating temporary directory")
+	ErrChangingDirectory  = errors.New("error changing directory")
+	ErrCloningRepository  = errors.New("error cloning repository")
+	ErrUpdatingSubmodules = errors.New("error updating submodules")
+	ErrReadingBuildConfig = errors.New("error reading " + RepoBuildConfigFile)
+	ErrModulePathPattern  = errors.New("invalid module path pattern")
+	ErrReadmeTemplate     = errors.New("invalid README template")
+	ErrWritingReadme      = errors.New("error writing README.md")
+	ErrReadingGoMod       = errors.New("error reading go.mod file")
+	ErrWritingGoMod       = errors.New("error writing to go.mod file")
+	ErrCreatingGoMod      = errors.New("error creating go.mod file")
+	ErrSettingGoVersion   = errors.New("error setting the go version")
+	ErrVerifyingBuild     = errors.New("build verification failed")
+	ErrConfiguringSigning = errors.New("error configuring commit signing")
+	ErrAddingFiles        = errors.New("error adding go.mod file to git")
+	ErrCommitting         = errors.New("error committing changes")
+	ErrPushing            = errors.New("error pushing changes")
+	ErrCreatingTag        = errors.New("error creating tag")
+	ErrPushingTag         = errors.New("error pushing tag")
+	ErrRemovingClone      = errors.New("error removing the cloned repository")
+
+	// CommandPipeline errors.
+	ErrRunningCommand = errors.New("error running")
+	ErrRollingBack    = errors.New("error rolling back")
+)
@@ -2,11 +2,17 @@ package gitsetup
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
+	"os"
 	"os/exec"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -31,12 +37,46 @@ type SecretsManagerClient interface {
 
 var secretsManagerClient SecretsManagerClient
 
+// initSecretsManager makes init create the Secrets Manager client and warm the secret cache.
+// The package's tests turn it off from a variable initializer, which runs before init, so they never reach AWS.
+var initSecretsManager = true
+
 func init() {
+	if !initSecretsManager {
+		return
+	}
 	cfg, err := configLoader.LoadDefaultConfig(context.Background(), config.WithRegion("us-east-1"))
 	if err != nil {
 		log.Fatalf("unable to load SDK config, %v", err)
 	}
 	secretsManagerClient = secretsmanager.NewFromConfig(cfg)
+
+	// Warm the secret cache so the first request does not wait on Secrets Manager.
+	go prewarmSecretCache(secretsManagerClient)
+}
+
+// secretPrefetchTimeout bounds the background prefetch started by init.
+const secretPrefetchTimeout = 30 * time.Second
+
+// prewarmSecretCache prefetches the GitHub token and template URL with client, logging failures.
+// Keys overridden by environment variables are not fetched.
+func prewarmSecretCache(client SecretsManagerClient) {
+	var keys []string
+	if os.Getenv(GitHubTokenEnvVar) == "" {
+		keys = append(keys, "GITHUB_TOKEN")
+	}
+	if os.Getenv(TemplateURLEnvVar) == "" {
+		keys = append(keys, "TEMPLATE_URL")
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretPrefetchTimeout)
+	defer cancel()
+	if err := prefetchSecrets(ctx, client, keys...); err != nil {
+		slog.Warn("failed to prefetch secrets", "error", err)
+	}
 }
 
 type CommandRunner interface {
@@ -56,58 +96,334 @@ func (r *DefaultCommandRunner) Output(cmd *exec.Cmd) ([]byte, error) {
 
 // var runner CommandRunner = &DefaultCommandRunner{}
 
+// Secret IDs in Secrets Manager, overridable through the matching environment variables.
+const (
+	DefaultGitHubTokenSecretID = "github_token"
+	DefaultTemplateURLSecretID = "github_token"
+
+	GitHubTokenSecretIDEnvVar = "AUTOBUILD_GITHUB_TOKEN_SECRET_ID"
+	TemplateURLSecretIDEnvVar = "AUTOBUILD_TEMPLATE_URL_SECRET_ID"
+
+	// GitHubTokenEnvVar and TemplateURLEnvVar bypass Secrets Manager, e.g. for local development.
+	GitHubTokenEnvVar = "AUTOBUILD_GITHUB_TOKEN"
+	TemplateURLEnvVar = "AUTOBUILD_TEMPLATE_URL"
+
+	// GitHubTokenChecksumEnvVar holds the expected hex encoded SHA-256 of the GitHub token.
+	GitHubTokenChecksumEnvVar = "AUTOBUILD_GITHUB_TOKEN_SHA256"
+)
+
+// SecretConfig identifies a JSON key within a Secrets Manager secret.
+type SecretConfig struct {
+	SecretID string
+	Key      string
+	// ExpectedChecksum is the hex encoded SHA-256 of the value. When set, a value
+	// with a different checksum is rejected with ErrSecretTampered.
+	ExpectedChecksum string
+	// VersionStage fetches the version with this staging label, e.g. AWSPENDING during a rotation.
+	// Empty fetches AWSCURRENT.
+	VersionStage string
+}
+
+// cacheKey returns the key under which the secret value is cached.
+// Version stages are cached separately so a rotation never mixes versions.
+func (c SecretConfig) cacheKey() string {
+	if c.VersionStage != "" {
+		return c.SecretID + "@" + c.VersionStage + "/" + c.Key
+	}
+	return c.SecretID + "/" + c.Key
+}
+
+// secretVersionKey returns the key under which the fetch time of the secret version holding c is kept.
+func (c SecretConfig) secretVersionKey() string {
+	return SecretConfig{SecretID: c.SecretID, VersionStage: c.VersionStage}.cacheKey()
+}
+
+// SecretCacheTTLEnvVar sets how long fetched secrets are cached, e.g. "1h". Unset or 0 keeps them until
+// the cache is cleared. Secrets used within the last fifth of their TTL are refreshed in the background.
+const SecretCacheTTLEnvVar = "AUTOBUILD_SECRET_CACHE_TTL"
+
 var secretCache = struct {
 	sync.Mutex
 	data map[string]string
-}{data: make(map[string]string)}
+	ttl  time.Duration
+	// fetchedAt holds when each secret version was fetched. Values without a fetch time never expire.
+	fetchedAt map[string]time.Time
+	// clients holds the Secrets Manager client each secret version was fetched with, so a background
+	// refresh uses the same credentials, e.g. the SSO ones.
+	clients map[string]SecretsManagerClient
+	// refreshing holds the secret versions with a background refresh in flight.
+	refreshing map[string]bool
+	// generation is incremented by clearSecretCache. Fetches started before then do not store their values.
+	generation uint64
+}{
+	data:       make(map[string]string),
+	ttl:        secretCacheTTLFromEnv(),
+	fetchedAt:  make(map[string]time.Time),
+	clients:    make(map[string]SecretsManagerClient),
+	refreshing: make(map[string]bool),
+}
 
-func FetchSecretValue(key string) (string, error) {
+// secretCacheTTLFromEnv returns the secret cache TTL from the environment, 0 for unset or invalid values.
+func secretCacheTTLFromEnv() time.Duration {
+	value := os.Getenv(SecretCacheTTLEnvVar)
+	if value == "" {
+		return 0
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		slog.Warn("ignoring invalid secret cache TTL", "env", SecretCacheTTLEnvVar, "value", value)
+		return 0
+	}
+	return ttl
+}
+
+// clearSecretCache drops every cached secret so the next lookups go to Secrets Manager,
+// and re-reads AUTOBUILD_SECRET_CACHE_TTL.
+func clearSecretCache() {
 	secretCache.Lock()
-	if value, found := secretCache.data[key]; found {
-		secretCache.Unlock()
+	defer secretCache.Unlock()
+	secretCache.data = make(map[string]string)
+	secretCache.fetchedAt = make(map[string]time.Time)
+	secretCache.clients = make(map[string]SecretsManagerClient)
+	secretCache.ttl = secretCacheTTLFromEnv()
+	secretCache.generation++
+}
+
+// FetchSecretValue fetches key from the GitHub token secret.
+func FetchSecretValue(key string) (string, error) {
+	return FetchSecretByConfig(SecretConfig{
+		SecretID: secretIDFromEnv(GitHubTokenSecretIDEnvVar, DefaultGitHubTokenSecretID),
+		Key:      key,
+	})
+}
+
+// FetchSecretValueAtVersion fetches key from the version of the GitHub token secret
+// with the staging label versionStage, e.g. AWSPENDING while the token is rotated.
+func FetchSecretValueAtVersion(key, versionStage string) (string, error) {
+	return FetchSecretByConfig(SecretConfig{
+		SecretID:     secretIDFromEnv(GitHubTokenSecretIDEnvVar, DefaultGitHubTokenSecretID),
+		Key:          key,
+		VersionStage: versionStage,
+	})
+}
+
+// FetchSecretByConfig fetches cfg.Key from the JSON secret cfg.SecretID, caching every key of the secret.
+// The value is verified against cfg.ExpectedChecksum when one is set.
+func FetchSecretByConfig(cfg SecretConfig) (string, error) {
+	value, err := fetchSecret(cfg)
+	if err != nil {
+		return "", err
+	}
+	return verifySecretChecksum(cfg, value)
+}
+
+// verifySecretChecksum returns value when it matches cfg.ExpectedChecksum or no checksum is set.
+func verifySecretChecksum(cfg SecretConfig, value string) (string, error) {
+	if cfg.ExpectedChecksum != "" {
+		sum := sha256.Sum256([]byte(value))
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), cfg.ExpectedChecksum) {
+			return "", fmt.Errorf("secret key %s: %w", cfg.Key, ErrSecretTampered)
+		}
+	}
+	return value, nil
+}
+
+// fetchSecret returns cfg.Key from the cache, fetching the whole secret from Secrets Manager on a miss.
+func fetchSecret(cfg SecretConfig) (string, error) {
+	if value, found := cachedSecret(cfg); found {
 		return value, nil
 	}
-	secretCache.Unlock()
 
 	_, err := configLoader.LoadDefaultConfig(context.Background())
 	if err != nil {
-		return "", fmt.Errorf("error loading AWS config: %v", err)
+		return "", fmt.Errorf("%w: %v", ErrLoadingAWSConfig, err)
+	}
+
+	secretData, err := loadSecret(context.Background(), secretsManagerClient, cfg.SecretID, cfg.VersionStage)
+	if err != nil {
+		return "", err
 	}
 
-	client := secretsManagerClient
+	value, found := secretData[cfg.Key]
+	if !found {
+		return "", fmt.Errorf("%w: %s", ErrSecretKeyNotFound, cfg.Key)
+	}
+
+	return value, nil
+}
+
+// PrefetchSecrets loads keys into the secret cache with a single GetSecretValue call per secret,
+// so later fetches of those keys do not reach Secrets Manager. Keys that are already cached are skipped.
+func PrefetchSecrets(ctx context.Context, keys ...string) error {
+	_, err := configLoader.LoadDefaultConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrLoadingAWSConfig, err)
+	}
+	return prefetchSecrets(ctx, secretsManagerClient, keys...)
+}
+
+// prefetchSecrets is PrefetchSecrets with an explicit Secrets Manager client.
+func prefetchSecrets(ctx context.Context, client SecretsManagerClient, keys ...string) error {
+	// Group the missing keys by the secret that holds them, keeping the order of first use
+	var secretIDs []string
+	missing := map[string][]string{}
+	for _, key := range keys {
+		cfg := secretConfigForKey(key)
+		if _, found := cachedSecret(cfg); found {
+			continue
+		}
+		if _, seen := missing[cfg.SecretID]; !seen {
+			secretIDs = append(secretIDs, cfg.SecretID)
+		}
+		missing[cfg.SecretID] = append(missing[cfg.SecretID], key)
+	}
+
+	for _, secretID := range secretIDs {
+		secretData, err := loadSecret(ctx, client, secretID, "")
+		if err != nil {
+			return err
+		}
+		for _, key := range missing[secretID] {
+			if _, found := secretData[key]; !found {
+				return fmt.Errorf("%w: %s", ErrSecretKeyNotFound, key)
+			}
+		}
+	}
+	return nil
+}
+
+// secretConfigForKey returns the SecretConfig of a key used by AutoBuildGo.
+func secretConfigForKey(key string) SecretConfig {
+	if key == "TEMPLATE_URL" {
+		return SecretConfig{SecretID: secretIDFromEnv(TemplateURLSecretIDEnvVar, DefaultTemplateURLSecretID), Key: key}
+	}
+	return SecretConfig{SecretID: secretIDFromEnv(GitHubTokenSecretIDEnvVar, DefaultGitHubTokenSecretID), Key: key}
+}
+
+// cachedSecret returns the cached value of cfg, if any. Expired values are not returned, and values
+// within the last fifth of their TTL are returned while a background refresh fetches them again.
+func cachedSecret(cfg SecretConfig) (string, bool) {
+	secretCache.Lock()
+	defer secretCache.Unlock()
+	value, found := secretCache.data[cfg.cacheKey()]
+	if !found || secretCache.ttl == 0 {
+		return value, found
+	}
+	fetchedAt, ok := secretCache.fetchedAt[cfg.secretVersionKey()]
+	if !ok {
+		return value, true
+	}
+
+	age := timeNow().Sub(fetchedAt)
+	if age >= secretCache.ttl {
+		return "", false
+	}
+	if age >= secretCache.ttl-secretCache.ttl/5 && !secretCache.refreshing[cfg.secretVersionKey()] {
+		secretCache.refreshing[cfg.secretVersionKey()] = true
+		go refreshSecret(cfg, secretCache.clients[cfg.secretVersionKey()], secretCache.generation)
+	}
+	return value, true
+}
+
+// refreshSecret fetches the secret version holding cfg into the cache again with client, the one
+// that fetched it before, logging failures. The values are dropped when the cache was cleared since generation.
+// It runs in the background, so it does not use the context of the request that triggered it.
+func refreshSecret(cfg SecretConfig, client SecretsManagerClient, generation uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretPrefetchTimeout)
+	defer cancel()
+	_, err := loadSecretAtGeneration(ctx, client, cfg.SecretID, cfg.VersionStage, generation)
+
+	secretCache.Lock()
+	delete(secretCache.refreshing, cfg.secretVersionKey())
+	secretCache.Unlock()
+	if err != nil {
+		slog.Warn("failed to refresh secret", "secret", cfg.SecretID, "error", err)
+	}
+}
+
+// loadSecret fetches the JSON secret secretID at versionStage with client and caches every key of it.
+// An empty versionStage fetches AWSCURRENT.
+func loadSecret(ctx context.Context, client SecretsManagerClient, secretID, versionStage string) (map[string]string, error) {
+	secretCache.Lock()
+	generation := secretCache.generation
+	secretCache.Unlock()
+	return loadSecretAtGeneration(ctx, client, secretID, versionStage, generation)
+}
+
+// loadSecretAtGeneration is loadSecret caching the values only while the cache is still at generation,
+// so a fetch that overlaps clearSecretCache cannot bring the old values back.
+func loadSecretAtGeneration(ctx context.Context, client SecretsManagerClient, secretID, versionStage string, generation uint64) (map[string]string, error) {
 	input := &secretsmanager.GetSecretValueInput{
-		SecretId: aws.String("github_token"),
+		SecretId: aws.String(secretID),
+	}
+	if versionStage != "" {
+		input.VersionStage = aws.String(versionStage)
 	}
 
-	result, err := client.GetSecretValue(context.Background(), input)
+	result, err := client.GetSecretValue(ctx, input)
 	if err != nil {
-		return "", fmt.Errorf("error fetching secret value: %v", err)
+		return nil, fmt.Errorf("%w: %v", ErrFetchingSecret, err)
 	}
 
 	var secretData map[string]string
 	err = json.Unmarshal([]byte(*result.SecretString), &secretData)
 	if err != nil {
-		return "", fmt.Errorf("error unmarshalling secret value: %v", err)
+		return nil, fmt.Errorf("%w: %v", ErrUnmarshallingSecret, err)
 	}
 
 	secretCache.Lock()
-	for k, v := range secretData {
-		secretCache.data[k] = v
+	defer secretCache.Unlock()
+	if secretCache.generation != generation {
+		return secretData, nil
 	}
-	secretCache.Unlock()
-
-	value, found := secretData[key]
-	if !found {
-		return "", fmt.Errorf("secret key %s not found", key)
+	for k, v := range secretData {
+		secretCache.data[SecretConfig{SecretID: secretID, Key: k, VersionStage: versionStage}.cacheKey()] = v
 	}
+	versionKey := SecretConfig{SecretID: secretID, VersionStage: versionStage}.secretVersionKey()
+	secretCache.fetchedAt[versionKey] = timeNow()
+	secretCache.clients[versionKey] = client
 
-	return value, nil
+	return secretData, nil
 }
 
+// FetchSecretToken returns AUTOBUILD_GITHUB_TOKEN when set, or else the GitHub token from Secrets Manager,
+// read with IAM Identity Center credentials when USE_SSO is true.
 func FetchSecretToken() (string, error) {
-	return FetchSecretValue("GITHUB_TOKEN")
+	if token, ok := secretFromEnv(GitHubTokenEnvVar); ok {
+		return token, nil
+	}
+	return fetchSecretByConfigWithSSO(SecretConfig{
+		SecretID:         secretIDFromEnv(GitHubTokenSecretIDEnvVar, DefaultGitHubTokenSecretID),
+		Key:              "GITHUB_TOKEN",
+		ExpectedChecksum: os.Getenv(GitHubTokenChecksumEnvVar),
+	})
 }
 
+// FetchTemplateURL returns AUTOBUILD_TEMPLATE_URL when set, or else the template URL from Secrets Manager.
 func FetchTemplateURL() (string, error) {
-	return FetchSecretValue("TEMPLATE_URL")
+	if templateURL, ok := secretFromEnv(TemplateURLEnvVar); ok {
+		return templateURL, nil
+	}
+	return FetchSecretByConfig(SecretConfig{
+		SecretID: secretIDFromEnv(TemplateURLSecretIDEnvVar, DefaultTemplateURLSecretID),
+		Key:      "TEMPLATE_URL",
+	})
+}
+
+// secretFromEnv returns the value of envVar and warns that it overrides Secrets Manager.
+func secretFromEnv(envVar string) (string, bool) {
+	value := os.Getenv(envVar)
+	if value == "" {
+		return "", false
+	}
+	slog.Warn("using environment variable instead of Secrets Manager", "env", envVar)
+	return value, true
+}
+
+// secretIDFromEnv returns the secret ID set in envVar, or defaultID when it is unset.
+func secretIDFromEnv(envVar, defaultID string) string {
+	if id := os.Getenv(envVar); id != "" {
+		return id
+	}
+	return defaultID
 }
@@ -0,0 +1,54 @@
+//go:build integration
+
+package gitsetup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/lep13/AutoBuildGo/services/localstacktest"
+)
+
+func TestFetchSecretValue_LocalStack(t *testing.T) {
+	endpoint := localstacktest.Start(t)
+
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+	}
+	client := secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+	})
+
+	_, err := client.CreateSecret(context.Background(), &secretsmanager.CreateSecretInput{
+		Name:         aws.String(DefaultGitHubTokenSecretID),
+		SecretString: aws.String(`{"GITHUB_TOKEN": "integration_token", "TEMPLATE_URL": "integration_template_url"}`),
+	})
+	if err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+
+	originalClient := secretsManagerClient
+	secretsManagerClient = client
+	defer func() { secretsManagerClient = originalClient }()
+
+	// Clear the cache before the test
+	secretCache.Lock()
+	secretCache.data = make(map[string]string)
+	secretCache.Unlock()
+
+	token, err := FetchSecretValue("GITHUB_TOKEN")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if token != "integration_token" {
+		t.Errorf("expected token: %s, got: %s", "integration_token", token)
+	}
+
+	if _, err := FetchSecretValue("MISSING_KEY"); err == nil {
+		t.Errorf("expected an error for a missing key, got nil")
+	}
+}
@@ -2,9 +2,12 @@ package gitsetup
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -20,14 +23,22 @@ func (m *mockConfigLoader) LoadDefaultConfig(ctx context.Context, options ...fun
 type mockSecretsManagerClient struct {
 	secretString string
 	err          error
+	secretIDs    []string
+	// versionSecrets holds the secret string of each version stage, falling back to secretString
+	versionSecrets map[string]string
 }
 
 func (m *mockSecretsManagerClient) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	m.secretIDs = append(m.secretIDs, aws.ToString(params.SecretId))
 	if m.err != nil {
 		return nil, m.err
 	}
+	secretString := m.secretString
+	if versioned, ok := m.versionSecrets[aws.ToString(params.VersionStage)]; ok {
+		secretString = versioned
+	}
 	return &secretsmanager.GetSecretValueOutput{
-		SecretString: aws.String(m.secretString),
+		SecretString: aws.String(secretString),
 	}, nil
 }
 
@@ -148,3 +159,407 @@ func TestFetchTemplateURL(t *testing.T) {
 		t.Errorf("expected URL: %s, got: %s", "test_template_url", url)
 	}
 }
+
+func TestFetchSecretByConfig(t *testing.T) {
+	configLoader = &mockConfigLoader{}
+	mockClient := &mockSecretsManagerClient{secretString: `{"API_KEY": "other_api_key"}`}
+	secretsManagerClient = mockClient
+
+	// Clear the cache before the test
+	secretCache.Lock()
+	secretCache.data = make(map[string]string)
+	secretCache.Unlock()
+
+	cfg := SecretConfig{SecretID: "other_secret", Key: "API_KEY"}
+	value, err := FetchSecretByConfig(cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if value != "other_api_key" {
+		t.Errorf("expected value: %s, got: %s", "other_api_key", value)
+	}
+
+	// The second fetch is served from the cache keyed by secret ID and key
+	if _, err := FetchSecretByConfig(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(mockClient.secretIDs) != 1 || mockClient.secretIDs[0] != "other_secret" {
+		t.Errorf("expected a single fetch of other_secret, got: %v", mockClient.secretIDs)
+	}
+
+	// The same key in another secret is not served from the cache
+	if _, err := FetchSecretByConfig(SecretConfig{SecretID: "third_secret", Key: "API_KEY"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(mockClient.secretIDs) != 2 {
+		t.Errorf("expected a fetch of third_secret, got: %v", mockClient.secretIDs)
+	}
+}
+
+func TestFetchSecretValueAtVersion(t *testing.T) {
+	configLoader = &mockConfigLoader{}
+	mockClient := &mockSecretsManagerClient{
+		secretString:   `{"GITHUB_TOKEN": "current_token"}`,
+		versionSecrets: map[string]string{"AWSPENDING": `{"GITHUB_TOKEN": "pending_token"}`},
+	}
+	secretsManagerClient = mockClient
+
+	// Clear the cache before the test
+	secretCache.Lock()
+	secretCache.data = make(map[string]string)
+	secretCache.Unlock()
+
+	current, err := FetchSecretValue("GITHUB_TOKEN")
+	if err != nil || current != "current_token" {
+		t.Fatalf("expected current_token, got: %q, %v", current, err)
+	}
+
+	// The cached AWSCURRENT value must not be returned for another version stage
+	pending, err := FetchSecretValueAtVersion("GITHUB_TOKEN", "AWSPENDING")
+	if err != nil || pending != "pending_token" {
+		t.Fatalf("expected pending_token, got: %q, %v", pending, err)
+	}
+	if _, err := FetchSecretValueAtVersion("GITHUB_TOKEN", "AWSPENDING"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(mockClient.secretIDs) != 2 {
+		t.Errorf("expected one fetch per version stage, got: %v", mockClient.secretIDs)
+	}
+}
+
+func TestFetchSecretToken_SecretIDFromEnv(t *testing.T) {
+	t.Setenv(GitHubTokenSecretIDEnvVar, "custom_github_secret")
+
+	configLoader = &mockConfigLoader{}
+	mockClient := &mockSecretsManagerClient{secretString: `{"GITHUB_TOKEN": "custom_token"}`}
+	secretsManagerClient = mockClient
+
+	// Clear the cache before the test
+	secretCache.Lock()
+	secretCache.data = make(map[string]string)
+	secretCache.Unlock()
+
+	token, err := FetchSecretToken()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if token != "custom_token" {
+		t.Errorf("expected token: %s, got: %s", "custom_token", token)
+	}
+	if len(mockClient.secretIDs) != 1 || mockClient.secretIDs[0] != "custom_github_secret" {
+		t.Errorf("expected custom_github_secret to be fetched, got: %v", mockClient.secretIDs)
+	}
+}
+
+func TestFetchSecretByConfig_ExpectedChecksum(t *testing.T) {
+	configLoader = &mockConfigLoader{}
+	secretsManagerClient = &mockSecretsManagerClient{secretString: `{"GITHUB_TOKEN": "real_token"}`}
+
+	// Clear the cache before the test
+	secretCache.Lock()
+	secretCache.data = make(map[string]string)
+	secretCache.Unlock()
+
+	sum := sha256.Sum256([]byte("real_token"))
+	checksum := hex.EncodeToString(sum[:])
+
+	t.Run("Checksum Matches", func(t *testing.T) {
+		t.Setenv(GitHubTokenChecksumEnvVar, checksum)
+
+		token, err := FetchSecretToken()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if token != "real_token" {
+			t.Errorf("expected token: %s, got: %s", "real_token", token)
+		}
+	})
+
+	t.Run("Checksum Mismatch", func(t *testing.T) {
+		// The cached value is verified as well
+		other := sha256.Sum256([]byte("other_token"))
+		t.Setenv(GitHubTokenChecksumEnvVar, hex.EncodeToString(other[:]))
+
+		token, err := FetchSecretToken()
+		if !errors.Is(err, ErrSecretTampered) {
+			t.Errorf("expected %v, got: %v", ErrSecretTampered, err)
+		}
+		if token != "" {
+			t.Errorf("expected no token to be returned, got: %s", token)
+		}
+	})
+}
+
+func TestFetchSecrets_EnvOverride(t *testing.T) {
+	t.Setenv(GitHubTokenEnvVar, "env_token")
+	t.Setenv(TemplateURLEnvVar, "https://api.github.com/repos/template-owner/template-repo/generate")
+
+	mockClient := &mockSecretsManagerClient{err: errors.New("Secrets Manager unavailable")}
+	secretsManagerClient = mockClient
+
+	token, err := FetchSecretToken()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if token != "env_token" {
+		t.Errorf("expected token: %s, got: %s", "env_token", token)
+	}
+
+	templateURL, err := FetchTemplateURL()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if templateURL != "https://api.github.com/repos/template-owner/template-repo/generate" {
+		t.Errorf("unexpected template URL: %s", templateURL)
+	}
+
+	if len(mockClient.secretIDs) != 0 {
+		t.Errorf("expected Secrets Manager not to be called, got: %v", mockClient.secretIDs)
+	}
+}
+
+func TestPrefetchSecrets(t *testing.T) {
+	configLoader = &mockConfigLoader{}
+
+	// Clear the cache before the test
+	secretCache.Lock()
+	secretCache.data = make(map[string]string)
+	secretCache.Unlock()
+
+	t.Run("Single Call For Both Keys", func(t *testing.T) {
+		mockClient := &mockSecretsManagerClient{secretString: `{"GITHUB_TOKEN": "test_token", "TEMPLATE_URL": "test_template_url"}`}
+		secretsManagerClient = mockClient
+
+		if err := PrefetchSecrets(context.Background(), "GITHUB_TOKEN", "TEMPLATE_URL"); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(mockClient.secretIDs) != 1 {
+			t.Errorf("expected a single GetSecretValue call, got: %v", mockClient.secretIDs)
+		}
+
+		// Both keys are now served from the cache
+		if _, err := FetchSecretToken(); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if _, err := FetchTemplateURL(); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if err := PrefetchSecrets(context.Background(), "GITHUB_TOKEN"); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(mockClient.secretIDs) != 1 {
+			t.Errorf("expected cached keys not to be fetched again, got: %v", mockClient.secretIDs)
+		}
+	})
+
+	t.Run("Missing Key", func(t *testing.T) {
+		secretsManagerClient = &mockSecretsManagerClient{secretString: `{"GITHUB_TOKEN": "test_token"}`}
+
+		err := PrefetchSecrets(context.Background(), "OTHER_KEY")
+		if !errors.Is(err, ErrSecretKeyNotFound) || err.Error() != "secret key not found: OTHER_KEY" {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Fetch Error", func(t *testing.T) {
+		secretsManagerClient = &mockSecretsManagerClient{err: errors.New("access denied")}
+
+		err := PrefetchSecrets(context.Background(), "ANOTHER_KEY")
+		if !errors.Is(err, ErrFetchingSecret) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestPrewarmSecretCache(t *testing.T) {
+	// Clear the cache before the test
+	secretCache.Lock()
+	secretCache.data = make(map[string]string)
+	secretCache.Unlock()
+
+	t.Run("Warms Cache", func(t *testing.T) {
+		mockClient := &mockSecretsManagerClient{secretString: `{"GITHUB_TOKEN": "warm_token", "TEMPLATE_URL": "warm_template_url"}`}
+		prewarmSecretCache(mockClient)
+
+		if len(mockClient.secretIDs) != 1 {
+			t.Errorf("expected a single GetSecretValue call, got: %v", mockClient.secretIDs)
+		}
+		if value, found := cachedSecret(SecretConfig{SecretID: DefaultGitHubTokenSecretID, Key: "GITHUB_TOKEN"}); !found || value != "warm_token" {
+			t.Errorf("expected the token to be cached, got: %q", value)
+		}
+	})
+
+	t.Run("Environment Overrides", func(t *testing.T) {
+		t.Setenv(GitHubTokenEnvVar, "env_token")
+		t.Setenv(TemplateURLEnvVar, "env_template_url")
+
+		mockClient := &mockSecretsManagerClient{err: errors.New("should not be called")}
+		prewarmSecretCache(mockClient)
+
+		if len(mockClient.secretIDs) != 0 {
+			t.Errorf("expected Secrets Manager not to be called, got: %v", mockClient.secretIDs)
+		}
+	})
+
+	t.Run("Failure Is Not Fatal", func(t *testing.T) {
+		secretCache.Lock()
+		secretCache.data = make(map[string]string)
+		secretCache.Unlock()
+
+		// Only logs a warning
+		prewarmSecretCache(&mockSecretsManagerClient{err: errors.New("access denied")})
+	})
+}
+
+// useSecretCacheTTL empties the secret cache and sets its TTL until the test ends.
+func useSecretCacheTTL(t *testing.T, ttl time.Duration) {
+	clearSecretCache()
+	secretCache.Lock()
+	secretCache.ttl = ttl
+	secretCache.Unlock()
+	t.Cleanup(clearSecretCache)
+}
+
+// waitForSecretRefresh waits until no background refresh of the secret cache is in flight.
+func waitForSecretRefresh(t *testing.T) {
+	t.Helper()
+	for attempt := 0; attempt < 100; attempt++ {
+		secretCache.Lock()
+		refreshing := len(secretCache.refreshing)
+		secretCache.Unlock()
+		if refreshing == 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("background secret refresh did not finish")
+}
+
+func TestSecretCache_BackgroundRefresh(t *testing.T) {
+	now := fixedTime(t)
+	useSecretCacheTTL(t, time.Hour)
+	configLoader = &mockConfigLoader{}
+	mockClient := &mockSecretsManagerClient{secretString: `{"API_KEY": "v1"}`}
+	secretsManagerClient = mockClient
+	cfg := SecretConfig{SecretID: "service_secrets", Key: "API_KEY"}
+
+	fetch := func(expected string) {
+		t.Helper()
+		value, err := FetchSecretByConfig(cfg)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if value != expected {
+			t.Errorf("expected %q, got %q", expected, value)
+		}
+	}
+
+	fetch("v1")
+	*now = now.Add(30 * time.Minute)
+	fetch("v1")
+	if len(mockClient.secretIDs) != 1 {
+		t.Fatalf("expected no refresh before the last fifth of the TTL, got %d fetches", len(mockClient.secretIDs))
+	}
+
+	// Within the last fifth of the TTL the cached value is served while it is refreshed
+	mockClient.secretString = `{"API_KEY": "v2"}`
+	*now = now.Add(20 * time.Minute)
+	fetch("v1")
+	waitForSecretRefresh(t)
+	fetch("v2")
+	if len(mockClient.secretIDs) != 2 {
+		t.Errorf("expected one background refresh, got %d fetches", len(mockClient.secretIDs))
+	}
+
+	// An expired value is fetched again before it is returned
+	mockClient.secretString = `{"API_KEY": "v3"}`
+	*now = now.Add(2 * time.Hour)
+	fetch("v3")
+}
+
+func TestSecretCache_RefreshFailureKeepsValue(t *testing.T) {
+	now := fixedTime(t)
+	useSecretCacheTTL(t, time.Hour)
+	configLoader = &mockConfigLoader{}
+	mockClient := &mockSecretsManagerClient{secretString: `{"API_KEY": "v1"}`}
+	secretsManagerClient = mockClient
+	cfg := SecretConfig{SecretID: "service_secrets", Key: "API_KEY"}
+
+	if _, err := FetchSecretByConfig(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	mockClient.err = errors.New("throttled")
+	*now = now.Add(55 * time.Minute)
+	value, err := FetchSecretByConfig(cfg)
+	if err != nil || value != "v1" {
+		t.Errorf("expected the cached value, got %q, %v", value, err)
+	}
+	waitForSecretRefresh(t)
+
+	// The cache is read directly, since cachedSecret would start another refresh
+	secretCache.Lock()
+	value, found := secretCache.data[cfg.cacheKey()]
+	secretCache.Unlock()
+	if !found || value != "v1" {
+		t.Errorf("expected the value to stay cached after a failed refresh, got %q, %v", value, found)
+	}
+}
+
+// blockingSecretsManagerClient answers GetSecretValue with secretString once release is closed.
+type blockingSecretsManagerClient struct {
+	release      chan struct{}
+	secretString string
+}
+
+func (c *blockingSecretsManagerClient) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	<-c.release
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(c.secretString)}, nil
+}
+
+func TestSecretCache_RefreshAfterClear(t *testing.T) {
+	now := fixedTime(t)
+	useSecretCacheTTL(t, time.Hour)
+	configLoader = &mockConfigLoader{}
+	secretsManagerClient = &mockSecretsManagerClient{secretString: `{"API_KEY": "v1"}`}
+	cfg := SecretConfig{SecretID: "service_secrets", Key: "API_KEY"}
+
+	if _, err := FetchSecretByConfig(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	blocking := &blockingSecretsManagerClient{release: make(chan struct{}), secretString: `{"API_KEY": "v2"}`}
+	secretsManagerClient = blocking
+	*now = now.Add(55 * time.Minute)
+	if _, err := FetchSecretByConfig(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// The refresh in flight finishes after the cache was cleared and must not repopulate it
+	clearSecretCache()
+	close(blocking.release)
+	waitForSecretRefresh(t)
+
+	secretCache.Lock()
+	value, found := secretCache.data[cfg.cacheKey()]
+	secretCache.Unlock()
+	if found {
+		t.Errorf("expected the cleared cache to stay empty, got %q", value)
+	}
+}
+
+func TestSecretCacheTTLFromEnv(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{value: "", expected: 0},
+		{value: "30m", expected: 30 * time.Minute},
+		{value: "soon", expected: 0},
+		{value: "-1h", expected: 0},
+	}
+	for _, tt := range tests {
+		t.Setenv(SecretCacheTTLEnvVar, tt.value)
+		if got := secretCacheTTLFromEnv(); got != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.value, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// ForkRequest is the body of a /fork-repo request.
+type ForkRequest struct {
+	SourceOwner string `json:"source_owner"`
+	SourceRepo  string `json:"source_repo"`
+	TargetOrg   string `json:"target_org,omitempty"` // Empty forks into the authenticated user's account
+}
+
+// ForkGitRepository forks owner/repoName into org, or into the authenticated user's account when org is empty.
+// GitHub creates the fork asynchronously, so it may take a moment before it can be cloned.
+func (client *GitClient) ForkGitRepository(ctx context.Context, owner, repoName, org string) error {
+	token, err := client.refreshToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]string{}
+	if org != "" {
+		payload["organization"] = org
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	url := client.apiURL(fmt.Sprintf("/repos/%s/%s/forks", owner, repoName))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// GitHub answers 202 Accepted while the fork is being created.
+	if resp.StatusCode == http.StatusAccepted {
+		return nil
+	}
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return newGitHubError(resp.StatusCode, "failed to fork repository, status code: %d, response: %s", resp.StatusCode, string(body))
+}
+
+// ForkRepoHandler forks an existing GitHub repository and creates an ECR repository with the same name.
+func ForkRepoHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("ForkRepoHandler invoked")
+	var req ForkRequest
+	if !decodeJSONRequest(w, r, &req) {
+		return
+	}
+
+	if req.SourceOwner == "" || req.SourceRepo == "" {
+		http.Error(w, "Source owner and repository are required", http.StatusBadRequest)
+		return
+	}
+	// The fork and the ECR repository are named after the source repository
+	if err := ValidateRepoName(req.SourceRepo); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !acquireRepo(w, req.SourceRepo) {
+		return
+	}
+	defer createRequests.Release(req.SourceRepo)
+
+	// Fork first so nothing is created in ECR when the source cannot be forked
+	if err := ForkGitRepositoryFunc(NewGitClientFunc(), r.Context(), req.SourceOwner, req.SourceRepo, req.TargetOrg); err != nil {
+		writeGitHubError(w, "Failed to fork Git repository: ", err)
+		return
+	}
+
+	ecrRepo, ok := createECRRepository(w, RepoRequest{RepoName: req.SourceRepo})
+	if !ok {
+		return
+	}
+
+	writeRepoResponse(w, "ECR repository created and Git repository forked successfully", ecrRepo)
+}
@@ -0,0 +1,154 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	awsECR "github.com/aws/aws-sdk-go-v2/service/ecr"
+	localECR "github.com/lep13/AutoBuildGo/services/ecr"
+)
+
+func TestForkGitRepository(t *testing.T) {
+	tests := []struct {
+		name               string
+		org                string
+		status             int
+		expectedOrg        string
+		expectedErrMessage string
+	}{
+		{name: "Fork Into Organization", org: "my-org", status: http.StatusAccepted, expectedOrg: "my-org"},
+		{name: "Fork Into User Account", status: http.StatusAccepted},
+		{
+			name:               "Source Not Found",
+			status:             http.StatusNotFound,
+			expectedErrMessage: "failed to fork repository, status code: 404, response: Not Found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var url, auth string
+			var payload map[string]string
+			client := &GitClient{
+				HTTPClient: &mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						url, auth = req.URL.String(), req.Header.Get("Authorization")
+						json.NewDecoder(req.Body).Decode(&payload)
+						return &http.Response{StatusCode: tt.status, Body: io.NopCloser(bytes.NewBufferString("Not Found"))}, nil
+					},
+				},
+				FetchSecretFunc: mockFetchSecretFunc,
+				BaseURL:         "https://github.example.com/api/v3",
+			}
+
+			err := client.ForkGitRepository(context.Background(), "source-owner", "source-repo", tt.org)
+			if (err != nil) != (tt.expectedErrMessage != "") {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedErrMessage != "", err)
+			}
+			if err != nil && err.Error() != tt.expectedErrMessage {
+				t.Errorf("expected error message: %s, got: %s", tt.expectedErrMessage, err.Error())
+			}
+			if url != "https://github.example.com/api/v3/repos/source-owner/source-repo/forks" {
+				t.Errorf("unexpected request URL: %s", url)
+			}
+			if auth != "token mock_token" {
+				t.Errorf("unexpected Authorization header: %q", auth)
+			}
+			if payload["organization"] != tt.expectedOrg {
+				t.Errorf("expected organization %q, got %q", tt.expectedOrg, payload["organization"])
+			}
+		})
+	}
+}
+
+func TestForkRepoHandler(t *testing.T) {
+	originalFork := ForkGitRepositoryFunc
+	defer func() { ForkGitRepositoryFunc = originalFork }()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+		forkErr        error
+		expectedForks  int
+		expectedECR    int
+	}{
+		{
+			name:           "Successful Fork",
+			body:           `{"source_owner": "internal", "source_repo": "test-repo", "target_org": "my-org"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"message":"ECR repository created and Git repository forked successfully",` +
+				`"repository_uri":"123456789012.dkr.ecr.us-east-1.amazonaws.com/test-repo",` +
+				`"repository_arn":"arn:aws:ecr:us-east-1:123456789012:repository/test-repo"}`,
+			expectedForks: 1,
+			expectedECR:   1,
+		},
+		{
+			name:           "Fork Failed",
+			body:           `{"source_owner": "internal", "source_repo": "test-repo", "target_org": "my-org"}`,
+			forkErr:        errors.New("not found"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to fork Git repository: not found",
+			expectedForks:  1,
+		},
+		{
+			name:           "Invalid Source Repo",
+			body:           `{"source_owner": "internal", "source_repo": "../test-repo"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `invalid repo name "../test-repo": use up to 100 letters, digits, '.', '-' or '_'`,
+		},
+		{
+			name:           "Missing Source",
+			body:           `{"source_owner": "internal"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Source owner and repository are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var forks []string
+			ForkGitRepositoryFunc = func(client *GitClient, ctx context.Context, owner, repoName, org string) error {
+				forks = append(forks, owner+"/"+repoName+" -> "+org)
+				return tt.forkErr
+			}
+			ecrRepos := 0
+			CreateECRClientFunc = mockCreateECRClient
+			CreateRepoFunc = func(repoName string, client localECR.ECRClientInterface) (*awsECR.CreateRepositoryOutput, error) {
+				ecrRepos++
+				return mockCreateRepo(repoName, client)
+			}
+			NewGitClientFunc = mockNewGitClient
+
+			req := httptest.NewRequest(http.MethodPost, "/fork-repo", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ForkRepoHandler(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, body)
+			}
+			if len(forks) != tt.expectedForks {
+				t.Errorf("expected %d forks, got %q", tt.expectedForks, forks)
+			}
+			if ecrRepos != tt.expectedECR {
+				t.Errorf("expected %d ECR repositories, got %d", tt.expectedECR, ecrRepos)
+			}
+			if tt.expectedForks > 0 && forks[0] != "internal/test-repo -> my-org" {
+				t.Errorf("unexpected fork: %q", forks[0])
+			}
+		})
+	}
+}
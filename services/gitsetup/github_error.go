@@ -0,0 +1,41 @@
+package gitsetup
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// GitHubError is returned when the GitHub API answers with an unexpected status code.
+// Use errors.As to inspect the status code.
+type GitHubError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *GitHubError) Error() string {
+	return e.Message
+}
+
+// newGitHubError returns a GitHubError for statusCode with a message built from format and args.
+func newGitHubError(statusCode int, format string, args ...any) *GitHubError {
+	return &GitHubError{
+		StatusCode: statusCode,
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+// IsNotFound reports whether err is a GitHubError for a 404 Not Found response.
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is a GitHubError for a 401 Unauthorized response.
+func IsUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized)
+}
+
+func hasStatusCode(err error, statusCode int) bool {
+	var gitHubErr *GitHubError
+	return errors.As(err, &gitHubErr) && gitHubErr.StatusCode == statusCode
+}
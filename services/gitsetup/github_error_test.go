@@ -0,0 +1,84 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestGitHubErrorHelpers(t *testing.T) {
+	notFound := newGitHubError(http.StatusNotFound, "failed to fetch repository, status code: %d", http.StatusNotFound)
+	unauthorized := newGitHubError(http.StatusUnauthorized, "bad credentials")
+
+	tests := []struct {
+		name                 string
+		err                  error
+		expectedNotFound     bool
+		expectedUnauthorized bool
+	}{
+		{name: "Not Found", err: notFound, expectedNotFound: true},
+		{name: "Unauthorized", err: unauthorized, expectedUnauthorized: true},
+		{name: "Wrapped Not Found", err: fmt.Errorf("error fetching repository: %w", notFound), expectedNotFound: true},
+		{name: "Server Error", err: newGitHubError(http.StatusInternalServerError, "server error")},
+		{name: "Other Error", err: errors.New("failed to fetch repository")},
+		{name: "Nil Error", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFound(tt.err); got != tt.expectedNotFound {
+				t.Errorf("IsNotFound: expected %v, got %v", tt.expectedNotFound, got)
+			}
+			if got := IsUnauthorized(tt.err); got != tt.expectedUnauthorized {
+				t.Errorf("IsUnauthorized: expected %v, got %v", tt.expectedUnauthorized, got)
+			}
+		})
+	}
+
+	if notFound.Error() != "failed to fetch repository, status code: 404" {
+		t.Errorf("unexpected error message: %s", notFound.Error())
+	}
+}
+
+func TestFetchGitHubUsername_Unauthorized(t *testing.T) {
+	client := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+		},
+	}
+
+	_, err := fetchGitHubUsername(context.Background(), client, "bad_token", "https://api.github.com/user")
+
+	var gitHubErr *GitHubError
+	if !errors.As(err, &gitHubErr) {
+		t.Fatalf("expected a *GitHubError, got %T", err)
+	}
+	if gitHubErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code %d, got %d", http.StatusUnauthorized, gitHubErr.StatusCode)
+	}
+	if !IsUnauthorized(err) {
+		t.Errorf("expected IsUnauthorized to be true")
+	}
+}
+
+func TestFetchGitHubUsername_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Context() != ctx {
+				t.Error("expected the request to carry the context")
+			}
+			return nil, req.Context().Err()
+		},
+	}
+
+	if _, err := fetchGitHubUsername(ctx, client, "token", "https://api.github.com/user"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -2,10 +2,14 @@ package gitsetup
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
 // HTTPClient is an interface that defines the Do method used by http.Client
@@ -14,62 +18,302 @@ type HTTPClient interface {
 }
 
 // GitClient is a structure that holds dependencies for making HTTP requests.
+// It caches its token, so it must not be copied after first use; share a *GitClient instead.
 type GitClient struct {
 	HTTPClient      HTTPClient
 	FetchSecretFunc func() (string, error)
+	BaseURL         string // Base URL of the GitHub API, overridable for GitHub Enterprise
+	// TokenLifetime is how long a token returned by FetchSecretFunc stays valid. When set, the token is
+	// cached and only fetched again shortly before it expires. Zero fetches a token for every call.
+	TokenLifetime time.Duration
+
+	tokenMu        sync.Mutex
+	token          string
+	tokenExpiresAt time.Time
+}
+
+// GitClientOption configures a GitClient created by NewGitClientWithOptions.
+type GitClientOption func(*gitClientOptions)
+
+// gitClientOptions holds the settings applied by GitClientOption functions.
+type gitClientOptions struct {
+	timeout          time.Duration
+	transport        http.RoundTripper
+	baseURL          string
+	maxRateLimitWait time.Duration
+	tokenLifetime    time.Duration
+}
+
+// WithHTTPTimeout sets the per-request timeout, DefaultHTTPTimeout when not set. Zero disables it.
+func WithHTTPTimeout(d time.Duration) GitClientOption {
+	return func(o *gitClientOptions) {
+		o.timeout = d
+	}
+}
+
+// WithMaxRateLimitWait sets how long requests rejected by a GitHub rate limit are retried,
+// DefaultMaxRateLimitWait when not set. Zero disables the retries.
+func WithMaxRateLimitWait(d time.Duration) GitClientOption {
+	return func(o *gitClientOptions) {
+		o.maxRateLimitWait = d
+	}
+}
+
+// WithTransport sets the transport of the underlying http.Client, e.g. for proxy settings.
+// By default all clients share one pooled transport.
+func WithTransport(transport http.RoundTripper) GitClientOption {
+	return func(o *gitClientOptions) {
+		o.transport = transport
+	}
+}
+
+// WithBaseURL overrides the GitHub API base URL, e.g. for GitHub Enterprise.
+func WithBaseURL(url string) GitClientOption {
+	return func(o *gitClientOptions) {
+		o.baseURL = strings.TrimSuffix(url, "/")
+	}
 }
 
 // NewGitClient returns an instance of GitClient with default dependencies.
 func NewGitClient() *GitClient {
+	return NewGitClientWithOptions()
+}
+
+// NewGitClientWithOptions returns an instance of GitClient configured with the given options.
+func NewGitClientWithOptions(opts ...GitClientOption) *GitClient {
+	options := gitClientOptions{
+		timeout:          DefaultHTTPTimeout,
+		transport:        sharedTransport,
+		baseURL:          gitHubAPIURL,
+		maxRateLimitWait: DefaultMaxRateLimitWait,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var client HTTPClient = &http.Client{Transport: options.transport}
+	if options.timeout > 0 {
+		client = NewTimeoutHTTPClient(client, options.timeout)
+	}
+	if options.maxRateLimitWait > 0 {
+		client = NewRateLimitHTTPClient(client, options.maxRateLimitWait)
+	}
+
 	return &GitClient{
-		HTTPClient:      &http.Client{},
+		HTTPClient:      client,
 		FetchSecretFunc: FetchSecretToken,
+		BaseURL:         options.baseURL,
+		TokenLifetime:   options.tokenLifetime,
 	}
 }
 
-// CreateGitRepository creates a new GitHub repository using the specified configuration.
-func (client *GitClient) CreateGitRepository(config RepoConfig) error {
+// CreateGitRepository creates a new GitHub repository using the specified configuration
+// and returns the URLs of the created repository. Wait with result.Ready before cloning it.
+// Calls are made through the GitHub circuit breaker, so ErrGitHubUnavailable is returned while GitHub is failing.
+func (client *GitClient) CreateGitRepository(config RepoConfig) (RepoCreateResult, error) {
+	return client.CreateGitRepositoryContext(context.Background(), config)
+}
+
+// CreateGitRepositoryContext is CreateGitRepository with a context that cancels the GitHub API calls.
+func (client *GitClient) CreateGitRepositoryContext(ctx context.Context, config RepoConfig) (RepoCreateResult, error) {
+	return withGitHubBreaker(func() (RepoCreateResult, error) {
+		return client.createGitRepository(ctx, config)
+	})
+}
+
+// createGitRepository implements CreateGitRepositoryContext without the circuit breaker.
+func (client *GitClient) createGitRepository(ctx context.Context, config RepoConfig) (RepoCreateResult, error) {
 	// Fetch the token using the FetchSecretToken function.
-	token, err := client.FetchSecretFunc()
+	token, err := client.refreshToken(ctx)
 	if err != nil {
-		return err
+		return RepoCreateResult{}, err
+	}
+
+	// Make sure the template URL points at a template generate endpoint before posting to it.
+	if err := ValidateTemplateURL(config.TemplateURL); err != nil {
+		return RepoCreateResult{}, err
 	}
-	return client.createRepositoryWithTemplate(config, token)
+	// The settings are checked before the repository is created, so invalid Extra keys leave nothing behind.
+	settings, err := repositorySettings(config)
+	if err != nil {
+		return RepoCreateResult{}, err
+	}
+	result, err := client.createRepositoryWithTemplate(ctx, config, token)
+	if err != nil {
+		return RepoCreateResult{}, err
+	}
+
+	// The generate endpoint ignores most repository settings, so they are applied with one PATCH afterwards.
+	if len(settings) > 0 {
+		if err := client.updateRepository(ctx, token, config.Name, settings); err != nil {
+			return RepoCreateResult{}, fmt.Errorf("failed to apply repository settings: %w", err)
+		}
+	}
+
+	// Topics are not accepted by the generate endpoint either.
+	if len(config.Topics) > 0 {
+		if err := client.replaceTopics(ctx, token, config.Name, config.Topics); err != nil {
+			return RepoCreateResult{}, fmt.Errorf("failed to set topics: %w", err)
+		}
+	}
+
+	if len(config.Environments) > 0 {
+		owner, err := fetchGitHubUsername(ctx, client.HTTPClient, token, client.apiURL("/user"))
+		if err != nil {
+			return RepoCreateResult{}, err
+		}
+		for _, env := range config.Environments {
+			if err := client.createEnvironment(ctx, token, owner, config.Name, env); err != nil {
+				return RepoCreateResult{}, fmt.Errorf("failed to create environments: %w", err)
+			}
+		}
+	}
+	result.Ready = client.newRepoReadyWaiter(config, result)
+	return result, nil
 }
 
 // createRepositoryWithTemplate sends a request to GitHub API to create a repository from a template.
-func (client *GitClient) createRepositoryWithTemplate(config RepoConfig, token string) error {
-	data, err := json.Marshal(map[string]interface{}{
+func (client *GitClient) createRepositoryWithTemplate(ctx context.Context, config RepoConfig, token string) (RepoCreateResult, error) {
+	payload := map[string]interface{}{
 		"name":        config.Name,
 		"description": config.Description,
 		"private":     config.Private,
-	})
+	}
+
+	data, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return RepoCreateResult{}, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, config.TemplateURL, bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.TemplateURL, bytes.NewBuffer(data))
 	if err != nil {
-		return err
+		return RepoCreateResult{}, err
 	}
 
 	req.Header.Set("Authorization", "token "+token)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return RepoCreateResult{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return RepoCreateResult{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		return RepoCreateResult{}, newGitHubError(resp.StatusCode, "failed to create repository, status code: %d, response: %s", resp.StatusCode, string(body))
+	}
+
+	// The generate endpoint answers with the full repository, but an empty body is not treated as a failure
+	var result RepoCreateResult
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &result); err != nil {
+			return RepoCreateResult{}, fmt.Errorf("failed to decode created repository: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// CheckGitRepositoryExists reports whether the authenticated user owns a repository with the given name.
+func (client *GitClient) CheckGitRepositoryExists(repoName string) (bool, error) {
+	return client.CheckGitRepositoryExistsContext(context.Background(), repoName)
+}
+
+// CheckGitRepositoryExistsContext is CheckGitRepositoryExists with a context that cancels the GitHub API calls.
+func (client *GitClient) CheckGitRepositoryExistsContext(ctx context.Context, repoName string) (bool, error) {
+	resp, err := client.doRepoRequest(ctx, http.MethodGet, repoName)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return false, newGitHubError(resp.StatusCode, "failed to check repository, status code: %d, response: %s", resp.StatusCode, string(body))
+}
+
+// DeleteGitRepository deletes the authenticated user's repository with the given name.
+func (client *GitClient) DeleteGitRepository(repoName string) error {
+	resp, err := client.doRepoRequest(context.Background(), http.MethodDelete, repoName)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusCreated {
+	if resp.StatusCode == http.StatusNoContent {
 		return nil
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponseBody(resp.Body)
 	if err != nil {
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	return fmt.Errorf("failed to create repository, status code: %d, response: %s", resp.StatusCode, string(body))
+	return newGitHubError(resp.StatusCode, "failed to delete repository, status code: %d, response: %s", resp.StatusCode, string(body))
+}
+
+// doRepoRequest sends a request without a body to /repos/{owner}/{repo} for the authenticated user.
+func (client *GitClient) doRepoRequest(ctx context.Context, method, repoName string) (*http.Response, error) {
+	token, err := client.refreshToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.sendRepoRequest(ctx, token, method, repoName, "", nil)
+}
+
+// sendRepoRequest sends a request to /repos/{owner}/{repo} followed by subPath for the user the token belongs to.
+// A non-nil payload is sent as the JSON request body.
+func (client *GitClient) sendRepoRequest(ctx context.Context, token, method, repoName, subPath string, payload any) (*http.Response, error) {
+	owner, err := fetchGitHubUsername(ctx, client.HTTPClient, token, client.apiURL("/user"))
+	if err != nil {
+		return nil, err
+	}
+	return client.sendOwnerRepoRequest(ctx, token, method, owner, repoName, subPath, payload)
+}
+
+// sendOwnerRepoRequest sends a request to /repos/{owner}/{repo} followed by subPath.
+// A non-nil payload is sent as the JSON request body.
+func (client *GitClient) sendOwnerRepoRequest(ctx context.Context, token, method, owner, repoName, subPath string, payload any) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewBuffer(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, client.apiURL(fmt.Sprintf("/repos/%s/%s%s", owner, repoName, subPath)), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return client.HTTPClient.Do(req)
+}
+
+// apiURL returns the full GitHub API URL for path, defaulting to api.github.com when BaseURL is unset.
+func (client *GitClient) apiURL(path string) string {
+	baseURL := client.BaseURL
+	if baseURL == "" {
+		baseURL = gitHubAPIURL
+	}
+	return baseURL + path
 }
@@ -2,10 +2,13 @@ package gitsetup
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
+	"reflect"
 	"testing"
+	"time"
 )
 
 // mockHTTPClient is a mock implementation of the HTTPClient interface.
@@ -37,6 +40,12 @@ func TestCreateGitRepository(t *testing.T) {
 		{
 			name: "Successful Repository Creation",
 			doFunc: func(req *http.Request) (*http.Response, error) {
+				switch req.Method {
+				case http.MethodGet:
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+				case http.MethodPatch:
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("{}"))}, nil
+				}
 				return &http.Response{
 					StatusCode: http.StatusCreated,
 					Body:       io.NopCloser(bytes.NewBufferString("")),
@@ -114,7 +123,7 @@ func TestCreateGitRepository(t *testing.T) {
 				FetchSecretFunc: tt.fetchSecretFunc,
 			}
 
-			err := client.CreateGitRepository(tt.config)
+			_, err := client.CreateGitRepository(tt.config)
 			if (err != nil) != (tt.expectedErrMessage != "") {
 				t.Errorf("expected error: %v, got: %v", tt.expectedErrMessage != "", err)
 			}
@@ -124,11 +133,33 @@ func TestCreateGitRepository(t *testing.T) {
 		})
 	}
 }
+
+// unwrapRateLimitClient returns the client wrapped by the RateLimitHTTPClient of a GitClient and its maximum wait.
+func unwrapRateLimitClient(t *testing.T, client HTTPClient) (HTTPClient, time.Duration) {
+	t.Helper()
+	rateLimitClient, ok := client.(*RateLimitHTTPClient)
+	if !ok {
+		t.Fatalf("expected HTTPClient to be of type *RateLimitHTTPClient, got %T", client)
+	}
+	return rateLimitClient.Client, rateLimitClient.MaxWait
+}
+
 func TestNewGitClient(t *testing.T) {
 	client := NewGitClient()
 
-	if _, ok := client.HTTPClient.(*http.Client); !ok {
-		t.Errorf("expected HTTPClient to be of type *http.Client, got %T", client.HTTPClient)
+	wrapped, maxWait := unwrapRateLimitClient(t, client.HTTPClient)
+	if maxWait != DefaultMaxRateLimitWait {
+		t.Errorf("expected maximum rate limit wait %v, got %v", DefaultMaxRateLimitWait, maxWait)
+	}
+	timeoutClient, ok := wrapped.(*TimeoutHTTPClient)
+	if !ok {
+		t.Fatalf("expected the rate limit client to wrap a *TimeoutHTTPClient, got %T", wrapped)
+	}
+	if timeoutClient.Timeout != DefaultHTTPTimeout {
+		t.Errorf("expected timeout %v, got %v", DefaultHTTPTimeout, timeoutClient.Timeout)
+	}
+	if _, ok := timeoutClient.Client.(*http.Client); !ok {
+		t.Errorf("expected wrapped client to be of type *http.Client, got %T", timeoutClient.Client)
 	}
 
 	if client.FetchSecretFunc == nil {
@@ -144,3 +175,634 @@ func TestNewGitClient(t *testing.T) {
 	// 	t.Errorf("expected a non-empty token from FetchSecretFunc, got an empty string")
 	// }
 }
+
+func TestNewGitClientWithOptions(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		client := NewGitClientWithOptions()
+
+		wrapped, _ := unwrapRateLimitClient(t, client.HTTPClient)
+		timeoutClient, ok := wrapped.(*TimeoutHTTPClient)
+		if !ok {
+			t.Fatalf("expected the rate limit client to wrap a *TimeoutHTTPClient, got %T", wrapped)
+		}
+		if timeoutClient.Timeout != DefaultHTTPTimeout {
+			t.Errorf("expected timeout %v, got %v", DefaultHTTPTimeout, timeoutClient.Timeout)
+		}
+		if httpClient := timeoutClient.Client.(*http.Client); httpClient.Transport != sharedTransport {
+			t.Errorf("expected the shared transport, got %T", httpClient.Transport)
+		}
+		if client.BaseURL != "https://api.github.com" {
+			t.Errorf("expected base URL %q, got %q", "https://api.github.com", client.BaseURL)
+		}
+	})
+
+	t.Run("Custom Options", func(t *testing.T) {
+		transport := &http.Transport{}
+		client := NewGitClientWithOptions(
+			WithHTTPTimeout(5*time.Second),
+			WithTransport(transport),
+			WithBaseURL("https://github.example.com/api/v3/"),
+			WithMaxRateLimitWait(5*time.Minute),
+		)
+
+		wrapped, maxWait := unwrapRateLimitClient(t, client.HTTPClient)
+		if maxWait != 5*time.Minute {
+			t.Errorf("expected maximum rate limit wait %v, got %v", 5*time.Minute, maxWait)
+		}
+		timeoutClient, ok := wrapped.(*TimeoutHTTPClient)
+		if !ok {
+			t.Fatalf("expected the rate limit client to wrap a *TimeoutHTTPClient, got %T", wrapped)
+		}
+		if timeoutClient.Timeout != 5*time.Second {
+			t.Errorf("expected timeout %v, got %v", 5*time.Second, timeoutClient.Timeout)
+		}
+		if httpClient := timeoutClient.Client.(*http.Client); httpClient.Transport != transport {
+			t.Errorf("expected custom transport to be used")
+		}
+		if client.BaseURL != "https://github.example.com/api/v3" {
+			t.Errorf("expected base URL %q, got %q", "https://github.example.com/api/v3", client.BaseURL)
+		}
+	})
+
+	t.Run("Timeout And Rate Limit Retries Disabled", func(t *testing.T) {
+		client := NewGitClientWithOptions(WithHTTPTimeout(0), WithMaxRateLimitWait(0))
+
+		if _, ok := client.HTTPClient.(*http.Client); !ok {
+			t.Errorf("expected HTTPClient to be of type *http.Client, got %T", client.HTTPClient)
+		}
+	})
+}
+
+// repoAPIClient answers /user with the given login and every other request with repoStatus.
+func repoAPIClient(t *testing.T, expectedMethod string, repoStatus int) *mockHTTPClient {
+	return &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/user" {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`)),
+				}, nil
+			}
+			if req.Method != expectedMethod || req.URL.Path != "/repos/octocat/test-repo" {
+				t.Errorf("unexpected request: %s %s", req.Method, req.URL.Path)
+			}
+			return &http.Response{
+				StatusCode: repoStatus,
+				Body:       io.NopCloser(bytes.NewBufferString("Server Error")),
+			}, nil
+		},
+	}
+}
+
+func TestCheckGitRepositoryExists(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		expectedExists bool
+		expectedErr    bool
+	}{
+		{name: "Repository Exists", status: http.StatusOK, expectedExists: true},
+		{name: "Repository Not Found", status: http.StatusNotFound, expectedExists: false},
+		{name: "Server Error", status: http.StatusInternalServerError, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &GitClient{
+				HTTPClient:      repoAPIClient(t, http.MethodGet, tt.status),
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+
+			exists, err := client.CheckGitRepositoryExists("test-repo")
+			if (err != nil) != tt.expectedErr {
+				t.Errorf("expected error: %v, got: %v", tt.expectedErr, err)
+			}
+			if exists != tt.expectedExists {
+				t.Errorf("expected exists: %v, got: %v", tt.expectedExists, exists)
+			}
+		})
+	}
+}
+
+func TestDeleteGitRepository(t *testing.T) {
+	t.Run("Repository Deleted", func(t *testing.T) {
+		client := &GitClient{
+			HTTPClient:      repoAPIClient(t, http.MethodDelete, http.StatusNoContent),
+			FetchSecretFunc: mockFetchSecretFunc,
+		}
+		if err := client.DeleteGitRepository("test-repo"); err != nil {
+			t.Errorf("expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("Delete Forbidden", func(t *testing.T) {
+		client := &GitClient{
+			HTTPClient:      repoAPIClient(t, http.MethodDelete, http.StatusForbidden),
+			FetchSecretFunc: mockFetchSecretFunc,
+		}
+		err := client.DeleteGitRepository("test-repo")
+		expected := "failed to delete repository, status code: 403, response: Server Error"
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	})
+
+	t.Run("Fetch Secret Token Error", func(t *testing.T) {
+		client := &GitClient{
+			HTTPClient:      &mockHTTPClient{},
+			FetchSecretFunc: mockFetchSecretFuncError,
+		}
+		if err := client.DeleteGitRepository("test-repo"); err == nil {
+			t.Errorf("expected an error, got nil")
+		}
+	})
+}
+
+func TestCreateGitRepository_Result(t *testing.T) {
+	responseBody := `{
+		"full_name": "octocat/test-repo",
+		"html_url": "https://github.com/octocat/test-repo",
+		"clone_url": "https://github.com/octocat/test-repo.git",
+		"ssh_url": "git@github.com:octocat/test-repo.git",
+		"private": true
+	}`
+	config := RepoConfig{
+		Name:        "test-repo",
+		TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+	}
+
+	tests := []struct {
+		name               string
+		body               string
+		expected           RepoCreateResult
+		expectedErrMessage string
+	}{
+		{
+			name: "Full Repository",
+			body: responseBody,
+			expected: RepoCreateResult{
+				HTMLURL:  "https://github.com/octocat/test-repo",
+				CloneURL: "https://github.com/octocat/test-repo.git",
+				SSHURL:   "git@github.com:octocat/test-repo.git",
+				FullName: "octocat/test-repo",
+			},
+		},
+		{name: "Empty Body", body: ""},
+		{
+			name:               "Invalid JSON",
+			body:               "not json",
+			expectedErrMessage: "failed to decode created repository: invalid character 'o' in literal null (expecting 'u')",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &GitClient{
+				HTTPClient: &mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(bytes.NewBufferString(tt.body))}, nil
+					},
+				},
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+
+			result, err := client.CreateGitRepository(config)
+			if (err != nil) != (tt.expectedErrMessage != "") {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedErrMessage != "", err)
+			}
+			if err != nil && err.Error() != tt.expectedErrMessage {
+				t.Errorf("expected error message: %s, got: %s", tt.expectedErrMessage, err.Error())
+			}
+			if err == nil && result.Ready == nil {
+				t.Error("expected a RepoReadyWaiter for the created repository")
+			}
+			result.Ready = nil
+			if result != tt.expected {
+				t.Errorf("expected result %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCreateGitRepository_Description(t *testing.T) {
+	tests := []struct {
+		name               string
+		description        string
+		patchStatus        int
+		expectedPatch      bool
+		expectedErrMessage string
+	}{
+		{name: "No Description"},
+		{name: "Description Set", description: "test description", patchStatus: http.StatusOK, expectedPatch: true},
+		{
+			name:               "Description Rejected",
+			description:        "test description",
+			patchStatus:        http.StatusUnprocessableEntity,
+			expectedPatch:      true,
+			expectedErrMessage: "failed to apply repository settings: failed to update repository, status code: 422, response: Validation Failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patchBody map[string]any
+			client := &GitClient{
+				HTTPClient: &mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						switch {
+						case req.Method == http.MethodPost:
+							return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+						case req.URL.Path == "/user":
+							return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+						case req.Method == http.MethodPatch && req.URL.Path == "/repos/octocat/test-repo":
+							json.NewDecoder(req.Body).Decode(&patchBody)
+							return &http.Response{StatusCode: tt.patchStatus, Body: io.NopCloser(bytes.NewBufferString("Validation Failed"))}, nil
+						}
+						t.Errorf("unexpected request: %s %s", req.Method, req.URL)
+						return nil, errors.New("unexpected request")
+					},
+				},
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+
+			_, err := client.CreateGitRepository(RepoConfig{
+				Name:        "test-repo",
+				Description: tt.description,
+				TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+			})
+			if (err != nil) != (tt.expectedErrMessage != "") {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedErrMessage != "", err)
+			}
+			if err != nil && err.Error() != tt.expectedErrMessage {
+				t.Errorf("expected error message: %s, got: %s", tt.expectedErrMessage, err.Error())
+			}
+			if tt.expectedPatch && patchBody["description"] != tt.description {
+				t.Errorf("expected description %q, got %v", tt.description, patchBody["description"])
+			}
+			if !tt.expectedPatch && patchBody != nil {
+				t.Errorf("expected no PATCH request, got %v", patchBody)
+			}
+		})
+	}
+}
+
+func TestCreateGitRepository_SettingsInOnePatch(t *testing.T) {
+	var patchBodies []map[string]any
+	client := &GitClient{
+		HTTPClient: &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				switch {
+				case req.Method == http.MethodPost:
+					return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+				case req.URL.Path == "/user":
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+				case req.Method == http.MethodPatch && req.URL.Path == "/repos/octocat/test-repo":
+					var body map[string]any
+					json.NewDecoder(req.Body).Decode(&body)
+					patchBodies = append(patchBodies, body)
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("{}"))}, nil
+				}
+				t.Errorf("unexpected request: %s %s", req.Method, req.URL)
+				return nil, errors.New("unexpected request")
+			},
+		},
+		FetchSecretFunc: mockFetchSecretFunc,
+	}
+
+	_, err := client.CreateGitRepository(RepoConfig{
+		Name:                   "test-repo",
+		Description:            "test description",
+		TemplateURL:            "https://api.github.com/repos/template-owner/template-repo/generate",
+		DefaultBranch:          "master",
+		AutoDeleteHeadBranches: true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(patchBodies) != 1 {
+		t.Fatalf("expected one PATCH request, got %d", len(patchBodies))
+	}
+	// The default branch does not exist yet, so it is renamed by the ready waiter instead.
+	expected := map[string]any{
+		"description":            "test description",
+		"delete_branch_on_merge": true,
+	}
+	if !reflect.DeepEqual(patchBodies[0], expected) {
+		t.Errorf("expected PATCH body %v, got %v", expected, patchBodies[0])
+	}
+}
+
+func TestCreateGitRepository_AutoDeleteHeadBranches(t *testing.T) {
+	tests := []struct {
+		name               string
+		enabled            bool
+		patchStatus        int
+		expectedPatch      bool
+		expectedErrMessage string
+	}{
+		{name: "Disabled", enabled: false},
+		{name: "Enabled", enabled: true, patchStatus: http.StatusOK, expectedPatch: true},
+		{
+			name:               "Rejected",
+			enabled:            true,
+			patchStatus:        http.StatusForbidden,
+			expectedPatch:      true,
+			expectedErrMessage: "failed to apply repository settings: failed to update repository, status code: 403, response: Must have admin rights",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var createBody, patchBody map[string]any
+			client := &GitClient{
+				HTTPClient: &mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						switch {
+						case req.Method == http.MethodPost:
+							json.NewDecoder(req.Body).Decode(&createBody)
+							return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+						case req.URL.Path == "/user":
+							return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+						case req.Method == http.MethodPatch && req.URL.Path == "/repos/octocat/test-repo":
+							json.NewDecoder(req.Body).Decode(&patchBody)
+							return &http.Response{StatusCode: tt.patchStatus, Body: io.NopCloser(bytes.NewBufferString("Must have admin rights"))}, nil
+						}
+						t.Errorf("unexpected request: %s %s", req.Method, req.URL)
+						return nil, errors.New("unexpected request")
+					},
+				},
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+
+			_, err := client.CreateGitRepository(RepoConfig{
+				Name:                   "test-repo",
+				TemplateURL:            "https://api.github.com/repos/template-owner/template-repo/generate",
+				AutoDeleteHeadBranches: tt.enabled,
+			})
+			if (err != nil) != (tt.expectedErrMessage != "") {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedErrMessage != "", err)
+			}
+			if err != nil && err.Error() != tt.expectedErrMessage {
+				t.Errorf("expected error message: %s, got: %s", tt.expectedErrMessage, err.Error())
+			}
+			if _, found := createBody["delete_branch_on_merge"]; found {
+				t.Error("expected delete_branch_on_merge to be left out of the generate request")
+			}
+			if tt.expectedPatch && patchBody["delete_branch_on_merge"] != true {
+				t.Errorf("expected delete_branch_on_merge true, got %v", patchBody["delete_branch_on_merge"])
+			}
+			if !tt.expectedPatch && patchBody != nil {
+				t.Errorf("expected no PATCH request, got %v", patchBody)
+			}
+		})
+	}
+}
+
+func TestCreateGitRepository_Topics(t *testing.T) {
+	var topicsBody map[string][]string
+	client := &GitClient{
+		HTTPClient: &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				switch {
+				case req.Method == http.MethodPost:
+					return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+				case req.URL.Path == "/user":
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+				case req.Method == http.MethodPut && req.URL.Path == "/repos/octocat/test-repo/topics":
+					json.NewDecoder(req.Body).Decode(&topicsBody)
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("{}"))}, nil
+				}
+				t.Errorf("unexpected request: %s %s", req.Method, req.URL)
+				return nil, errors.New("unexpected request")
+			},
+		},
+		FetchSecretFunc: mockFetchSecretFunc,
+	}
+
+	_, err := client.CreateGitRepository(RepoConfig{
+		Name:        "test-repo",
+		TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+		Topics:      []string{"go", "microservice"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got := topicsBody["names"]; len(got) != 2 || got[0] != "go" || got[1] != "microservice" {
+		t.Errorf("expected topics [go microservice], got %v", got)
+	}
+}
+
+func TestCreateGitRepository_MergeOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		mergeOptions MergeConfig
+		expected     map[string]interface{}
+	}{
+		{
+			name:         "Squash Only",
+			mergeOptions: MergeConfig{AllowSquash: true},
+			expected: map[string]interface{}{
+				"allow_squash_merge": true,
+				"allow_merge_commit": false,
+				"allow_rebase_merge": false,
+			},
+		},
+		{
+			name:     "GitHub Defaults",
+			expected: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var createBody, patchBody map[string]interface{}
+			client := &GitClient{
+				HTTPClient: &mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						switch req.Method {
+						case http.MethodGet:
+							return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+						case http.MethodPatch:
+							json.NewDecoder(req.Body).Decode(&patchBody)
+							return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("{}"))}, nil
+						}
+						json.NewDecoder(req.Body).Decode(&createBody)
+						return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+					},
+				},
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+
+			config := RepoConfig{
+				Name:         "test-repo",
+				TemplateURL:  "https://api.github.com/repos/template-owner/template-repo/generate",
+				MergeOptions: tt.mergeOptions,
+			}
+			if _, err := client.CreateGitRepository(config); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			for _, field := range []string{"allow_squash_merge", "allow_merge_commit", "allow_rebase_merge"} {
+				expected, ok := tt.expected[field]
+				got, sent := patchBody[field]
+				if ok != sent || got != expected {
+					t.Errorf("expected %s %v (sent: %v), got %v (sent: %v)", field, expected, ok, got, sent)
+				}
+				if _, found := createBody[field]; found {
+					t.Errorf("expected %s to be left out of the generate request", field)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateGitRepository_SquashMerge(t *testing.T) {
+	tests := []struct {
+		name        string
+		squashMerge SquashMergeConfig
+		expected    map[string]string
+	}{
+		{
+			name:        "Title And Message",
+			squashMerge: SquashMergeConfig{CommitTitle: SquashMergeTitlePRTitle, CommitMessage: SquashMergeMessagePRBody},
+			expected:    map[string]string{"squash_merge_commit_title": "PR_TITLE", "squash_merge_commit_message": "PR_BODY"},
+		},
+		{
+			name:        "Title Only",
+			squashMerge: SquashMergeConfig{CommitTitle: SquashMergeTitleCommitOrPRTitle},
+			expected:    map[string]string{"squash_merge_commit_title": "COMMIT_OR_PR_TITLE"},
+		},
+		{
+			name:     "GitHub Defaults",
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var createBody, patchBody map[string]interface{}
+			client := &GitClient{
+				HTTPClient: &mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						switch req.Method {
+						case http.MethodGet:
+							return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+						case http.MethodPatch:
+							json.NewDecoder(req.Body).Decode(&patchBody)
+							return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("{}"))}, nil
+						}
+						json.NewDecoder(req.Body).Decode(&createBody)
+						return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+					},
+				},
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+
+			config := RepoConfig{
+				Name:        "test-repo",
+				TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+				SquashMerge: tt.squashMerge,
+			}
+			if _, err := client.CreateGitRepository(config); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			for _, field := range []string{"squash_merge_commit_title", "squash_merge_commit_message"} {
+				expected, ok := tt.expected[field]
+				got, sent := patchBody[field]
+				if ok != sent || (sent && got != expected) {
+					t.Errorf("expected %s %q (sent: %v), got %v (sent: %v)", field, expected, ok, got, sent)
+				}
+				if _, found := createBody[field]; found {
+					t.Errorf("expected %s to be left out of the generate request", field)
+				}
+			}
+			if len(tt.expected) > 0 && patchBody["allow_squash_merge"] != true {
+				t.Errorf("expected allow_squash_merge true with the squash merge settings, got %v", patchBody["allow_squash_merge"])
+			}
+		})
+	}
+}
+
+func TestCreateGitRepository_Extra(t *testing.T) {
+	var createBody, patchBody map[string]interface{}
+	client := &GitClient{
+		HTTPClient: &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				switch req.Method {
+				case http.MethodGet:
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+				case http.MethodPatch:
+					json.NewDecoder(req.Body).Decode(&patchBody)
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("{}"))}, nil
+				}
+				json.NewDecoder(req.Body).Decode(&createBody)
+				return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+			},
+		},
+		FetchSecretFunc: mockFetchSecretFunc,
+	}
+
+	config := RepoConfig{
+		Name:        "test-repo",
+		Description: "test description",
+		TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+		Extra: map[string]string{
+			"homepage":    "https://example.com",
+			"has_wiki":    "false",
+			"description": "",
+		},
+	}
+	if _, err := client.CreateGitRepository(config); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"homepage":    "https://example.com",
+		"has_wiki":    false,
+		"description": "test description",
+	}
+	if !reflect.DeepEqual(patchBody, expected) {
+		t.Errorf("expected PATCH body %v, got %v", expected, patchBody)
+	}
+	if _, found := createBody["homepage"]; found {
+		t.Error("expected the extra settings to be left out of the generate request")
+	}
+}
+
+func TestCreateGitRepository_ExtraRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra map[string]string
+	}{
+		{name: "Unknown Key", extra: map[string]string{"team_id": "42"}},
+		{name: "Rename", extra: map[string]string{"name": "other-repo"}},
+		{name: "Default Branch", extra: map[string]string{"default_branch": "develop"}},
+		{name: "Private", extra: map[string]string{"private": "false"}},
+		{name: "Visibility", extra: map[string]string{"visibility": "public"}},
+		{name: "Invalid Boolean", extra: map[string]string{"has_wiki": "sometimes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &GitClient{
+				HTTPClient: &mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						t.Errorf("unexpected request: %s %s", req.Method, req.URL)
+						return nil, errors.New("unexpected request")
+					},
+				},
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+
+			_, err := client.CreateGitRepository(RepoConfig{
+				Name:        "test-repo",
+				TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+				Extra:       tt.extra,
+			})
+			if !errors.Is(err, ErrUnsupportedRepoSetting) {
+				t.Errorf("expected ErrUnsupportedRepoSetting, got: %v", err)
+			}
+		})
+	}
+}
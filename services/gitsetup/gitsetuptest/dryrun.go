@@ -0,0 +1,143 @@
+// Package gitsetuptest provides clients that stand in for GitHub and ECR so the
+// repository setup can run end to end without any real AWS or GitHub calls.
+package gitsetuptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsECR "github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/lep13/AutoBuildGo/services/ecr"
+	"github.com/lep13/AutoBuildGo/services/gitsetup"
+)
+
+// DryRunRegistryID is the account ID used in the fake ECR outputs.
+const DryRunRegistryID = "000000000000"
+
+// dryRunRegion is the region used in the fake ECR repository URIs and ARNs.
+const dryRunRegion = "us-east-1"
+
+var (
+	_ gitsetup.HTTPClient    = DryRunHTTPClient{}
+	_ ecr.ECRClientInterface = DryRunECRClient{}
+)
+
+// DryRunHTTPClient answers every request with 201 Created and an empty body without sending it.
+type DryRunHTTPClient struct{}
+
+func (DryRunHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusCreated,
+		Status:     "201 Created",
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(nil)),
+		Request:    req,
+	}, nil
+}
+
+// DryRunECRClient implements ecr.ECRClientInterface with fake outputs and never calls AWS.
+type DryRunECRClient struct{}
+
+func (DryRunECRClient) CreateRepository(ctx context.Context, params *awsECR.CreateRepositoryInput, optFns ...func(*awsECR.Options)) (*awsECR.CreateRepositoryOutput, error) {
+	return &awsECR.CreateRepositoryOutput{
+		Repository: dryRunRepository(aws.ToString(params.RepositoryName)),
+	}, nil
+}
+
+func (DryRunECRClient) GetAuthorizationToken(ctx context.Context, params *awsECR.GetAuthorizationTokenInput, optFns ...func(*awsECR.Options)) (*awsECR.GetAuthorizationTokenOutput, error) {
+	return &awsECR.GetAuthorizationTokenOutput{
+		AuthorizationData: []types.AuthorizationData{{
+			AuthorizationToken: aws.String(base64.StdEncoding.EncodeToString([]byte("AWS:dry-run"))),
+			ProxyEndpoint:      aws.String(fmt.Sprintf("https://%s.dkr.ecr.%s.amazonaws.com", DryRunRegistryID, dryRunRegion)),
+			ExpiresAt:          aws.Time(time.Now().Add(12 * time.Hour)),
+		}},
+	}, nil
+}
+
+func (DryRunECRClient) DeleteRepository(ctx context.Context, params *awsECR.DeleteRepositoryInput, optFns ...func(*awsECR.Options)) (*awsECR.DeleteRepositoryOutput, error) {
+	return &awsECR.DeleteRepositoryOutput{
+		Repository: dryRunRepository(aws.ToString(params.RepositoryName)),
+	}, nil
+}
+
+func (DryRunECRClient) DescribeRepositories(ctx context.Context, params *awsECR.DescribeRepositoriesInput, optFns ...func(*awsECR.Options)) (*awsECR.DescribeRepositoriesOutput, error) {
+	output := &awsECR.DescribeRepositoriesOutput{}
+	for _, name := range params.RepositoryNames {
+		output.Repositories = append(output.Repositories, *dryRunRepository(name))
+	}
+	return output, nil
+}
+
+func (DryRunECRClient) ListImages(ctx context.Context, params *awsECR.ListImagesInput, optFns ...func(*awsECR.Options)) (*awsECR.ListImagesOutput, error) {
+	return &awsECR.ListImagesOutput{}, nil
+}
+
+func (DryRunECRClient) BatchDeleteImage(ctx context.Context, params *awsECR.BatchDeleteImageInput, optFns ...func(*awsECR.Options)) (*awsECR.BatchDeleteImageOutput, error) {
+	return &awsECR.BatchDeleteImageOutput{ImageIds: params.ImageIds}, nil
+}
+
+// GetRepositoryPolicy reports that the repository has no policy.
+func (DryRunECRClient) GetRepositoryPolicy(ctx context.Context, params *awsECR.GetRepositoryPolicyInput, optFns ...func(*awsECR.Options)) (*awsECR.GetRepositoryPolicyOutput, error) {
+	return nil, &types.RepositoryPolicyNotFoundException{Message: aws.String("dry run repositories have no policy")}
+}
+
+func (DryRunECRClient) SetRepositoryPolicy(ctx context.Context, params *awsECR.SetRepositoryPolicyInput, optFns ...func(*awsECR.Options)) (*awsECR.SetRepositoryPolicyOutput, error) {
+	return &awsECR.SetRepositoryPolicyOutput{
+		RepositoryName: params.RepositoryName,
+		RegistryId:     aws.String(DryRunRegistryID),
+		PolicyText:     params.PolicyText,
+	}, nil
+}
+
+func (DryRunECRClient) StartImageScan(ctx context.Context, params *awsECR.StartImageScanInput, optFns ...func(*awsECR.Options)) (*awsECR.StartImageScanOutput, error) {
+	return &awsECR.StartImageScanOutput{
+		RepositoryName:  params.RepositoryName,
+		RegistryId:      aws.String(DryRunRegistryID),
+		ImageId:         params.ImageId,
+		ImageScanStatus: &types.ImageScanStatus{Status: types.ScanStatusInProgress},
+	}, nil
+}
+
+// DescribeImageScanFindings reports a completed scan without findings.
+func (DryRunECRClient) DescribeImageScanFindings(ctx context.Context, params *awsECR.DescribeImageScanFindingsInput, optFns ...func(*awsECR.Options)) (*awsECR.DescribeImageScanFindingsOutput, error) {
+	return &awsECR.DescribeImageScanFindingsOutput{
+		RepositoryName:    params.RepositoryName,
+		RegistryId:        aws.String(DryRunRegistryID),
+		ImageId:           params.ImageId,
+		ImageScanStatus:   &types.ImageScanStatus{Status: types.ScanStatusComplete},
+		ImageScanFindings: &types.ImageScanFindings{FindingSeverityCounts: map[string]int32{}},
+	}, nil
+}
+
+func (DryRunECRClient) PutImageTagMutability(ctx context.Context, params *awsECR.PutImageTagMutabilityInput, optFns ...func(*awsECR.Options)) (*awsECR.PutImageTagMutabilityOutput, error) {
+	return &awsECR.PutImageTagMutabilityOutput{
+		RepositoryName:     params.RepositoryName,
+		RegistryId:         aws.String(DryRunRegistryID),
+		ImageTagMutability: params.ImageTagMutability,
+	}, nil
+}
+
+// dryRunRepository returns a fake repository named name in the dry run registry.
+func dryRunRepository(name string) *types.Repository {
+	return &types.Repository{
+		RepositoryName: aws.String(name),
+		RegistryId:     aws.String(DryRunRegistryID),
+		RepositoryArn:  aws.String(fmt.Sprintf("arn:aws:ecr:%s:%s:repository/%s", dryRunRegion, DryRunRegistryID, name)),
+		RepositoryUri:  aws.String(fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com/%s", DryRunRegistryID, dryRunRegion, name)),
+	}
+}
+
+func (DryRunECRClient) PutLifecyclePolicy(ctx context.Context, params *awsECR.PutLifecyclePolicyInput, optFns ...func(*awsECR.Options)) (*awsECR.PutLifecyclePolicyOutput, error) {
+	return &awsECR.PutLifecyclePolicyOutput{
+		RepositoryName:      params.RepositoryName,
+		RegistryId:          aws.String(DryRunRegistryID),
+		LifecyclePolicyText: params.LifecyclePolicyText,
+	}, nil
+}
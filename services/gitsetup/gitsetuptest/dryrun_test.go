@@ -0,0 +1,50 @@
+package gitsetuptest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lep13/AutoBuildGo/services/ecr"
+	"github.com/lep13/AutoBuildGo/services/gitsetup"
+)
+
+func TestDryRunHTTPClient(t *testing.T) {
+	client := &gitsetup.GitClient{
+		HTTPClient:      DryRunHTTPClient{},
+		FetchSecretFunc: func() (string, error) { return "dry-run-token", nil },
+	}
+
+	_, err := client.CreateGitRepository(gitsetup.RepoConfig{
+		Name:        "test-repo",
+		TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+	})
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestDryRunECRClient(t *testing.T) {
+	client := DryRunECRClient{}
+
+	output, err := ecr.CreateRepo("test-repo", client)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if uri := *output.Repository.RepositoryUri; uri != "000000000000.dkr.ecr.us-east-1.amazonaws.com/test-repo" {
+		t.Errorf("unexpected repository URI: %s", uri)
+	}
+
+	exists, err := ecr.RepoExists("test-repo", client)
+	if err != nil || !exists {
+		t.Errorf("expected the repository to exist, got exists: %v, err: %v", exists, err)
+	}
+
+	if err := ecr.DeleteRepoWithOptions("test-repo", ecr.DeleteRepoOptions{DrainFirst: true}, client); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+
+	token, err := ecr.GetAuthorizationToken(context.Background(), client)
+	if err != nil || token.Username != "AWS" {
+		t.Errorf("expected a fake token for AWS, got: %+v, err: %v", token, err)
+	}
+}
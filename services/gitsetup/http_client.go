@@ -0,0 +1,57 @@
+package gitsetup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultHTTPTimeout is the timeout applied to GitHub API requests made by NewGitClient.
+const DefaultHTTPTimeout = 30 * time.Second
+
+// TimeoutHTTPClient is an HTTPClient that bounds every request with a timeout.
+type TimeoutHTTPClient struct {
+	Client  HTTPClient
+	Timeout time.Duration
+}
+
+// NewTimeoutHTTPClient wraps client so that every request is cancelled after timeout.
+func NewTimeoutHTTPClient(client HTTPClient, timeout time.Duration) *TimeoutHTTPClient {
+	return &TimeoutHTTPClient{
+		Client:  client,
+		Timeout: timeout,
+	}
+}
+
+// Do sends the request with a context that expires after the configured timeout.
+// The context stays alive until the response body is closed.
+func (c *TimeoutHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), c.Timeout)
+
+	resp, err := c.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		// Only the timeout of c is reported as ErrRequestTimeout, not a deadline set by the caller.
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && req.Context().Err() == nil {
+			return nil, fmt.Errorf("%w: %s %s after %v", ErrRequestTimeout, req.Method, req.URL.Redacted(), c.Timeout)
+		}
+		return nil, err
+	}
+
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnCloseBody releases the request context once the response body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
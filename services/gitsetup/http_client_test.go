@@ -0,0 +1,99 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutHTTPClient(t *testing.T) {
+	t.Run("Request Completes", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		client := NewTimeoutHTTPClient(&http.Client{}, time.Second)
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		defer resp.Body.Close()
+
+		// The body must still be readable after Do returns
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read response body: %v", err)
+		}
+		if string(body) != "ok" {
+			t.Errorf("expected body %q, got %q", "ok", string(body))
+		}
+	})
+
+	t.Run("Request Times Out", func(t *testing.T) {
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+		}))
+		defer server.Close()
+		defer close(done)
+
+		client := NewTimeoutHTTPClient(&http.Client{}, 10*time.Millisecond)
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+		_, err := client.Do(req)
+		if !errors.Is(err, ErrRequestTimeout) {
+			t.Errorf("expected ErrRequestTimeout, got: %v", err)
+		}
+	})
+
+	t.Run("Caller Deadline Is Not A Timeout", func(t *testing.T) {
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+		}))
+		defer server.Close()
+		defer close(done)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		client := NewTimeoutHTTPClient(&http.Client{}, time.Hour)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+		_, err := client.Do(req)
+		if errors.Is(err, ErrRequestTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the caller's context.DeadlineExceeded, got: %v", err)
+		}
+	})
+
+	t.Run("Other Errors Are Returned Unchanged", func(t *testing.T) {
+		doErr := errors.New("HTTP Do error")
+		client := NewTimeoutHTTPClient(&mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				if _, ok := req.Context().Deadline(); !ok {
+					t.Errorf("expected the request context to have a deadline")
+				}
+				return nil, doErr
+			},
+		}, time.Second)
+		req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/user", bytes.NewBufferString(""))
+
+		_, err := client.Do(req)
+		if err != doErr {
+			t.Errorf("expected %v, got: %v", doErr, err)
+		}
+	})
+}
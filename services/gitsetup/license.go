@@ -0,0 +1,123 @@
+package gitsetup
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// licenseFile is the path the license of RepoConfig.License is committed to.
+const licenseFile = "LICENSE"
+
+// gitHubLicense is the part of a GET /licenses/{license} answer that is used.
+type gitHubLicense struct {
+	Body string `json:"body"`
+}
+
+// gitHubContent is the part of a GET /repos/{owner}/{repo}/contents/{path} answer that is used.
+type gitHubContent struct {
+	SHA string `json:"sha"`
+}
+
+// addLicense commits the text of the configured license to LICENSE on the default branch with
+// PUT /repos/{owner}/{repo}/contents/LICENSE, replacing a LICENSE copied from the template.
+// The [year] and [fullname] placeholders of the text are filled with the current year and the owner.
+func (w *RepoReadyWaiter) addLicense(ctx context.Context, token string) error {
+	if w.license == "" {
+		return nil
+	}
+
+	text, err := w.client.fetchLicenseText(ctx, token, w.license)
+	if err != nil {
+		return err
+	}
+	text = strings.NewReplacer("[year]", strconv.Itoa(timeNow().Year()), "[fullname]", w.owner).Replace(text)
+
+	sha, err := w.client.fileSHA(ctx, token, w.owner, w.repo, licenseFile)
+	if err != nil {
+		return err
+	}
+	payload := map[string]any{
+		"message": "Add " + w.license + " license",
+		"content": base64.StdEncoding.EncodeToString([]byte(text)),
+	}
+	if sha != "" {
+		payload["sha"] = sha
+	}
+
+	resp, err := w.client.sendOwnerRepoRequest(ctx, token, http.MethodPut, w.owner, w.repo, "/contents/"+licenseFile, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+		return nil
+	}
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	return newGitHubError(resp.StatusCode, "failed to add license %s, status code: %d, response: %s", w.license, resp.StatusCode, string(body))
+}
+
+// fetchLicenseText returns the text of the license with the SPDX identifier spdx from GET /licenses/{license}.
+func (client *GitClient) fetchLicenseText(ctx context.Context, token, spdx string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.apiURL("/licenses/"+url.PathEscape(strings.ToLower(spdx))), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "token "+token)
+
+	resp, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", newGitHubError(resp.StatusCode, "failed to fetch license %s, status code: %d, response: %s", spdx, resp.StatusCode, string(body))
+	}
+
+	var license gitHubLicense
+	if err := json.Unmarshal(body, &license); err != nil {
+		return "", fmt.Errorf("failed to decode license %s: %v", spdx, err)
+	}
+	return license.Body, nil
+}
+
+// fileSHA returns the blob SHA of path in owner/repoName, or "" when the file does not exist.
+func (client *GitClient) fileSHA(ctx context.Context, token, owner, repoName, path string) (string, error) {
+	resp, err := client.sendOwnerRepoRequest(ctx, token, http.MethodGet, owner, repoName, "/contents/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", newGitHubError(resp.StatusCode, "failed to check %s, status code: %d, response: %s", path, resp.StatusCode, string(body))
+	}
+
+	var content gitHubContent
+	if err := json.Unmarshal(body, &content); err != nil {
+		return "", fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	return content.SHA, nil
+}
@@ -0,0 +1,90 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestRepoReadyWaiter_AddLicense(t *testing.T) {
+	fixedTime(t)
+
+	tests := []struct {
+		name           string
+		licenseStatus  int
+		existingStatus int
+		putStatus      int
+		expectedSHA    string
+		expectedPuts   int
+		expectedStatus int
+	}{
+		{name: "New File", licenseStatus: http.StatusOK, existingStatus: http.StatusNotFound, putStatus: http.StatusCreated, expectedPuts: 1},
+		{name: "Replaces Template License", licenseStatus: http.StatusOK, existingStatus: http.StatusOK, putStatus: http.StatusOK, expectedSHA: "abc123", expectedPuts: 1},
+		{name: "Unknown License", licenseStatus: http.StatusNotFound, expectedStatus: http.StatusNotFound},
+		{name: "Rejected", licenseStatus: http.StatusOK, existingStatus: http.StatusNotFound, putStatus: http.StatusConflict, expectedPuts: 1, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var puts []map[string]string
+			client := &GitClient{
+				HTTPClient: &mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						respond := func(status int, body string) (*http.Response, error) {
+							return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+						}
+						switch {
+						case req.URL.Path == "/repos/octocat/test-repo/git/refs/heads/main":
+							return respond(http.StatusOK, "{}")
+						case req.URL.Path == "/licenses/apache-2.0":
+							return respond(tt.licenseStatus, `{"key": "apache-2.0", "body": "Copyright [year] [fullname]\n"}`)
+						case req.Method == http.MethodGet && req.URL.Path == "/repos/octocat/test-repo/contents/LICENSE":
+							return respond(tt.existingStatus, `{"sha": "abc123"}`)
+						case req.Method == http.MethodPut && req.URL.Path == "/repos/octocat/test-repo/contents/LICENSE":
+							var body map[string]string
+							json.NewDecoder(req.Body).Decode(&body)
+							puts = append(puts, body)
+							return respond(tt.putStatus, "{}")
+						}
+						t.Errorf("unexpected request: %s %s", req.Method, req.URL)
+						return nil, errors.New("unexpected request")
+					},
+				},
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+			config := RepoConfig{Name: "test-repo", License: "Apache-2.0"}
+			waiter := client.newRepoReadyWaiter(config, RepoCreateResult{FullName: "octocat/test-repo", DefaultBranch: "main"})
+
+			err := waiter.WaitReady(context.Background())
+			if tt.expectedStatus != 0 {
+				var gitHubErr *GitHubError
+				if !errors.As(err, &gitHubErr) || gitHubErr.StatusCode != tt.expectedStatus {
+					t.Errorf("expected a %d GitHubError, got: %v", tt.expectedStatus, err)
+				}
+			} else if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(puts) != tt.expectedPuts {
+				t.Fatalf("expected %d PUT requests, got %d", tt.expectedPuts, len(puts))
+			}
+			if len(puts) == 0 {
+				return
+			}
+			if puts[0]["sha"] != tt.expectedSHA {
+				t.Errorf("expected sha %q, got %q", tt.expectedSHA, puts[0]["sha"])
+			}
+			content, _ := base64.StdEncoding.DecodeString(puts[0]["content"])
+			if string(content) != "Copyright 2024 octocat\n" {
+				t.Errorf("expected the filled in license text, got %q", content)
+			}
+			if puts[0]["message"] != "Add Apache-2.0 license" {
+				t.Errorf("expected the commit message to name the license, got %q", puts[0]["message"])
+			}
+		})
+	}
+}
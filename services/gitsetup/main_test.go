@@ -0,0 +1,33 @@
+package gitsetup
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+// Package variables are initialized before init runs, so this keeps init from creating the
+// Secrets Manager client and prefetching the secrets.
+var _ = func() bool {
+	initSecretsManager = false
+	return true
+}()
+
+// TestMain runs the tests with GOMAXPROCS set to 4, so the scheduling under the race detector is
+// alike across machines. It does not bound the t.Parallel tests: the -parallel flag does, and its
+// default is taken from GOMAXPROCS before TestMain runs. Tests that replace package-level mocks
+// therefore must not call t.Parallel.
+// The home directory is replaced by an empty one so a UserConfigFile on the machine is not read.
+func TestMain(m *testing.M) {
+	runtime.GOMAXPROCS(4)
+
+	home, err := os.MkdirTemp("", "autobuildgo-home")
+	if err != nil {
+		panic(err)
+	}
+	userHomeDir = func() (string, error) { return home, nil }
+
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
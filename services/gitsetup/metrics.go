@@ -0,0 +1,102 @@
+package gitsetup
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// MetricsNamespaceEnvVar names the CloudWatch namespace repository creation metrics are published to.
+// Metrics are not published when it is unset.
+const MetricsNamespaceEnvVar = "AUTOBUILD_METRICS_NAMESPACE"
+
+// Metric names published by CreateRepoHandler.
+const (
+	RepoCreationSuccessMetric = "RepoCreationSuccess"
+	RepoCreationFailureMetric = "RepoCreationFailure"
+)
+
+// CloudWatchAPI is the part of the CloudWatch client used by CloudWatchPublisher.
+type CloudWatchAPI interface {
+	PutMetricData(ctx context.Context, params *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error)
+}
+
+// CloudWatchPublisher publishes custom metrics to a CloudWatch namespace.
+type CloudWatchPublisher struct {
+	Client    CloudWatchAPI
+	Namespace string
+}
+
+// NewCloudWatchPublisher returns a CloudWatchPublisher for namespace using a client created from cfg.
+func NewCloudWatchPublisher(cfg aws.Config, namespace string) *CloudWatchPublisher {
+	return &CloudWatchPublisher{
+		Client:    cloudwatch.NewFromConfig(cfg),
+		Namespace: namespace,
+	}
+}
+
+// Publish sends a single data point for metricName with the current time as timestamp.
+func (p *CloudWatchPublisher) Publish(ctx context.Context, metricName string, value float64, unit types.StandardUnit) error {
+	_, err := p.Client.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+		Namespace: aws.String(p.Namespace),
+		MetricData: []types.MetricDatum{
+			{
+				MetricName: aws.String(metricName),
+				Value:      aws.Float64(value),
+				Unit:       unit,
+				Timestamp:  aws.Time(time.Now()),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish metric %s: %v", metricName, err)
+	}
+	return nil
+}
+
+// cloudWatchClient returns the CloudWatch client publishMetric uses. It is created on first use and
+// shared afterwards, so the AWS config is not loaded again for every metric.
+var cloudWatchClient = sync.OnceValues(loadCloudWatchClient)
+
+// loadCloudWatchClient creates a CloudWatch client from the default AWS config.
+func loadCloudWatchClient() (CloudWatchAPI, error) {
+	cfg, err := configLoader.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %v", err)
+	}
+	return cloudwatch.NewFromConfig(cfg), nil
+}
+
+// publishMetric publishes to the namespace named by AUTOBUILD_METRICS_NAMESPACE and does nothing when it is unset.
+func publishMetric(ctx context.Context, metricName string, value float64, unit types.StandardUnit) error {
+	namespace := os.Getenv(MetricsNamespaceEnvVar)
+	if namespace == "" {
+		return nil
+	}
+
+	client, err := cloudWatchClient()
+	if err != nil {
+		return err
+	}
+	publisher := &CloudWatchPublisher{Client: client, Namespace: namespace}
+	return publisher.Publish(ctx, metricName, value, unit)
+}
+
+// recordRepoCreation publishes the outcome of a repository creation through MetricsFunc.
+// Failing to publish never fails the request, so errors are only logged.
+func recordRepoCreation(ctx context.Context, succeeded bool) {
+	metricName := RepoCreationFailureMetric
+	if succeeded {
+		metricName = RepoCreationSuccessMetric
+	}
+	if err := MetricsFunc(ctx, metricName, 1, types.StandardUnitCount); err != nil {
+		log.Printf("Failed to publish %s metric: %v", metricName, err)
+	}
+}
@@ -0,0 +1,171 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+type mockCloudWatchClient struct {
+	input *cloudwatch.PutMetricDataInput
+	err   error
+}
+
+func (m *mockCloudWatchClient) PutMetricData(ctx context.Context, params *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error) {
+	m.input = params
+	return &cloudwatch.PutMetricDataOutput{}, m.err
+}
+
+func TestCloudWatchPublisher_Publish(t *testing.T) {
+	client := &mockCloudWatchClient{}
+	publisher := &CloudWatchPublisher{Client: client, Namespace: "AutoBuildGo"}
+
+	if err := publisher.Publish(context.Background(), RepoCreationSuccessMetric, 1, types.StandardUnitCount); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if aws.ToString(client.input.Namespace) != "AutoBuildGo" {
+		t.Errorf("expected namespace AutoBuildGo, got %q", aws.ToString(client.input.Namespace))
+	}
+	if len(client.input.MetricData) != 1 {
+		t.Fatalf("expected 1 datum, got %d", len(client.input.MetricData))
+	}
+	datum := client.input.MetricData[0]
+	if aws.ToString(datum.MetricName) != RepoCreationSuccessMetric {
+		t.Errorf("expected metric %s, got %q", RepoCreationSuccessMetric, aws.ToString(datum.MetricName))
+	}
+	if aws.ToFloat64(datum.Value) != 1 {
+		t.Errorf("expected value 1, got %v", aws.ToFloat64(datum.Value))
+	}
+	if datum.Unit != types.StandardUnitCount {
+		t.Errorf("expected unit Count, got %q", datum.Unit)
+	}
+	if datum.Timestamp == nil {
+		t.Error("expected a timestamp")
+	}
+}
+
+func TestCloudWatchPublisher_PublishError(t *testing.T) {
+	publisher := &CloudWatchPublisher{Client: &mockCloudWatchClient{err: errors.New("throttled")}, Namespace: "AutoBuildGo"}
+
+	err := publisher.Publish(context.Background(), RepoCreationFailureMetric, 1, types.StandardUnitCount)
+	if err == nil || !strings.Contains(err.Error(), "failed to publish metric RepoCreationFailure: throttled") {
+		t.Errorf("expected publish error, got %v", err)
+	}
+}
+
+func TestPublishMetric_NoNamespace(t *testing.T) {
+	t.Setenv(MetricsNamespaceEnvVar, "")
+
+	if err := publishMetric(context.Background(), RepoCreationSuccessMetric, 1, types.StandardUnitCount); err != nil {
+		t.Errorf("expected no error without a namespace, got %v", err)
+	}
+}
+
+// countingConfigLoader counts the AWS configs it loads. Their clients fail every request without sending it.
+type countingConfigLoader struct {
+	loads int
+}
+
+func (c *countingConfigLoader) LoadDefaultConfig(ctx context.Context, options ...func(*config.LoadOptions) error) (aws.Config, error) {
+	c.loads++
+	return aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+		HTTPClient: &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("offline")
+		}},
+		RetryMaxAttempts: 1,
+	}, nil
+}
+
+func TestPublishMetric_SharedClient(t *testing.T) {
+	t.Setenv(MetricsNamespaceEnvVar, "AutoBuildGo")
+
+	originalLoader, originalClient := configLoader, cloudWatchClient
+	defer func() { configLoader, cloudWatchClient = originalLoader, originalClient }()
+	loader := &countingConfigLoader{}
+	configLoader = loader
+	cloudWatchClient = sync.OnceValues(loadCloudWatchClient)
+
+	for i := 0; i < 3; i++ {
+		if err := publishMetric(context.Background(), RepoCreationSuccessMetric, 1, types.StandardUnitCount); err == nil {
+			t.Errorf("call %d: expected the offline client to fail", i)
+		}
+	}
+	if loader.loads != 1 {
+		t.Errorf("expected the AWS config to be loaded once, got %d loads", loader.loads)
+	}
+}
+
+func TestCreateRepoHandler_Metrics(t *testing.T) {
+	seedSecretCache(t)
+
+	originalClock := Clock
+	originalMetrics := MetricsFunc
+	Clock = func(d time.Duration) {}
+	defer func() {
+		Clock = originalClock
+		MetricsFunc = originalMetrics
+	}()
+
+	tests := []struct {
+		name            string
+		body            string
+		cloneAndPush    func(context.Context, string, CloneConfig) error
+		expectedMetrics []string
+	}{
+		{
+			name:            "Success",
+			body:            `{"repo_name": "test-repo"}`,
+			cloneAndPush:    mockCloneAndPushRepo,
+			expectedMetrics: []string{RepoCreationSuccessMetric},
+		},
+		{
+			name:            "Failure",
+			body:            `{"repo_name": "test-repo"}`,
+			cloneAndPush:    mockCloneAndPushRepoError,
+			expectedMetrics: []string{RepoCreationFailureMetric},
+		},
+		{
+			name:         "Invalid Request",
+			body:         `{"repo_name": ""}`,
+			cloneAndPush: mockCloneAndPushRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			CreateECRClientFunc = mockCreateECRClient
+			CreateRepoFunc = mockCreateRepo
+			NewGitClientFunc = mockNewGitClient
+			CloneAndPushRepoFunc = tt.cloneAndPush
+
+			var metrics []string
+			MetricsFunc = func(ctx context.Context, metricName string, value float64, unit types.StandardUnit) error {
+				metrics = append(metrics, metricName)
+				return errors.New("publishing failures are only logged")
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/create-repo", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			CreateRepoHandler(httptest.NewRecorder(), req)
+
+			if strings.Join(metrics, ",") != strings.Join(tt.expectedMetrics, ",") {
+				t.Errorf("expected metrics %v, got %v", tt.expectedMetrics, metrics)
+			}
+		})
+	}
+}
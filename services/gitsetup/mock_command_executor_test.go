@@ -0,0 +1,72 @@
+package gitsetup
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// CommandCall is a command expected by MockCommandExecutor and the error returned for it.
+type CommandCall struct {
+	Name      string
+	Args      []string
+	Env       []string // set for commands run with RunWithEnv
+	ReturnErr error
+}
+
+func (c CommandCall) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// MockCommandExecutor is a CommandExecutor that records the commands it is asked to run.
+// When Commands is set, every call must match the next expected command; Verify reports any mismatch.
+type MockCommandExecutor struct {
+	Commands []CommandCall
+	Calls    []CommandCall
+	errors   []string
+}
+
+func (m *MockCommandExecutor) Run(name string, args ...string) error {
+	return m.RunWithEnv(name, nil, args...)
+}
+
+func (m *MockCommandExecutor) RunWithEnv(name string, env []string, args ...string) error {
+	call := CommandCall{Name: name, Args: args, Env: env}
+	m.Calls = append(m.Calls, call)
+	if m.Commands == nil {
+		return nil
+	}
+
+	i := len(m.Calls) - 1
+	if i >= len(m.Commands) {
+		m.errors = append(m.errors, fmt.Sprintf("unexpected command %q", call))
+		return fmt.Errorf("unexpected command %q", call)
+	}
+	expected := m.Commands[i]
+	if call.String() != expected.String() {
+		m.errors = append(m.errors, fmt.Sprintf("command %d: expected %q, got %q", i, expected, call))
+	}
+	return expected.ReturnErr
+}
+
+// CommandLines returns the commands run so far, one "name args..." string per command.
+func (m *MockCommandExecutor) CommandLines() []string {
+	lines := make([]string, len(m.Calls))
+	for i, call := range m.Calls {
+		lines[i] = call.String()
+	}
+	return lines
+}
+
+// Verify fails t if a command did not match or an expected command was not run.
+func (m *MockCommandExecutor) Verify(t *testing.T) {
+	t.Helper()
+	for _, err := range m.errors {
+		t.Error(err)
+	}
+	if m.Commands != nil && len(m.Calls) < len(m.Commands) {
+		for _, missing := range m.Commands[len(m.Calls):] {
+			t.Errorf("expected command %q was not run", missing)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package gitsetup
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"time"
 )
 
 type RepoConfig struct {
@@ -10,14 +13,101 @@ type RepoConfig struct {
 	Private     bool
 	AutoInit    bool
 	TemplateURL string
+	Secrets     map[string]string // GitHub Actions secrets created after the repository is set up
+	// DefaultBranch is the name the default branch is renamed to by RepoCreateResult.Ready once the
+	// template contents are copied. Empty keeps the template's branch name.
+	DefaultBranch string
+	// AutoDeleteHeadBranches deletes the head branch of a pull request once it is merged (delete_branch_on_merge).
+	AutoDeleteHeadBranches bool
+	// MergeOptions controls which pull request merge methods are allowed. The zero value keeps GitHub's defaults.
+	MergeOptions MergeConfig
+	// SquashMerge controls the commit created by squash merges. Empty fields keep GitHub's defaults.
+	// Setting a field also allows squash merges, since GitHub rejects the settings otherwise.
+	SquashMerge SquashMergeConfig
+	// Topics replace the repository's topics after creation.
+	Topics []string
+	// Environments are the GitHub Actions deployment environments created after the repository.
+	Environments []EnvironmentConfig
+	// License is the SPDX identifier of the repository's license, e.g. MIT. The generate endpoint
+	// cannot add a license, so RepoCreateResult.Ready commits GitHub's text of it as LICENSE once the
+	// template contents are copied, replacing the template's LICENSE.
+	License string
+	// Extra holds GitHub repository settings without a field of their own, e.g. homepage or has_wiki.
+	// They are applied with PATCH /repos/{owner}/{repo} after creation, and the fields above win when
+	// both set the same key. Keys that endpoint does not accept fail with ErrUnsupportedRepoSetting.
+	Extra map[string]string
 }
 
+// RepoCreateResult holds the URLs of a repository created by CreateGitRepository.
+type RepoCreateResult struct {
+	HTMLURL       string `json:"html_url"`
+	CloneURL      string `json:"clone_url"`
+	SSHURL        string `json:"ssh_url"`
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+	// Ready waits until the default branch of the repository can be cloned and renames it to RepoConfig.DefaultBranch.
+	Ready *RepoReadyWaiter `json:"-"`
+}
+
+// MergeConfig selects the pull request merge methods allowed on a repository.
+type MergeConfig struct {
+	AllowSquash bool
+	AllowMerge  bool
+	AllowRebase bool
+}
+
+// Values accepted by GitHub for SquashMergeConfig.
+const (
+	SquashMergeTitlePRTitle         = "PR_TITLE"
+	SquashMergeTitleCommitOrPRTitle = "COMMIT_OR_PR_TITLE"
+
+	SquashMergeMessagePRBody         = "PR_BODY"
+	SquashMergeMessageCommitMessages = "COMMIT_MESSAGES"
+	SquashMergeMessageBlank          = "BLANK"
+)
+
+// SquashMergeConfig sets the title and message of the commit created by a squash merge.
+type SquashMergeConfig struct {
+	CommitTitle   string // squash_merge_commit_title
+	CommitMessage string // squash_merge_commit_message
+}
+
+// IsSet reports whether any merge method was selected.
+// GitHub requires at least one method to be allowed, so the zero value means "use the defaults".
+func (m MergeConfig) IsSet() bool {
+	return m.AllowSquash || m.AllowMerge || m.AllowRebase
+}
+
+// repoNamePattern matches the characters GitHub allows in a repository name.
+var repoNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+
+// ValidateRepoName checks that repoName is a valid GitHub repository name.
+func ValidateRepoName(repoName string) error {
+	if repoName == "" {
+		return ErrEmptyRepoName
+	}
+	if repoName == "." || repoName == ".." || !repoNamePattern.MatchString(repoName) {
+		return fmt.Errorf("invalid repo name %q: use up to 100 letters, digits, '.', '-' or '_'", repoName)
+	}
+	return nil
+}
+
+// DefaultRepoConfig returns the RepoConfig for a private repository created from the template
+// of the TEMPLATE_URL secret. UserConfig.RepoConfig uses the template of the user's config file instead.
 func DefaultRepoConfig(repoName string, description string) (RepoConfig, error) {
 	templateURL, err := FetchTemplateURL()
 	if err != nil {
 		return RepoConfig{}, fmt.Errorf("failed to fetch template URL: %v", err)
 	}
+	return newRepoConfig(repoName, description, templateURL)
+}
 
+// newRepoConfig returns the RepoConfig for a private repository created from templateURL,
+// after checking repoName with ValidateRepoName.
+func newRepoConfig(repoName, description, templateURL string) (RepoConfig, error) {
+	if err := ValidateRepoName(repoName); err != nil {
+		return RepoConfig{}, err
+	}
 	return RepoConfig{
 		Name:        repoName,
 		Description: description,
@@ -26,3 +116,74 @@ func DefaultRepoConfig(repoName string, description string) (RepoConfig, error)
 		TemplateURL: templateURL,
 	}, nil
 }
+
+// DefaultModulePathPattern is the module path used when CloneConfig does not set one.
+const DefaultModulePathPattern = "github.com/{{.Username}}/{{.RepoName}}"
+
+// DefaultInitialTag is the version tag DefaultCloneConfig puts on the first commit.
+const DefaultInitialTag = "v0.1.0"
+
+// CloneConfig controls how CloneAndPushRepo prepares the cloned repository.
+type CloneConfig struct {
+	// ModulePathPattern is a text/template rendered with ModulePathData,
+	// e.g. "github.com/{{.Username}}/{{.RepoName}}" or "go.mycompany.com/{{.RepoName}}".
+	ModulePathPattern string
+	// GitHubHost is the host the repository is cloned from and pushed to, e.g. a GitHub Enterprise
+	// host like UserConfig.GitHubHost. Empty uses github.com.
+	GitHubHost string
+	// Branch is checked out with git clone --branch. Empty clones the default branch.
+	Branch string
+	// PushBranch pushes the changes with git push origin HEAD:<PushBranch>. Empty pushes to the tracking branch.
+	PushBranch string
+	// InitialTag is created and pushed after the module path update so the module can be
+	// imported by version. It is deleted from GitHub again when the branch cannot be pushed. Empty skips tagging.
+	InitialTag string
+	// GPGKeyID signs the commit with this key by setting user.signingkey and commit.gpgsign in the
+	// cloned repository. The key must already be in the GPG keyring. Empty leaves the commit unsigned.
+	GPGKeyID string
+	// RecurseSubmodules clones with --recurse-submodules and runs git submodule update --init --recursive.
+	// Imports are then also rewritten inside the submodules; those changes are committed in each
+	// submodule and pushed with git push --recurse-submodules=on-demand, which needs push access to them.
+	RecurseSubmodules bool
+	// VerifyBuild runs go build ./... after the module path update and fails before anything is
+	// committed when the module does not compile, e.g. because of a missed import.
+	VerifyBuild bool
+	// SkipGoMod does not run go mod init for repositories without a go.mod, and skips the go directive of
+	// .autobuild.yaml for them. Their README, extra files and InitialTag are still committed and pushed.
+	SkipGoMod bool
+	// ReadmeTemplate is a text/template rendered with ReadmeData and written to README.md, which is
+	// added to the commit. Empty leaves the template's README.md as it is.
+	ReadmeTemplate string
+	// ECRRepositoryURI is the URI of the repository's ECR repository, available to ReadmeTemplate as .ECRUri.
+	ECRRepositoryURI string
+	// GoEnv is added to the environment of the go commands, e.g. GOFLAGS=-mod=mod or GONOSUMDB for
+	// private modules. It is ignored by executors that do not implement CommandExecutorWithEnv.
+	GoEnv []string
+	// Executor runs the git and go commands. Nil runs them with os/exec. The GitHub token is passed to
+	// git through the environment, so executors that do not implement CommandExecutorWithEnv run unauthenticated.
+	Executor CommandExecutor
+}
+
+// ModulePathData is the data available to CloneConfig.ModulePathPattern.
+type ModulePathData struct {
+	Username string
+	RepoName string
+}
+
+// ReadmeData is the data available to CloneConfig.ReadmeTemplate.
+type ReadmeData struct {
+	RepoName  string
+	Owner     string
+	ECRUri    string
+	CreatedAt time.Time
+}
+
+// DefaultCloneConfig returns the CloneConfig used by CloneAndPushRepo. The defaults can be changed
+// at runtime with PATCH /update-clone-config.
+func DefaultCloneConfig() CloneConfig {
+	defaultCloneConfig.RLock()
+	defer defaultCloneConfig.RUnlock()
+	config := defaultCloneConfig.config
+	config.GoEnv = slices.Clone(config.GoEnv)
+	return config
+}
@@ -0,0 +1,50 @@
+package gitsetup
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateRepoName(t *testing.T) {
+	tests := []struct {
+		name        string
+		repoName    string
+		expectedErr bool
+	}{
+		{name: "Valid Name", repoName: "test-repo"},
+		{name: "Dots And Underscores", repoName: "Test_Repo.v2"},
+		{name: "Empty Name", repoName: "", expectedErr: true},
+		{name: "Spaces", repoName: "test repo", expectedErr: true},
+		{name: "Slash", repoName: "owner/repo", expectedErr: true},
+		{name: "Dot", repoName: ".", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateRepoName(tt.repoName); (err != nil) != tt.expectedErr {
+				t.Errorf("expected error: %v, got: %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestDefaultRepoConfig_EmptyName(t *testing.T) {
+	seedSecretCache(t)
+
+	_, err := DefaultRepoConfig("", "")
+	if !errors.Is(err, ErrEmptyRepoName) || err.Error() != "repo name must not be empty" {
+		t.Errorf("expected error %q, got: %v", "repo name must not be empty", err)
+	}
+}
+
+func TestDefaultRepoConfig(t *testing.T) {
+	seedSecretCache(t)
+
+	config, err := DefaultRepoConfig("test-repo", "test description")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if config.Name != "test-repo" || !config.Private {
+		t.Errorf("unexpected config: %+v", config)
+	}
+}
@@ -0,0 +1,40 @@
+package gitsetup
+
+import "log/slog"
+
+// OperationLogger records what the handlers do, so the audit trail can be asserted in tests.
+// Fields are alternating keys and values, as with slog.
+type OperationLogger interface {
+	Info(msg string, fields ...any)
+	Error(msg string, err error, fields ...any)
+}
+
+// HandlerLogger is used by CreateRepoHandler and can be replaced, e.g. by NopOperationLogger in tests.
+var HandlerLogger OperationLogger = SlogOperationLogger{}
+
+// SlogOperationLogger writes to Logger, or to slog.Default() when Logger is nil.
+type SlogOperationLogger struct {
+	Logger *slog.Logger
+}
+
+func (l SlogOperationLogger) Info(msg string, fields ...any) {
+	l.logger().Info(msg, fields...)
+}
+
+// Error logs msg with err added as the "error" field.
+func (l SlogOperationLogger) Error(msg string, err error, fields ...any) {
+	l.logger().Error(msg, append([]any{"error", err}, fields...)...)
+}
+
+func (l SlogOperationLogger) logger() *slog.Logger {
+	if l.Logger == nil {
+		return slog.Default()
+	}
+	return l.Logger
+}
+
+// NopOperationLogger discards everything.
+type NopOperationLogger struct{}
+
+func (NopOperationLogger) Info(msg string, fields ...any)             {}
+func (NopOperationLogger) Error(msg string, err error, fields ...any) {}
@@ -0,0 +1,99 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingLogger is an OperationLogger that keeps every entry as "level msg".
+type recordingLogger struct {
+	entries []string
+	errs    []error
+}
+
+func (l *recordingLogger) Info(msg string, fields ...any) {
+	l.entries = append(l.entries, "INFO "+msg)
+}
+
+func (l *recordingLogger) Error(msg string, err error, fields ...any) {
+	l.entries = append(l.entries, "ERROR "+msg)
+	l.errs = append(l.errs, err)
+}
+
+// useHandlerLogger replaces HandlerLogger for the duration of the test.
+func useHandlerLogger(t *testing.T, logger OperationLogger) {
+	original := HandlerLogger
+	HandlerLogger = logger
+	t.Cleanup(func() { HandlerLogger = original })
+}
+
+func TestSlogOperationLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := SlogOperationLogger{Logger: slog.New(slog.NewTextHandler(&buf, nil))}
+
+	logger.Info("Git repository created", "repo", "test-repo")
+	logger.Error("Failed to prefetch secrets", errors.New("access denied"), "repo", "test-repo")
+
+	output := buf.String()
+	for _, expected := range []string{
+		`level=INFO msg="Git repository created" repo=test-repo`,
+		`level=ERROR msg="Failed to prefetch secrets" error="access denied" repo=test-repo`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q in the log, got:\n%s", expected, output)
+		}
+	}
+
+	// A zero SlogOperationLogger and NopOperationLogger must not panic
+	SlogOperationLogger{}.Info("zero value")
+	NopOperationLogger{}.Info("discarded")
+	NopOperationLogger{}.Error("discarded", errors.New("discarded"))
+}
+
+func TestCreateRepoHandler_OperationLogger(t *testing.T) {
+	seedSecretCache(t)
+
+	originalClock, originalPrefetch := Clock, PrefetchSecretsFunc
+	Clock = func(d time.Duration) {}
+	PrefetchSecretsFunc = func(ctx context.Context, keys ...string) error { return errors.New("prefetch failed") }
+	defer func() { Clock, PrefetchSecretsFunc = originalClock, originalPrefetch }()
+
+	CreateECRClientFunc = mockCreateECRClient
+	CreateRepoFunc = mockCreateRepo
+	NewGitClientFunc = mockNewGitClient
+	CloneAndPushRepoFunc = mockCloneAndPushRepo
+
+	logger := &recordingLogger{}
+	useHandlerLogger(t, logger)
+
+	body, _ := json.Marshal(RepoRequest{RepoName: "test-repo"})
+	req := httptest.NewRequest(http.MethodPost, "/create-repo", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	CreateRepoHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	expected := []string{
+		"INFO CreateRepoHandler invoked",
+		"ERROR Failed to prefetch secrets",
+		"INFO Git repository created",
+		"INFO ECR and Git repositories created",
+	}
+	if !slices.Equal(logger.entries, expected) {
+		t.Errorf("expected log entries %q, got %q", expected, logger.entries)
+	}
+	if len(logger.errs) != 1 || logger.errs[0].Error() != "prefetch failed" {
+		t.Errorf("expected the prefetch error to be logged, got %v", logger.errs)
+	}
+}
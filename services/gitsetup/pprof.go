@@ -0,0 +1,137 @@
+package gitsetup
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PprofEnvVar enables the /debug/pprof/ endpoints when set to true.
+const PprofEnvVar = "AUTOBUILD_PPROF"
+
+// defaultProfileSeconds is how long /debug/pprof/profile and /debug/pprof/trace record without ?seconds=.
+const defaultProfileSeconds = 30
+
+// pprofEnabled reports whether AUTOBUILD_PPROF enables the profiling endpoints.
+func pprofEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(PprofEnvVar))
+	return enabled
+}
+
+// registerPprofRoutes registers the profiling handlers under /debug/pprof/ on mux. They are built
+// from runtime/pprof rather than net/http/pprof, whose init registers unauthenticated handlers on
+// http.DefaultServeMux. They expose the memory of the process, so they require the
+// AUTOBUILD_ADMIN_TOKEN bearer token.
+func registerPprofRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", requireAdminToken(pprofIndexHandler))
+	mux.HandleFunc("/debug/pprof/profile", requireAdminToken(pprofCPUHandler))
+	mux.HandleFunc("/debug/pprof/trace", requireAdminToken(pprofTraceHandler))
+}
+
+// pprofIndexHandler lists the runtime profiles, or writes the one named after /debug/pprof/.
+// ?debug=1 writes it as text instead of the protobuf format read by go tool pprof.
+func pprofIndexHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/debug/pprof/")
+	if name == "" {
+		writePprofIndex(w)
+		return
+	}
+
+	profile := pprof.Lookup(name)
+	if profile == nil {
+		http.Error(w, "Unknown profile: "+name, http.StatusNotFound)
+		return
+	}
+	debug, _ := strconv.Atoi(r.URL.Query().Get("debug"))
+	if debug > 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+	if err := profile.WriteTo(w, debug); err != nil {
+		http.Error(w, "Failed to write profile: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writePprofIndex writes an HTML page linking every runtime profile and the CPU profile and trace.
+func writePprofIndex(w http.ResponseWriter) {
+	profiles := pprof.Profiles()
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name() < profiles[j].Name() })
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintln(w, "<html><head><title>/debug/pprof/</title></head><body><ul>")
+	for _, profile := range profiles {
+		name := html.EscapeString(profile.Name())
+		fmt.Fprintf(w, "<li><a href=\"%s?debug=1\">%s</a> (%d)</li>\n", name, name, profile.Count())
+	}
+	fmt.Fprintln(w, "<li><a href=\"profile\">profile</a></li>")
+	fmt.Fprintln(w, "<li><a href=\"trace?seconds=5\">trace</a></li>")
+	fmt.Fprintln(w, "</ul></body></html>")
+}
+
+// pprofCPUHandler records a CPU profile for ?seconds= (30 by default) and writes it.
+func pprofCPUHandler(w http.ResponseWriter, r *http.Request) {
+	duration, err := profileDuration(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	if err := pprof.StartCPUProfile(w); err != nil {
+		http.Error(w, "Could not enable CPU profiling: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	waitProfile(r, duration)
+	pprof.StopCPUProfile()
+}
+
+// pprofTraceHandler records an execution trace for ?seconds= (30 by default) and writes it.
+func pprofTraceHandler(w http.ResponseWriter, r *http.Request) {
+	duration, err := profileDuration(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+	if err := trace.Start(w); err != nil {
+		http.Error(w, "Could not enable tracing: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	waitProfile(r, duration)
+	trace.Stop()
+}
+
+// profileDuration parses ?seconds= on r, defaulting to defaultProfileSeconds.
+func profileDuration(r *http.Request) (time.Duration, error) {
+	value := r.URL.Query().Get("seconds")
+	if value == "" {
+		return defaultProfileSeconds * time.Second, nil
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("invalid seconds %q", value)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// waitProfile waits for duration, or until the client of r goes away.
+func waitProfile(r *http.Request, duration time.Duration) {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-r.Context().Done():
+	}
+}
@@ -0,0 +1,89 @@
+package gitsetup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// registerDefaultMux registers the routes on http.DefaultServeMux once, so that -count=N reruns
+// do not register them again.
+var registerDefaultMux sync.Once
+
+func TestRegisterRoutes_Pprof(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          string
+		adminToken     string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "Enabled", value: "true", adminToken: "s3cr3t", authorization: "Bearer s3cr3t", expectedStatus: http.StatusOK},
+		{name: "Missing Token", value: "true", adminToken: "s3cr3t", expectedStatus: http.StatusUnauthorized},
+		{name: "Wrong Token", value: "true", adminToken: "s3cr3t", authorization: "Bearer guess", expectedStatus: http.StatusUnauthorized},
+		{name: "No Admin Token Configured", value: "true", authorization: "Bearer ", expectedStatus: http.StatusUnauthorized},
+		{name: "Disabled", value: "", adminToken: "s3cr3t", authorization: "Bearer s3cr3t", expectedStatus: http.StatusNotFound},
+		{name: "Invalid", value: "yes please", adminToken: "s3cr3t", authorization: "Bearer s3cr3t", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PprofEnvVar, tt.value)
+			t.Setenv(AdminTokenEnvVar, tt.adminToken)
+			mux := RegisterRoutes(nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_PprofOnDefaultServeMux(t *testing.T) {
+	t.Setenv(PprofEnvVar, "true")
+	t.Setenv(AdminTokenEnvVar, "s3cr3t")
+	registerDefaultMux.Do(func() { RegisterRoutes(http.DefaultServeMux) })
+
+	tests := []struct {
+		name           string
+		path           string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "Index", path: "/debug/pprof/", authorization: "Bearer s3cr3t", expectedStatus: http.StatusOK},
+		{name: "Index Without Token", path: "/debug/pprof/", expectedStatus: http.StatusUnauthorized},
+		{name: "Named Profile", path: "/debug/pprof/goroutine?debug=1", authorization: "Bearer s3cr3t", expectedStatus: http.StatusOK},
+		{name: "Named Profile Without Token", path: "/debug/pprof/heap", expectedStatus: http.StatusUnauthorized},
+		{name: "Unknown Profile", path: "/debug/pprof/nothing", authorization: "Bearer s3cr3t", expectedStatus: http.StatusNotFound},
+		{name: "Cmdline Not Exposed", path: "/debug/pprof/cmdline", authorization: "Bearer s3cr3t", expectedStatus: http.StatusNotFound},
+		{name: "Invalid Seconds", path: "/debug/pprof/profile?seconds=soon", authorization: "Bearer s3cr3t", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			http.DefaultServeMux.ServeHTTP(w, req)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.name == "Named Profile" && !strings.Contains(w.Body.String(), "goroutine profile") {
+				t.Errorf("expected the goroutine profile, got %q", w.Body.String())
+			}
+		})
+	}
+}
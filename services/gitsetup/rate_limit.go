@@ -0,0 +1,98 @@
+package gitsetup
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxRateLimitWait is the longest NewGitClient waits in total for GitHub rate limits to reset.
+const DefaultMaxRateLimitWait = 60 * time.Second
+
+// RateLimitHTTPClient is an HTTPClient that retries requests rejected by a GitHub rate limit
+// once the limit resets. The wait is read from the Retry-After header, or from X-RateLimit-Reset
+// when X-RateLimit-Remaining is 0. A wait ends early with the context's error when the request's context is done.
+type RateLimitHTTPClient struct {
+	Client HTTPClient
+	// MaxWait bounds the total time spent waiting for one request. A response asking for a longer
+	// wait is returned as is.
+	MaxWait time.Duration
+}
+
+// NewRateLimitHTTPClient wraps client so that rate limited requests are retried for up to maxWait.
+func NewRateLimitHTTPClient(client HTTPClient, maxWait time.Duration) *RateLimitHTTPClient {
+	return &RateLimitHTTPClient{
+		Client:  client,
+		MaxWait: maxWait,
+	}
+}
+
+// Do sends the request, retrying it while GitHub answers 403 or 429 with a rate limit wait that fits in MaxWait.
+func (c *RateLimitHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	remaining := c.MaxWait
+	for {
+		resp, err := c.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		wait, limited := rateLimitWait(resp)
+		if !limited || wait > remaining {
+			return resp, nil
+		}
+
+		// The body has been sent, so it has to be recreated for the retry
+		retry := req
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			retry = req.Clone(req.Context())
+			retry.Body = body
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		remaining -= wait
+		req = retry
+	}
+}
+
+// rateLimitWait reports whether resp was rejected by a rate limit and how long to wait before retrying.
+// Waits are at least a second so a Retry-After of 0 cannot cause a busy loop.
+func rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	var wait time.Duration
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(value); err == nil {
+			wait = date.Sub(timeNow())
+		} else {
+			return 0, false
+		}
+	} else if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		// GitHub sends the rate limit headers on every response, so a 403 is only a rate limit when none are left
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		wait = time.Unix(reset, 0).Sub(timeNow())
+	} else {
+		return 0, false
+	}
+
+	return max(wait, time.Second), true
+}
@@ -0,0 +1,176 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// rateLimitedResponses answers with the given responses in order and records the request bodies.
+type rateLimitedResponses struct {
+	responses []*http.Response
+	bodies    []string
+}
+
+func (r *rateLimitedResponses) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+	}
+	r.bodies = append(r.bodies, string(body))
+
+	resp := r.responses[0]
+	r.responses = r.responses[1:]
+	return resp, nil
+}
+
+func rateLimitResponse(statusCode int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: statusCode, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString("rate limited"))}
+	for key, value := range headers {
+		resp.Header.Set(key, value)
+	}
+	return resp
+}
+
+func TestRateLimitHTTPClient(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	originalNow := timeNow
+	defer func() { timeNow = originalNow }()
+	timeNow = func() time.Time { return now }
+
+	reset := strconv.FormatInt(now.Add(30*time.Second).Unix(), 10)
+
+	tests := []struct {
+		name           string
+		responses      []*http.Response
+		expectedStatus int
+		expectedWaits  []time.Duration
+	}{
+		{
+			name:           "Not Rate Limited",
+			responses:      []*http.Response{rateLimitResponse(http.StatusCreated, nil)},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "Secondary Rate Limit Retry-After",
+			responses: []*http.Response{
+				rateLimitResponse(http.StatusForbidden, map[string]string{"Retry-After": "5"}),
+				rateLimitResponse(http.StatusCreated, nil),
+			},
+			expectedStatus: http.StatusCreated,
+			expectedWaits:  []time.Duration{5 * time.Second},
+		},
+		{
+			name: "Too Many Requests Retry-After Date",
+			responses: []*http.Response{
+				rateLimitResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": now.Add(10 * time.Second).Format(http.TimeFormat)}),
+				rateLimitResponse(http.StatusCreated, nil),
+			},
+			expectedStatus: http.StatusCreated,
+			expectedWaits:  []time.Duration{10 * time.Second},
+		},
+		{
+			name: "Primary Rate Limit Reset",
+			responses: []*http.Response{
+				rateLimitResponse(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}),
+				rateLimitResponse(http.StatusCreated, nil),
+			},
+			expectedStatus: http.StatusCreated,
+			expectedWaits:  []time.Duration{30 * time.Second},
+		},
+		{
+			name: "Retry-After Zero Waits A Second",
+			responses: []*http.Response{
+				rateLimitResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "0"}),
+				rateLimitResponse(http.StatusCreated, nil),
+			},
+			expectedStatus: http.StatusCreated,
+			expectedWaits:  []time.Duration{time.Second},
+		},
+		{
+			name: "Forbidden Without Rate Limit",
+			responses: []*http.Response{
+				rateLimitResponse(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "4999", "X-RateLimit-Reset": reset}),
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Wait Longer Than Max",
+			responses: []*http.Response{
+				rateLimitResponse(http.StatusForbidden, map[string]string{"Retry-After": "120"}),
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Max Wait Spent",
+			responses: []*http.Response{
+				rateLimitResponse(http.StatusForbidden, map[string]string{"Retry-After": "40"}),
+				rateLimitResponse(http.StatusForbidden, map[string]string{"Retry-After": "40"}),
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedWaits:  []time.Duration{40 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := useRecordedWaits(t)
+
+			responses := &rateLimitedResponses{responses: tt.responses}
+			client := NewRateLimitHTTPClient(responses, time.Minute)
+
+			req, _ := http.NewRequest(http.MethodPost, "https://api.github.com/repos/template-owner/template-repo/generate", bytes.NewBufferString(`{"name": "test-repo"}`))
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			waits := *recorded
+			if len(waits) != len(tt.expectedWaits) {
+				t.Fatalf("expected waits %v, got %v", tt.expectedWaits, waits)
+			}
+			for i := range waits {
+				if waits[i] != tt.expectedWaits[i] {
+					t.Errorf("expected waits %v, got %v", tt.expectedWaits, waits)
+				}
+			}
+			// Every attempt must send the full request body
+			for _, body := range responses.bodies {
+				if body != `{"name": "test-repo"}` {
+					t.Errorf("expected the request body to be resent, got %q", body)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitHTTPClient_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	originalAfter := timeAfter
+	t.Cleanup(func() { timeAfter = originalAfter })
+	timeAfter = func(time.Duration) <-chan time.Time {
+		cancel()
+		return make(chan time.Time)
+	}
+
+	responses := &rateLimitedResponses{responses: []*http.Response{
+		rateLimitResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "5"}),
+		rateLimitResponse(http.StatusCreated, nil),
+	}}
+	client := NewRateLimitHTTPClient(responses, time.Minute)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/user", nil)
+	if _, err := client.Do(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+	if len(responses.bodies) != 1 {
+		t.Errorf("expected no retry after the context is done, got %d requests", len(responses.bodies))
+	}
+}
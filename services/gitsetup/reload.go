@@ -0,0 +1,109 @@
+package gitsetup
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// serverSettings are the web server settings that can change without a restart, read from the
+// server section of the UserConfigFile and the environment. reloadConfig replaces them as a whole,
+// so a request never sees a mix of old and new settings.
+type serverSettings struct {
+	corsOrigins []string
+	rateLimits  rateLimits
+	// rateLimit is nil when no rate limit is configured. A reload keeps it, and the state of its
+	// limiters, unless the limits change.
+	rateLimit func(http.Handler) http.Handler
+}
+
+// currentSettings holds the settings used by the handler returned by serverHandler.
+var currentSettings atomic.Pointer[serverSettings]
+
+// reloadOnSIGHUP makes sure only one goroutine waits for SIGHUP, however often the server starts.
+var reloadOnSIGHUP sync.Once
+
+// newServerSettings returns the settings of userConfig, falling back to the environment for those
+// it does not set. The rate limiters of previous, which may be nil, are reused when the limits are unchanged.
+func newServerSettings(userConfig ServerUserConfig, previous *serverSettings) *serverSettings {
+	s := &serverSettings{
+		corsOrigins: userConfig.CORSOrigins,
+		rateLimits:  rateLimitsFromEnv(),
+	}
+	if len(s.corsOrigins) == 0 {
+		s.corsOrigins = corsOriginsFromEnv()
+	}
+	if userConfig.RateLimit > 0 {
+		s.rateLimits.global = userConfig.RateLimit
+	}
+	if userConfig.RateLimitPerIP > 0 {
+		s.rateLimits.perIP = userConfig.RateLimitPerIP
+	}
+
+	if previous != nil && previous.rateLimits == s.rateLimits {
+		s.rateLimit = previous.rateLimit
+	} else {
+		s.rateLimit = s.rateLimits.middleware()
+	}
+	return s
+}
+
+// settings returns the current settings, loading them on first use. An invalid UserConfigFile
+// is logged and only the environment is used.
+func settings() *serverSettings {
+	if s := currentSettings.Load(); s != nil {
+		return s
+	}
+	userConfig, err := LoadUserConfig()
+	if err != nil {
+		log.Printf("Ignoring the server settings of the user configuration: %v", err)
+	}
+	currentSettings.CompareAndSwap(nil, newServerSettings(userConfig.Server, nil))
+	return currentSettings.Load()
+}
+
+// wrap applies the middleware configured by s to handler.
+func (s *serverSettings) wrap(handler http.Handler) http.Handler {
+	// Rate limiting goes inside CORS so browsers can read the 429 responses
+	if s.rateLimit != nil {
+		handler = s.rateLimit(handler)
+	}
+	if len(s.corsOrigins) > 0 {
+		handler = CORSMiddleware(s.corsOrigins)(handler)
+	}
+	return handler
+}
+
+// reloadConfig re-reads the settings from the UserConfigFile, swapping them in at once, and empties
+// the secret cache and the token of the shared GitClient so a rotated GitHub token or template URL
+// is fetched on the next request. When the file is invalid the current settings are kept.
+func reloadConfig() {
+	if userConfig, err := LoadUserConfig(); err != nil {
+		log.Printf("Keeping the current server settings: %v", err)
+	} else {
+		currentSettings.Store(newServerSettings(userConfig.Server, currentSettings.Load()))
+	}
+	clearSecretCache()
+	serverGitClient().clearToken()
+	log.Println("Configuration reloaded")
+}
+
+// watchReloadSignals calls reloadConfig for every signal received until signals is closed.
+func watchReloadSignals(signals <-chan os.Signal) {
+	for range signals {
+		reloadConfig()
+	}
+}
+
+// startReloadOnSIGHUP reloads the configuration whenever the process receives SIGHUP.
+func startReloadOnSIGHUP() {
+	reloadOnSIGHUP.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP)
+		go watchReloadSignals(signals)
+	})
+}
@@ -0,0 +1,123 @@
+package gitsetup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// reloadSettings reloads the server settings and drops them once the test is done.
+func reloadSettings(t *testing.T) {
+	reloadConfig()
+	t.Cleanup(func() { currentSettings.Store(nil) })
+}
+
+// settingsRequester returns a function sending a request from a browser on https://ui.example.com
+// through the middleware of the current settings.
+func settingsRequester() func() *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	handler := serverHandler(mux)
+	return func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", "https://ui.example.com")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	t.Setenv(CORSOriginsEnvVar, "")
+	t.Setenv(RateLimitEnvVar, "")
+	t.Setenv(RateLimitPerIPEnvVar, "")
+	useHomeDir(t, "")
+	reloadSettings(t)
+	seedSecretCache(t)
+	request := settingsRequester()
+
+	if got := request().Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no CORS headers before the reload, got %q", got)
+	}
+
+	// A changed config file only applies once the configuration is reloaded
+	useHomeDir(t, "server:\n  cors_origins: [https://ui.example.com]\n  rate_limit: 1\n")
+	if got := request().Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no CORS headers until the reload, got %q", got)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signals <- syscall.SIGHUP
+	close(signals)
+	watchReloadSignals(signals)
+
+	if got := request().Header().Get("Access-Control-Allow-Origin"); got != "https://ui.example.com" {
+		t.Errorf("expected the reloaded CORS origin, got %q", got)
+	}
+	if w := request(); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the reloaded rate limit to reject the second request, got %d", w.Code)
+	}
+
+	secretCache.Lock()
+	cached := len(secretCache.data)
+	secretCache.Unlock()
+	if cached != 0 {
+		t.Errorf("expected the secret cache to be emptied, got %d entries", cached)
+	}
+}
+
+func TestReloadConfig_KeepsRateLimiters(t *testing.T) {
+	t.Setenv(CORSOriginsEnvVar, "")
+	t.Setenv(RateLimitEnvVar, "")
+	t.Setenv(RateLimitPerIPEnvVar, "")
+	useHomeDir(t, "server:\n  rate_limit_per_ip: 1\n")
+	reloadSettings(t)
+	request := settingsRequester()
+
+	if w := request(); w.Code != http.StatusOK {
+		t.Fatalf("expected the first request to pass, got %d", w.Code)
+	}
+	// Unchanged limits keep the limiters, so the client is still over its limit
+	reloadConfig()
+	if w := request(); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the request after the reload to be limited, got %d", w.Code)
+	}
+
+	// Changed limits start with new limiters
+	useHomeDir(t, "server:\n  rate_limit_per_ip: 2\n")
+	reloadConfig()
+	if w := request(); w.Code != http.StatusOK {
+		t.Errorf("expected the request after changing the limit to pass, got %d", w.Code)
+	}
+}
+
+func TestReloadConfig_InvalidFile(t *testing.T) {
+	t.Setenv(CORSOriginsEnvVar, "")
+	t.Setenv(RateLimitEnvVar, "")
+	t.Setenv(RateLimitPerIPEnvVar, "")
+	useHomeDir(t, "server:\n  cors_origins: [https://ui.example.com]\n")
+	reloadSettings(t)
+	request := settingsRequester()
+
+	useHomeDir(t, "server: [unclosed")
+	reloadConfig()
+	if got := request().Header().Get("Access-Control-Allow-Origin"); got != "https://ui.example.com" {
+		t.Errorf("expected an invalid file to keep the current CORS origin, got %q", got)
+	}
+}
+
+func TestNewServerSettings_EnvironmentFallback(t *testing.T) {
+	t.Setenv(CORSOriginsEnvVar, "https://env.example.com")
+	t.Setenv(RateLimitEnvVar, "5")
+	t.Setenv(RateLimitPerIPEnvVar, "1")
+
+	s := newServerSettings(ServerUserConfig{RateLimitPerIP: 2}, nil)
+	if len(s.corsOrigins) != 1 || s.corsOrigins[0] != "https://env.example.com" {
+		t.Errorf("expected the CORS origins of the environment, got %v", s.corsOrigins)
+	}
+	if expected := (rateLimits{global: 5, perIP: 2}); s.rateLimits != expected {
+		t.Errorf("expected limits %+v, got %+v", expected, s.rateLimits)
+	}
+}
@@ -0,0 +1,60 @@
+package gitsetup
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoBuildConfigFile is read from the root of the cloned repository by CloneAndPushRepo.
+const RepoBuildConfigFile = ".autobuild.yaml"
+
+// RepoBuildConfig holds the per-repository overrides a template can ship in .autobuild.yaml:
+//
+//	go_version: "1.22"
+//	extra_files:
+//	  - README.md
+//	commit_message: Set up the service module
+type RepoBuildConfig struct {
+	// GoVersion is set as the go directive of go.mod with go mod edit -go.
+	GoVersion string `yaml:"go_version"`
+	// ExtraFiles are added to the commit along with go.mod, e.g. files changed by a hook.
+	ExtraFiles []string `yaml:"extra_files"`
+	// CommitMessage replaces the default message of the module path commit.
+	CommitMessage string `yaml:"commit_message"`
+}
+
+// repoBuildConfigKeys are the keys RepoBuildConfig understands; others are reported as warnings.
+var repoBuildConfigKeys = []string{"go_version", "extra_files", "commit_message"}
+
+// loadRepoBuildConfig reads .autobuild.yaml from the current directory. A missing file gives the zero
+// RepoBuildConfig, and unknown keys are logged and ignored so templates can add settings ahead of this code.
+func loadRepoBuildConfig() (RepoBuildConfig, error) {
+	data, err := readFile(RepoBuildConfigFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return RepoBuildConfig{}, nil
+	}
+	if err != nil {
+		return RepoBuildConfig{}, fmt.Errorf("%w: %v", ErrReadingBuildConfig, err)
+	}
+
+	var keys map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return RepoBuildConfig{}, fmt.Errorf("%w: %v", ErrReadingBuildConfig, err)
+	}
+	for key := range keys {
+		if !slices.Contains(repoBuildConfigKeys, key) {
+			slog.Warn("ignoring unknown key", "file", RepoBuildConfigFile, "key", key)
+		}
+	}
+
+	var buildConfig RepoBuildConfig
+	if err := yaml.Unmarshal(data, &buildConfig); err != nil {
+		return RepoBuildConfig{}, fmt.Errorf("%w: %v", ErrReadingBuildConfig, err)
+	}
+	return buildConfig, nil
+}
@@ -0,0 +1,126 @@
+package gitsetup
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// maxTopics is the number of topics GitHub allows on a repository.
+const maxTopics = 20
+
+var (
+	// topicPattern matches the topics GitHub accepts.
+	topicPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+	// licensePattern matches SPDX license identifiers such as MIT or Apache-2.0.
+	licensePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+-]*$`)
+)
+
+// RepoConfigBuilder builds a RepoConfig with the same defaults as DefaultRepoConfig.
+type RepoConfigBuilder struct {
+	config RepoConfig
+}
+
+// NewRepoConfigBuilder returns a builder for a private, auto initialized repository named repoName.
+func NewRepoConfigBuilder(repoName string) *RepoConfigBuilder {
+	return &RepoConfigBuilder{config: RepoConfig{
+		Name:     repoName,
+		Private:  true,
+		AutoInit: true,
+	}}
+}
+
+// WithDescription sets the repository description.
+func (b *RepoConfigBuilder) WithDescription(s string) *RepoConfigBuilder {
+	b.config.Description = s
+	return b
+}
+
+// WithPrivate sets whether the repository is private.
+func (b *RepoConfigBuilder) WithPrivate(private bool) *RepoConfigBuilder {
+	b.config.Private = private
+	return b
+}
+
+// WithAutoInit sets whether the repository is created with an initial commit.
+func (b *RepoConfigBuilder) WithAutoInit(autoInit bool) *RepoConfigBuilder {
+	b.config.AutoInit = autoInit
+	return b
+}
+
+// WithTemplateURL sets the template generate endpoint. When it is not called, Build uses the
+// template URL from Secrets Manager like DefaultRepoConfig.
+func (b *RepoConfigBuilder) WithTemplateURL(url string) *RepoConfigBuilder {
+	b.config.TemplateURL = url
+	return b
+}
+
+// WithAutoDeleteHeadBranches sets whether head branches are deleted when their pull request is merged.
+func (b *RepoConfigBuilder) WithAutoDeleteHeadBranches(enabled bool) *RepoConfigBuilder {
+	b.config.AutoDeleteHeadBranches = enabled
+	return b
+}
+
+// WithTopics adds topics to the repository.
+func (b *RepoConfigBuilder) WithTopics(topics ...string) *RepoConfigBuilder {
+	b.config.Topics = append(b.config.Topics, topics...)
+	return b
+}
+
+// WithEnvironment adds a deployment environment that requires approval by reviewers.
+func (b *RepoConfigBuilder) WithEnvironment(name string, reviewers ...string) *RepoConfigBuilder {
+	b.config.Environments = append(b.config.Environments, EnvironmentConfig{Name: name, Reviewers: reviewers})
+	return b
+}
+
+// WithLicense sets the SPDX identifier of the repository's license, committed as LICENSE once the repository is ready.
+func (b *RepoConfigBuilder) WithLicense(spdx string) *RepoConfigBuilder {
+	b.config.License = spdx
+	return b
+}
+
+// Build validates the configuration and returns it.
+func (b *RepoConfigBuilder) Build() (RepoConfig, error) {
+	config := b.config
+	config.Topics = append([]string(nil), b.config.Topics...)
+	config.Environments = append([]EnvironmentConfig(nil), b.config.Environments...)
+
+	if err := ValidateRepoName(config.Name); err != nil {
+		return RepoConfig{}, err
+	}
+
+	if len(config.Topics) > maxTopics {
+		return RepoConfig{}, fmt.Errorf("too many topics: %d, GitHub allows at most %d", len(config.Topics), maxTopics)
+	}
+	for _, topic := range config.Topics {
+		if !topicPattern.MatchString(topic) {
+			return RepoConfig{}, fmt.Errorf("invalid topic %q: use up to 50 lowercase letters, digits or '-'", topic)
+		}
+	}
+
+	for _, env := range config.Environments {
+		if env.Name == "" {
+			return RepoConfig{}, errors.New("environment name must not be empty")
+		}
+		if len(env.Reviewers) > maxEnvironmentReviewers {
+			return RepoConfig{}, fmt.Errorf("too many reviewers for environment %s: %d, GitHub allows at most %d", env.Name, len(env.Reviewers), maxEnvironmentReviewers)
+		}
+	}
+
+	if config.License != "" && !licensePattern.MatchString(config.License) {
+		return RepoConfig{}, fmt.Errorf("invalid license %q: must be an SPDX identifier", config.License)
+	}
+
+	if config.TemplateURL == "" {
+		templateURL, err := FetchTemplateURL()
+		if err != nil {
+			return RepoConfig{}, fmt.Errorf("failed to fetch template URL: %v", err)
+		}
+		config.TemplateURL = templateURL
+	}
+	if err := ValidateTemplateURL(config.TemplateURL); err != nil {
+		return RepoConfig{}, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,112 @@
+package gitsetup
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRepoConfigBuilder(t *testing.T) {
+	config, err := NewRepoConfigBuilder("test-repo").
+		WithDescription("test description").
+		WithPrivate(false).
+		WithAutoInit(false).
+		WithTemplateURL("https://api.github.com/repos/template-owner/template-repo/generate").
+		WithAutoDeleteHeadBranches(true).
+		WithTopics("go", "microservice").
+		WithTopics("autobuild").
+		WithLicense("Apache-2.0").
+		WithEnvironment("production", "octocat", "my-org/release-managers").
+		Build()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	expected := RepoConfig{
+		Name:                   "test-repo",
+		Description:            "test description",
+		TemplateURL:            "https://api.github.com/repos/template-owner/template-repo/generate",
+		AutoDeleteHeadBranches: true,
+		Topics:                 []string{"go", "microservice", "autobuild"},
+		License:                "Apache-2.0",
+		Environments:           []EnvironmentConfig{{Name: "production", Reviewers: []string{"octocat", "my-org/release-managers"}}},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected %+v, got %+v", expected, config)
+	}
+}
+
+func TestRepoConfigBuilder_Defaults(t *testing.T) {
+	seedSecretCache(t)
+
+	config, err := NewRepoConfigBuilder("test-repo").Build()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !config.Private || !config.AutoInit {
+		t.Errorf("expected a private, auto initialized repository, got %+v", config)
+	}
+	if config.TemplateURL != "https://api.github.com/repos/template-owner/template-repo/generate" {
+		t.Errorf("expected the template URL from Secrets Manager, got %q", config.TemplateURL)
+	}
+}
+
+func TestRepoConfigBuilder_Invalid(t *testing.T) {
+	templateURL := "https://api.github.com/repos/template-owner/template-repo/generate"
+
+	tests := []struct {
+		name        string
+		builder     *RepoConfigBuilder
+		expectedErr string
+	}{
+		{
+			name:        "Empty Name",
+			builder:     NewRepoConfigBuilder("").WithTemplateURL(templateURL),
+			expectedErr: "repo name must not be empty",
+		},
+		{
+			name:        "Invalid Topic",
+			builder:     NewRepoConfigBuilder("test-repo").WithTemplateURL(templateURL).WithTopics("Go Service"),
+			expectedErr: `invalid topic "Go Service"`,
+		},
+		{
+			name:        "Too Many Topics",
+			builder:     NewRepoConfigBuilder("test-repo").WithTemplateURL(templateURL).WithTopics(strings.Split(strings.Repeat("go,", 21), ",")[:21]...),
+			expectedErr: "too many topics: 21",
+		},
+		{
+			name:        "Invalid License",
+			builder:     NewRepoConfigBuilder("test-repo").WithTemplateURL(templateURL).WithLicense("MIT License"),
+			expectedErr: `invalid license "MIT License"`,
+		},
+		{
+			name:        "Empty Environment Name",
+			builder:     NewRepoConfigBuilder("test-repo").WithTemplateURL(templateURL).WithEnvironment(""),
+			expectedErr: "environment name must not be empty",
+		},
+		{
+			name:        "Too Many Reviewers",
+			builder:     NewRepoConfigBuilder("test-repo").WithTemplateURL(templateURL).WithEnvironment("production", "a", "b", "c", "d", "e", "f", "g"),
+			expectedErr: "too many reviewers for environment production: 7",
+		},
+		{
+			name:        "Invalid Template URL",
+			builder:     NewRepoConfigBuilder("test-repo").WithTemplateURL("http://example.com/template"),
+			expectedErr: "invalid template URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("expected error containing %q, got: %v", tt.expectedErr, err)
+			}
+		})
+	}
+
+	if _, err := NewRepoConfigBuilder("").Build(); !errors.Is(err, ErrEmptyRepoName) {
+		t.Errorf("expected %v, got: %v", ErrEmptyRepoName, err)
+	}
+}
@@ -0,0 +1,150 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Backoff of RepoReadyWaiter.WaitReady: the first retry waits repoReadyInitialDelay, doubling up to repoReadyMaxDelay.
+const (
+	repoReadyInitialDelay = 500 * time.Millisecond
+	repoReadyMaxDelay     = 8 * time.Second
+)
+
+// repoReadyTimeout is how long CreateRepoHandler waits for a new repository to be ready.
+const repoReadyTimeout = time.Minute
+
+// defaultBranchFallback is waited for when GitHub's answer does not name the default branch.
+const defaultBranchFallback = "main"
+
+// RepoReadyWaiter waits until the default branch of a repository created by CreateGitRepository
+// can be read. GitHub answers the generate request before the template contents are copied,
+// so a clone right after creation can find an empty repository.
+type RepoReadyWaiter struct {
+	client  *GitClient
+	owner   string // Empty when GitHub's answer did not name it; looked up from the token then
+	repo    string
+	branch  string
+	rename  string // RepoConfig.DefaultBranch; the branch is renamed to it once it exists
+	license string // RepoConfig.License; committed as LICENSE once the branch exists
+}
+
+// newRepoReadyWaiter returns the waiter for the repository created with config, which GitHub described with result.
+// The branch GitHub reported is waited for, since it is the one the template contents are copied to.
+func (client *GitClient) newRepoReadyWaiter(config RepoConfig, result RepoCreateResult) *RepoReadyWaiter {
+	owner, _, _ := strings.Cut(result.FullName, "/")
+	branch := result.DefaultBranch
+	if branch == "" {
+		branch = defaultBranchFallback
+	}
+	return &RepoReadyWaiter{client: client, owner: owner, repo: config.Name, branch: branch, rename: config.DefaultBranch, license: config.License}
+}
+
+// WaitReady polls GET /repos/{owner}/{repo}/git/refs/heads/{branch} with exponential backoff until it
+// answers 200 OK, returning ctx's error when ctx is done first. Not found, conflict (an empty repository)
+// and server errors are retried; other statuses are returned as a GitHubError.
+// A RepoConfig.DefaultBranch other than the generated branch is then applied with
+// POST /repos/{owner}/{repo}/branches/{branch}/rename, which also makes it the default branch,
+// and a RepoConfig.License is committed to LICENSE on the default branch.
+func (w *RepoReadyWaiter) WaitReady(ctx context.Context) error {
+	token, err := w.client.refreshToken(ctx)
+	if err != nil {
+		return err
+	}
+	if w.owner == "" {
+		if w.owner, err = fetchGitHubUsername(ctx, w.client.HTTPClient, token, w.client.apiURL("/user")); err != nil {
+			return err
+		}
+	}
+
+	delay := repoReadyInitialDelay
+	for {
+		ready, err := w.branchExists(ctx, token)
+		if err != nil {
+			return err
+		}
+		if ready {
+			if err := w.renameBranch(ctx, token); err != nil {
+				return err
+			}
+			return w.addLicense(ctx, token)
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return fmt.Errorf("repository %s/%s not ready: %w", w.owner, w.repo, err)
+		}
+		delay = min(delay*2, repoReadyMaxDelay)
+	}
+}
+
+// branchExists reports whether the branch ref can be read, or an error for statuses that will not change by waiting.
+func (w *RepoReadyWaiter) branchExists(ctx context.Context, token string) (bool, error) {
+	url := w.client.apiURL(fmt.Sprintf("/repos/%s/%s/git/refs/heads/%s", w.owner, w.repo, w.branch))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "token "+token)
+
+	resp, err := w.client.HTTPClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, fmt.Errorf("repository %s/%s not ready: %w", w.owner, w.repo, ctx.Err())
+		}
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return true, nil
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusConflict, resp.StatusCode >= http.StatusInternalServerError:
+		return false, nil
+	}
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return false, newGitHubError(resp.StatusCode, "failed to check branch %s, status code: %d, response: %s", w.branch, resp.StatusCode, string(body))
+}
+
+// renameBranch renames the generated branch to the configured default branch, if one is set.
+func (w *RepoReadyWaiter) renameBranch(ctx context.Context, token string) error {
+	if w.rename == "" || w.rename == w.branch {
+		return nil
+	}
+
+	data, err := json.Marshal(map[string]string{"new_name": w.rename})
+	if err != nil {
+		return err
+	}
+	url := w.client.apiURL(fmt.Sprintf("/repos/%s/%s/branches/%s/rename", w.owner, w.repo, w.branch))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusCreated {
+		w.branch = w.rename
+		return nil
+	}
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	return newGitHubError(resp.StatusCode, "failed to rename branch %s to %s, status code: %d, response: %s", w.branch, w.rename, resp.StatusCode, string(body))
+}
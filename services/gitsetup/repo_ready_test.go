@@ -0,0 +1,209 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// useRecordedWaits replaces timeAfter with one that records the waits and returns at once until the test ends.
+func useRecordedWaits(t *testing.T) *[]time.Duration {
+	originalAfter := timeAfter
+	t.Cleanup(func() { timeAfter = originalAfter })
+	var waits []time.Duration
+	timeAfter = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		fired := make(chan time.Time, 1)
+		fired <- time.Time{}
+		return fired
+	}
+	return &waits
+}
+
+// refStatusClient answers the ref requests with statuses in turn and records the requested URLs.
+func refStatusClient(urls *[]string, statuses ...int) *GitClient {
+	return &GitClient{
+		HTTPClient: &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/user" {
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+				}
+				*urls = append(*urls, req.URL.String())
+				status := statuses[min(len(*urls), len(statuses))-1]
+				return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewBufferString("Git Repository is empty."))}, nil
+			},
+		},
+		FetchSecretFunc: mockFetchSecretFunc,
+	}
+}
+
+func TestRepoReadyWaiter_WaitReady(t *testing.T) {
+	waits := useRecordedWaits(t)
+	var urls []string
+	client := refStatusClient(&urls, http.StatusNotFound, http.StatusConflict, http.StatusBadGateway, http.StatusOK)
+	waiter := client.newRepoReadyWaiter(RepoConfig{Name: "test-repo"}, RepoCreateResult{FullName: "my-org/test-repo", DefaultBranch: "master"})
+
+	if err := waiter.WaitReady(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if len(urls) != 4 || urls[0] != "https://api.github.com/repos/my-org/test-repo/git/refs/heads/master" {
+		t.Errorf("expected 4 requests for the master ref of my-org/test-repo, got %v", urls)
+	}
+	expectedWaits := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second}
+	if !reflect.DeepEqual(*waits, expectedWaits) {
+		t.Errorf("expected waits %v, got %v", expectedWaits, *waits)
+	}
+}
+
+func TestRepoReadyWaiter_Branch(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      RepoConfig
+		result      RepoCreateResult
+		expectedURL string
+	}{
+		{
+			name:        "Reported Branch",
+			config:      RepoConfig{Name: "test-repo"},
+			result:      RepoCreateResult{FullName: "octocat/test-repo", DefaultBranch: "master"},
+			expectedURL: "https://api.github.com/repos/octocat/test-repo/git/refs/heads/master",
+		},
+		{
+			name:        "Owner Looked Up",
+			config:      RepoConfig{Name: "test-repo"},
+			expectedURL: "https://api.github.com/repos/octocat/test-repo/git/refs/heads/main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []string
+			waiter := refStatusClient(&urls, http.StatusOK).newRepoReadyWaiter(tt.config, tt.result)
+
+			if err := waiter.WaitReady(context.Background()); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(urls) != 1 || urls[0] != tt.expectedURL {
+				t.Errorf("expected a request for %s, got %v", tt.expectedURL, urls)
+			}
+		})
+	}
+}
+
+func TestRepoReadyWaiter_RenameBranch(t *testing.T) {
+	tests := []struct {
+		name            string
+		defaultBranch   string
+		renameStatus    int
+		expectedRenames int
+		expectedStatus  int
+	}{
+		{name: "Renamed", defaultBranch: "develop", renameStatus: http.StatusCreated, expectedRenames: 1},
+		{name: "Same Branch", defaultBranch: "master"},
+		{name: "Not Configured"},
+		{
+			name:            "Rejected",
+			defaultBranch:   "develop",
+			renameStatus:    http.StatusUnprocessableEntity,
+			expectedRenames: 1,
+			expectedStatus:  http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var renames []map[string]string
+			client := &GitClient{
+				HTTPClient: &mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						switch {
+						case req.Method == http.MethodGet && req.URL.Path == "/repos/octocat/test-repo/git/refs/heads/master":
+							return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("{}"))}, nil
+						case req.Method == http.MethodPost && req.URL.Path == "/repos/octocat/test-repo/branches/master/rename":
+							var body map[string]string
+							json.NewDecoder(req.Body).Decode(&body)
+							renames = append(renames, body)
+							return &http.Response{StatusCode: tt.renameStatus, Body: io.NopCloser(bytes.NewBufferString("Validation Failed"))}, nil
+						}
+						t.Errorf("unexpected request: %s %s", req.Method, req.URL)
+						return nil, errors.New("unexpected request")
+					},
+				},
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+			config := RepoConfig{Name: "test-repo", DefaultBranch: tt.defaultBranch}
+			waiter := client.newRepoReadyWaiter(config, RepoCreateResult{FullName: "octocat/test-repo", DefaultBranch: "master"})
+
+			err := waiter.WaitReady(context.Background())
+			if tt.expectedStatus != 0 {
+				var gitHubErr *GitHubError
+				if !errors.As(err, &gitHubErr) || gitHubErr.StatusCode != tt.expectedStatus {
+					t.Errorf("expected a %d GitHubError, got: %v", tt.expectedStatus, err)
+				}
+			} else if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(renames) != tt.expectedRenames {
+				t.Fatalf("expected %d rename requests, got %d", tt.expectedRenames, len(renames))
+			}
+			if len(renames) > 0 && renames[0]["new_name"] != tt.defaultBranch {
+				t.Errorf("expected new_name %q, got %q", tt.defaultBranch, renames[0]["new_name"])
+			}
+		})
+	}
+}
+
+func TestRepoReadyWaiter_BackoffIsCapped(t *testing.T) {
+	waits := useRecordedWaits(t)
+	var urls []string
+	statuses := []int{404, 404, 404, 404, 404, 404, 404, 200}
+	waiter := refStatusClient(&urls, statuses...).newRepoReadyWaiter(RepoConfig{Name: "test-repo"}, RepoCreateResult{FullName: "octocat/test-repo"})
+
+	if err := waiter.WaitReady(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if last := (*waits)[len(*waits)-1]; last != repoReadyMaxDelay {
+		t.Errorf("expected the waits to be capped at %s, got %v", repoReadyMaxDelay, *waits)
+	}
+}
+
+func TestRepoReadyWaiter_Errors(t *testing.T) {
+	t.Run("Forbidden", func(t *testing.T) {
+		useRecordedWaits(t)
+		var urls []string
+		waiter := refStatusClient(&urls, http.StatusForbidden).newRepoReadyWaiter(RepoConfig{Name: "test-repo"}, RepoCreateResult{FullName: "octocat/test-repo"})
+
+		err := waiter.WaitReady(context.Background())
+		var gitHubErr *GitHubError
+		if !errors.As(err, &gitHubErr) || gitHubErr.StatusCode != http.StatusForbidden {
+			t.Errorf("expected a 403 GitHubError, got: %v", err)
+		}
+		if len(urls) != 1 {
+			t.Errorf("expected no retry, got %d requests", len(urls))
+		}
+	})
+
+	t.Run("Context Done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		originalAfter := timeAfter
+		t.Cleanup(func() { timeAfter = originalAfter })
+		timeAfter = func(time.Duration) <-chan time.Time {
+			cancel()
+			return make(chan time.Time)
+		}
+		var urls []string
+		waiter := refStatusClient(&urls, http.StatusNotFound).newRepoReadyWaiter(RepoConfig{Name: "test-repo"}, RepoCreateResult{FullName: "octocat/test-repo"})
+
+		err := waiter.WaitReady(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got: %v", err)
+		}
+	})
+}
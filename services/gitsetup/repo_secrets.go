@@ -0,0 +1,160 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// gitHubAPIURL is the base URL of the GitHub REST API.
+const gitHubAPIURL = "https://api.github.com"
+
+// gitHubWebURL is the base URL repositories on github.com are cloned from.
+const gitHubWebURL = "https://github.com"
+
+// repoPublicKey is the public key GitHub uses to encrypt Actions secrets for a repository.
+type repoPublicKey struct {
+	KeyID string `json:"key_id"`
+	Key   string `json:"key"`
+}
+
+// CreateGitHubSecret creates or updates a GitHub Actions secret on the given repository.
+// The value is encrypted with the repository's public key before it is sent.
+func CreateGitHubSecret(client HTTPClient, owner, repo, secretName, secretValue, token string) error {
+	return createGitHubSecret(context.Background(), client, owner, repo, secretName, secretValue, token)
+}
+
+// createGitHubSecret is CreateGitHubSecret with a context that cancels the GitHub API calls.
+func createGitHubSecret(ctx context.Context, client HTTPClient, owner, repo, secretName, secretValue, token string) error {
+	publicKey, err := fetchRepoPublicKey(ctx, client, owner, repo, token)
+	if err != nil {
+		return err
+	}
+
+	encryptedValue, err := encryptSecret(publicKey.Key, secretValue)
+	if err != nil {
+		return fmt.Errorf("error encrypting secret %s: %v", secretName, err)
+	}
+
+	data, err := json.Marshal(map[string]string{
+		"encrypted_value": encryptedValue,
+		"key_id":          publicKey.KeyID,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/actions/secrets/%s", gitHubAPIURL, owner, repo, secretName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// GitHub answers 201 when the secret is created and 204 when it is updated.
+	if resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return newGitHubError(resp.StatusCode, "failed to create secret %s, status code: %d, response: %s", secretName, resp.StatusCode, string(body))
+}
+
+// CreateRepoSecrets creates every secret in secrets on the authenticated user's repository.
+func CreateRepoSecrets(client HTTPClient, repoName string, secrets map[string]string) error {
+	return CreateRepoSecretsContext(context.Background(), client, repoName, secrets)
+}
+
+// CreateRepoSecretsContext is CreateRepoSecrets with a context that cancels the GitHub API calls.
+func CreateRepoSecretsContext(ctx context.Context, client HTTPClient, repoName string, secrets map[string]string) error {
+	token, err := gitHubService.FetchSecretToken()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFetchingToken, err)
+	}
+
+	username, err := gitHubService.FetchGitHubUsername(ctx, token)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFetchingUsername, err)
+	}
+
+	// Sort the names so secrets are always created in the same order
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := createGitHubSecret(ctx, client, username, repoName, name, secrets[name], token); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchRepoPublicKey fetches the public key used to encrypt Actions secrets for the repository.
+func fetchRepoPublicKey(ctx context.Context, client HTTPClient, owner, repo, token string) (repoPublicKey, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/actions/secrets/public-key", gitHubAPIURL, owner, repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return repoPublicKey{}, err
+	}
+	req.Header.Set("Authorization", "token "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return repoPublicKey{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return repoPublicKey{}, newGitHubError(resp.StatusCode, "failed to fetch repository public key, status code: %d", resp.StatusCode)
+	}
+
+	var key repoPublicKey
+	if err := json.NewDecoder(resp.Body).Decode(&key); err != nil {
+		return repoPublicKey{}, err
+	}
+
+	return key, nil
+}
+
+// encryptSecret seals value with the base64 encoded public key and returns the base64 encoded result.
+func encryptSecret(encodedKey, value string) (string, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return "", err
+	}
+	if len(keyBytes) != 32 {
+		return "", fmt.Errorf("invalid public key length: %d", len(keyBytes))
+	}
+
+	var publicKey [32]byte
+	copy(publicKey[:], keyBytes)
+
+	sealed, err := box.SealAnonymous(nil, []byte(value), &publicKey, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
@@ -0,0 +1,146 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// mockGitHubService is a mock implementation of the GitHubService interface.
+type mockGitHubService struct {
+	token       string
+	tokenErr    error
+	username    string
+	usernameErr error
+}
+
+func (m mockGitHubService) FetchSecretToken() (string, error) {
+	return m.token, m.tokenErr
+}
+
+func (m mockGitHubService) FetchGitHubUsername(ctx context.Context, token string) (string, error) {
+	return m.username, m.usernameErr
+}
+
+// secretsHTTPClient serves the repository public key and records the secrets that are stored.
+func secretsHTTPClient(t *testing.T, publicKey *[32]byte, putStatus int, stored map[string]string) *mockHTTPClient {
+	return &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodGet {
+				body, _ := json.Marshal(map[string]string{
+					"key_id": "key-id",
+					"key":    base64.StdEncoding.EncodeToString(publicKey[:]),
+				})
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBuffer(body))}, nil
+			}
+
+			var payload map[string]string
+			if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+				t.Fatalf("failed to decode request body: %v", err)
+			}
+			if payload["key_id"] != "key-id" {
+				t.Errorf("expected key_id %q, got %q", "key-id", payload["key_id"])
+			}
+			name := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+			stored[name] = payload["encrypted_value"]
+			return &http.Response{StatusCode: putStatus, Body: io.NopCloser(bytes.NewBufferString("Bad Request"))}, nil
+		},
+	}
+}
+
+func TestCreateGitHubSecret(t *testing.T) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key pair: %v", err)
+	}
+
+	t.Run("Secret Created", func(t *testing.T) {
+		stored := map[string]string{}
+		client := secretsHTTPClient(t, publicKey, http.StatusCreated, stored)
+
+		if err := CreateGitHubSecret(client, "owner", "repo", "AWS_ACCOUNT_ID", "123456789012", "mock_token"); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+
+		sealed, err := base64.StdEncoding.DecodeString(stored["AWS_ACCOUNT_ID"])
+		if err != nil {
+			t.Fatalf("failed to decode encrypted value: %v", err)
+		}
+		opened, ok := box.OpenAnonymous(nil, sealed, publicKey, privateKey)
+		if !ok {
+			t.Fatalf("failed to decrypt secret value")
+		}
+		if string(opened) != "123456789012" {
+			t.Errorf("expected secret value %q, got %q", "123456789012", string(opened))
+		}
+	})
+
+	t.Run("Secret Rejected", func(t *testing.T) {
+		client := secretsHTTPClient(t, publicKey, http.StatusBadRequest, map[string]string{})
+
+		err := CreateGitHubSecret(client, "owner", "repo", "AWS_ACCOUNT_ID", "123456789012", "mock_token")
+		expected := "failed to create secret AWS_ACCOUNT_ID, status code: 400, response: Bad Request"
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	})
+
+	t.Run("Public Key Error", func(t *testing.T) {
+		client := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+			},
+		}
+
+		err := CreateGitHubSecret(client, "owner", "repo", "AWS_ACCOUNT_ID", "123456789012", "mock_token")
+		expected := "failed to fetch repository public key, status code: 404"
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	})
+}
+
+func TestCreateRepoSecrets(t *testing.T) {
+	originalService := gitHubService
+	defer func() { gitHubService = originalService }()
+
+	publicKey, _, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key pair: %v", err)
+	}
+
+	t.Run("All Secrets Created", func(t *testing.T) {
+		gitHubService = mockGitHubService{token: "mock_token", username: "owner"}
+		stored := map[string]string{}
+		client := secretsHTTPClient(t, publicKey, http.StatusCreated, stored)
+
+		err := CreateRepoSecrets(client, "repo", map[string]string{
+			"AWS_ACCOUNT_ID": "123456789012",
+			"ECR_REPO_URI":   "123456789012.dkr.ecr.us-east-1.amazonaws.com/repo",
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(stored) != 2 {
+			t.Errorf("expected 2 secrets to be stored, got %d", len(stored))
+		}
+	})
+
+	t.Run("Token Error", func(t *testing.T) {
+		gitHubService = mockGitHubService{tokenErr: errors.New("token error")}
+
+		err := CreateRepoSecrets(&mockHTTPClient{}, "repo", map[string]string{"KEY": "value"})
+		if !errors.Is(err, ErrFetchingToken) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package gitsetup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// extraRepoSettings lists the RepoConfig.Extra keys accepted by PATCH /repos/{owner}/{repo},
+// and whether their value is sent as a boolean. name is left out since it would rename the repository,
+// default_branch since the branch does not exist yet; RepoConfig.DefaultBranch renames it instead,
+// and private and visibility since they could make a repository created with RepoConfig.Private public.
+var extraRepoSettings = map[string]bool{
+	"description":                    false,
+	"homepage":                       false,
+	"squash_merge_commit_title":      false,
+	"squash_merge_commit_message":    false,
+	"merge_commit_title":             false,
+	"merge_commit_message":           false,
+	"has_issues":                     true,
+	"has_projects":                   true,
+	"has_wiki":                       true,
+	"has_discussions":                true,
+	"is_template":                    true,
+	"allow_squash_merge":             true,
+	"allow_merge_commit":             true,
+	"allow_rebase_merge":             true,
+	"allow_auto_merge":               true,
+	"allow_update_branch":            true,
+	"allow_forking":                  true,
+	"delete_branch_on_merge":         true,
+	"use_squash_pr_title_as_default": true,
+	"web_commit_signoff_required":    true,
+	"archived":                       true,
+}
+
+// repositorySettings returns the settings of config that CreateGitRepository applies with
+// PATCH /repos/{owner}/{repo} after the generate request. The description is sent again since
+// whether the generate endpoint applies it depends on the template. Extra keys the endpoint
+// does not accept return ErrUnsupportedRepoSetting.
+func repositorySettings(config RepoConfig) (map[string]any, error) {
+	// Extra settings go in first so the fields set below take precedence over them
+	settings := make(map[string]any, len(config.Extra))
+	for key, value := range config.Extra {
+		isBool, ok := extraRepoSettings[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedRepoSetting, key)
+		}
+		if !isBool {
+			settings[key] = value
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be true or false, got %q", ErrUnsupportedRepoSetting, key, value)
+		}
+		settings[key] = enabled
+	}
+	if config.Description != "" {
+		settings["description"] = config.Description
+	}
+	if config.AutoDeleteHeadBranches {
+		settings["delete_branch_on_merge"] = true
+	}
+	if config.MergeOptions.IsSet() {
+		settings["allow_squash_merge"] = config.MergeOptions.AllowSquash
+		settings["allow_merge_commit"] = config.MergeOptions.AllowMerge
+		settings["allow_rebase_merge"] = config.MergeOptions.AllowRebase
+	}
+	// GitHub only accepts the squash merge commit settings together with allow_squash_merge.
+	if config.SquashMerge.CommitTitle != "" {
+		settings["allow_squash_merge"] = true
+		settings["squash_merge_commit_title"] = config.SquashMerge.CommitTitle
+	}
+	if config.SquashMerge.CommitMessage != "" {
+		settings["allow_squash_merge"] = true
+		settings["squash_merge_commit_message"] = config.SquashMerge.CommitMessage
+	}
+	return settings, nil
+}
+
+// updateRepository applies settings to the token owner's repository with PATCH /repos/{owner}/{repo}.
+func (client *GitClient) updateRepository(ctx context.Context, token, repoName string, settings map[string]any) error {
+	owner, err := fetchGitHubUsername(ctx, client.HTTPClient, token, client.apiURL("/user"))
+	if err != nil {
+		return err
+	}
+	return client.updateOwnerRepository(ctx, token, owner, repoName, settings)
+}
+
+// updateOwnerRepository applies settings to owner/repoName with PATCH /repos/{owner}/{repo}.
+func (client *GitClient) updateOwnerRepository(ctx context.Context, token, owner, repoName string, settings map[string]any) error {
+	resp, err := client.sendOwnerRepoRequest(ctx, token, http.MethodPatch, owner, repoName, "", settings)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return newGitHubError(resp.StatusCode, "failed to update repository, status code: %d, response: %s", resp.StatusCode, string(body))
+}
+
+// replaceTopics replaces the topics of the repository with PUT /repos/{owner}/{repo}/topics.
+func (client *GitClient) replaceTopics(ctx context.Context, token, repoName string, topics []string) error {
+	resp, err := client.sendRepoRequest(ctx, token, http.MethodPut, repoName, "/topics", map[string]any{"names": topics})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, err := readResponseBody(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return newGitHubError(resp.StatusCode, "failed to replace topics, status code: %d, response: %s", resp.StatusCode, string(body))
+}
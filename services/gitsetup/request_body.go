@@ -0,0 +1,32 @@
+package gitsetup
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+// Request body limit of the JSON handlers.
+const (
+	DefaultMaxRequestBodyBytes int64 = 64 << 10
+
+	MaxRequestBodyEnvVar = "AUTOBUILD_MAX_REQUEST_BODY"
+)
+
+// maxRequestBodySize is the largest request body the JSON handlers decode.
+var maxRequestBodySize = maxRequestBodySizeFromEnv()
+
+// maxRequestBodySizeFromEnv returns the size in bytes set in AUTOBUILD_MAX_REQUEST_BODY,
+// falling back to DefaultMaxRequestBodyBytes for unset or invalid values.
+func maxRequestBodySizeFromEnv() int64 {
+	value := os.Getenv(MaxRequestBodyEnvVar)
+	if value == "" {
+		return DefaultMaxRequestBodyBytes
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		slog.Warn("ignoring invalid request body limit", "env", MaxRequestBodyEnvVar, "value", value)
+		return DefaultMaxRequestBodyBytes
+	}
+	return n
+}
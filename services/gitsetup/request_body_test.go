@@ -0,0 +1,47 @@
+package gitsetup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxRequestBodySizeFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected int64
+	}{
+		{name: "Unset", value: "", expected: DefaultMaxRequestBodyBytes},
+		{name: "Valid", value: "1024", expected: 1024},
+		{name: "Invalid", value: "lots", expected: DefaultMaxRequestBodyBytes},
+		{name: "Not Positive", value: "0", expected: DefaultMaxRequestBodyBytes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(MaxRequestBodyEnvVar, tt.value)
+			if got := maxRequestBodySizeFromEnv(); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCreateRepoHandler_RequestBodyTooLarge(t *testing.T) {
+	originalMax := maxRequestBodySize
+	maxRequestBodySize = 32
+	defer func() { maxRequestBodySize = originalMax }()
+
+	body := `{"repo_name": "test-repo", "description": "` + strings.Repeat("x", 100) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/create-repo", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	CreateRepoHandler(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+}
@@ -0,0 +1,29 @@
+package gitsetup
+
+import "sync"
+
+// RequestDeduplicator tracks the repositories that are being created so concurrent
+// requests for the same repository name can be rejected.
+type RequestDeduplicator struct {
+	inProgress sync.Map
+}
+
+// NewRequestDeduplicator returns an empty RequestDeduplicator.
+func NewRequestDeduplicator() *RequestDeduplicator {
+	return &RequestDeduplicator{}
+}
+
+// Acquire marks repoName as in progress. It returns false when another request already holds it.
+func (d *RequestDeduplicator) Acquire(repoName string) bool {
+	_, loaded := d.inProgress.LoadOrStore(repoName, struct{}{})
+	return !loaded
+}
+
+// Release marks repoName as no longer in progress.
+func (d *RequestDeduplicator) Release(repoName string) {
+	d.inProgress.Delete(repoName)
+}
+
+// createRequests deduplicates the CreateRepoHandler, ImportRepoHandler and ForkRepoHandler requests.
+// They share it since each creates an ECR repository named after the Git repository.
+var createRequests = NewRequestDeduplicator()
@@ -0,0 +1,108 @@
+package gitsetup
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestDeduplicator(t *testing.T) {
+	d := NewRequestDeduplicator()
+
+	if !d.Acquire("test-repo") {
+		t.Fatal("expected the first request to acquire test-repo")
+	}
+	if d.Acquire("test-repo") {
+		t.Error("expected a duplicate request to be rejected")
+	}
+	if !d.Acquire("other-repo") {
+		t.Error("expected a request for another repository to be accepted")
+	}
+
+	d.Release("test-repo")
+	if !d.Acquire("test-repo") {
+		t.Error("expected test-repo to be acquired again after release")
+	}
+}
+
+func TestCreateRepoHandler_DuplicateRequest(t *testing.T) {
+	seedSecretCache(t)
+
+	originalClock := Clock
+	Clock = func(d time.Duration) {}
+	defer func() { Clock = originalClock }()
+
+	CreateECRClientFunc = mockCreateECRClient
+	CreateRepoFunc = mockCreateRepo
+	NewGitClientFunc = mockNewGitClient
+	CloneAndPushRepoFunc = mockCloneAndPushRepo
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/create-repo", bytes.NewBufferString(`{"repo_name": "test-repo"}`))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	// Simulate a request for test-repo that is still running
+	createRequests.Acquire("test-repo")
+	w := httptest.NewRecorder()
+	CreateRepoHandler(w, newRequest())
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+	if body := strings.TrimSpace(w.Body.String()); body != "creation for repo test-repo already in progress" {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	// Once the first request finishes the name can be used again
+	createRequests.Release("test-repo")
+	w = httptest.NewRecorder()
+	CreateRepoHandler(w, newRequest())
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if !createRequests.Acquire("test-repo") {
+		t.Error("expected the handler to release test-repo when done")
+	}
+	createRequests.Release("test-repo")
+}
+
+func TestRepoHandlers_DuplicateRequest(t *testing.T) {
+	originalNewGitClient := NewGitClientFunc
+	defer func() { NewGitClientFunc = originalNewGitClient }()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		path    string
+		body    string
+	}{
+		{name: "Import", handler: ImportRepoHandler, path: "/import-repo", body: `{"repo_name": "test-repo"}`},
+		{name: "Fork", handler: ForkRepoHandler, path: "/fork-repo", body: `{"source_owner": "octocat", "source_repo": "test-repo"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			NewGitClientFunc = func() *GitClient {
+				t.Error("expected the duplicate request to be rejected before GitHub is called")
+				return mockNewGitClient()
+			}
+
+			// Simulate a create request for test-repo that is still running
+			createRequests.Acquire("test-repo")
+			defer createRequests.Release("test-repo")
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			tt.handler(w, req)
+
+			if w.Code != http.StatusConflict {
+				t.Errorf("expected status %d, got %d", http.StatusConflict, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package gitsetup
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Request rate limits of the web server, in requests per second. A limit is not applied when its
+// variable is unset. Bursts of up to one second's worth of requests are allowed.
+const (
+	RateLimitEnvVar      = "AUTOBUILD_RATE_LIMIT"
+	RateLimitPerIPEnvVar = "AUTOBUILD_RATE_LIMIT_PER_IP"
+)
+
+// DefaultRateLimiterCleanupInterval is how long a client IP may be idle before its limiter is dropped.
+const DefaultRateLimiterCleanupInterval = 10 * time.Minute
+
+// PerIPRateLimiter limits requests per client IP, so one client cannot use up the global limit.
+type PerIPRateLimiter struct {
+	Limit rate.Limit
+	Burst int
+	// CleanupInterval drops the limiters of IPs without requests for longer than this.
+	CleanupInterval time.Duration
+
+	limiters    sync.Map // client IP -> *ipRateLimiter
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+// ipRateLimiter is the limiter of one client IP and when it was last used.
+type ipRateLimiter struct {
+	limiter  *rate.Limiter
+	mu       sync.Mutex
+	lastSeen time.Time
+}
+
+// NewPerIPRateLimiter returns a PerIPRateLimiter allowing limit requests per second per IP with the given burst.
+func NewPerIPRateLimiter(limit rate.Limit, burst int) *PerIPRateLimiter {
+	return &PerIPRateLimiter{
+		Limit:           limit,
+		Burst:           burst,
+		CleanupInterval: DefaultRateLimiterCleanupInterval,
+	}
+}
+
+// Allow reports whether a request from ip may proceed, creating the limiter of ip on its first request.
+func (l *PerIPRateLimiter) Allow(ip string) bool {
+	now := timeNow()
+	l.cleanup(now)
+
+	value, _ := l.limiters.LoadOrStore(ip, &ipRateLimiter{limiter: rate.NewLimiter(l.Limit, l.Burst)})
+	entry := value.(*ipRateLimiter)
+	entry.mu.Lock()
+	entry.lastSeen = now
+	entry.mu.Unlock()
+	return entry.limiter.AllowN(now, 1)
+}
+
+// cleanup drops the limiters idle for longer than CleanupInterval, at most once per interval.
+func (l *PerIPRateLimiter) cleanup(now time.Time) {
+	l.mu.Lock()
+	if now.Sub(l.lastCleanup) < l.CleanupInterval {
+		l.mu.Unlock()
+		return
+	}
+	l.lastCleanup = now
+	l.mu.Unlock()
+
+	l.limiters.Range(func(key, value any) bool {
+		entry := value.(*ipRateLimiter)
+		entry.mu.Lock()
+		idle := now.Sub(entry.lastSeen) > l.CleanupInterval
+		entry.mu.Unlock()
+		if idle {
+			l.limiters.Delete(key)
+		}
+		return true
+	})
+}
+
+// RateLimitMiddleware answers 429 Too Many Requests unless both the global and the per-IP limit
+// allow the request. Either limiter may be nil to skip that limit.
+func RateLimitMiddleware(global *rate.Limiter, perIP *PerIPRateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The per-IP limit is checked first so a client over its own limit does not use up the global one
+			if (perIP != nil && !perIP.Allow(clientIP(r))) || (global != nil && !global.Allow()) {
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the IP of r.RemoteAddr without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimitFromEnv returns the limit and burst set in the named variable, and false when it is unset or invalid.
+func rateLimitFromEnv(name string) (rate.Limit, int, bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, 0, false
+	}
+	perSecond, err := strconv.ParseFloat(value, 64)
+	if err != nil || perSecond <= 0 {
+		slog.Warn("ignoring invalid rate limit", "env", name, "value", value)
+		return 0, 0, false
+	}
+	return rate.Limit(perSecond), int(math.Ceil(perSecond)), true
+}
+
+// rateLimits are the global and per-IP request rate limits in requests per second, 0 when not limited.
+type rateLimits struct {
+	global float64
+	perIP  float64
+}
+
+// rateLimitsFromEnv returns the limits set in the environment.
+func rateLimitsFromEnv() rateLimits {
+	var limits rateLimits
+	if limit, _, ok := rateLimitFromEnv(RateLimitEnvVar); ok {
+		limits.global = float64(limit)
+	}
+	if limit, _, ok := rateLimitFromEnv(RateLimitPerIPEnvVar); ok {
+		limits.perIP = float64(limit)
+	}
+	return limits
+}
+
+// middleware returns a RateLimitMiddleware with new limiters for l, or nil when neither limit is set.
+// Bursts of up to one second's worth of requests are allowed.
+func (l rateLimits) middleware() func(http.Handler) http.Handler {
+	var global *rate.Limiter
+	if l.global > 0 {
+		global = rate.NewLimiter(rate.Limit(l.global), int(math.Ceil(l.global)))
+	}
+	var perIP *PerIPRateLimiter
+	if l.perIP > 0 {
+		perIP = NewPerIPRateLimiter(rate.Limit(l.perIP), int(math.Ceil(l.perIP)))
+	}
+	if global == nil && perIP == nil {
+		return nil
+	}
+	return RateLimitMiddleware(global, perIP)
+}
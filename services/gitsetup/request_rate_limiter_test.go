@@ -0,0 +1,146 @@
+package gitsetup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// fixedTime makes timeNow return a time the test can move.
+func fixedTime(t *testing.T) *time.Time {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	originalNow := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = originalNow })
+	return &now
+}
+
+func TestPerIPRateLimiter(t *testing.T) {
+	now := fixedTime(t)
+	limiter := NewPerIPRateLimiter(1, 2)
+
+	for i := 0; i < 2; i++ {
+		if !limiter.Allow("192.0.2.1") {
+			t.Fatalf("expected request %d within the burst to be allowed", i+1)
+		}
+	}
+	if limiter.Allow("192.0.2.1") {
+		t.Error("expected the third request to be limited")
+	}
+	if !limiter.Allow("192.0.2.2") {
+		t.Error("expected another IP to have its own limit")
+	}
+
+	*now = now.Add(time.Second)
+	if !limiter.Allow("192.0.2.1") {
+		t.Error("expected a request to be allowed once a token is refilled")
+	}
+}
+
+func TestPerIPRateLimiter_Cleanup(t *testing.T) {
+	now := fixedTime(t)
+	limiter := NewPerIPRateLimiter(1, 1)
+	limiter.CleanupInterval = time.Minute
+
+	limiter.Allow("192.0.2.1")
+	*now = now.Add(30 * time.Second)
+	limiter.Allow("192.0.2.2")
+
+	// 192.0.2.1 has been idle for more than a minute, 192.0.2.2 only for 40 seconds
+	*now = now.Add(40 * time.Second)
+	limiter.Allow("192.0.2.3")
+
+	if _, found := limiter.limiters.Load("192.0.2.1"); found {
+		t.Error("expected the idle limiter to be removed")
+	}
+	for _, ip := range []string{"192.0.2.2", "192.0.2.3"} {
+		if _, found := limiter.limiters.Load(ip); !found {
+			t.Errorf("expected the limiter of %s to be kept", ip)
+		}
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	fixedTime(t)
+
+	tests := []struct {
+		name             string
+		global           *rate.Limiter
+		perIP            *PerIPRateLimiter
+		remoteAddrs      []string
+		expectedStatuses []int
+	}{
+		{
+			name:             "Per IP Limit",
+			perIP:            NewPerIPRateLimiter(1, 1),
+			remoteAddrs:      []string{"192.0.2.1:1234", "192.0.2.1:5678", "192.0.2.2:1234"},
+			expectedStatuses: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name:             "Global Limit",
+			global:           rate.NewLimiter(rate.Every(time.Hour), 2),
+			perIP:            NewPerIPRateLimiter(1, 1),
+			remoteAddrs:      []string{"192.0.2.1:1234", "192.0.2.2:1234", "192.0.2.3:1234"},
+			expectedStatuses: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:             "No Limits",
+			remoteAddrs:      []string{"192.0.2.1:1234", "192.0.2.1:1234"},
+			expectedStatuses: []int{http.StatusOK, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RateLimitMiddleware(tt.global, tt.perIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			for i, remoteAddr := range tt.remoteAddrs {
+				req := httptest.NewRequest(http.MethodPost, "/create-repo", nil)
+				req.RemoteAddr = remoteAddr
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+
+				if w.Code != tt.expectedStatuses[i] {
+					t.Errorf("request %d from %s: expected status %d, got %d", i+1, remoteAddr, tt.expectedStatuses[i], w.Code)
+				}
+				if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+					t.Error("expected a Retry-After header")
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitsFromEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		global      string
+		perIP       string
+		expectedNil bool
+	}{
+		{name: "Unset", expectedNil: true},
+		{name: "Invalid", global: "fast", perIP: "-1", expectedNil: true},
+		{name: "Global Only", global: "10"},
+		{name: "Per IP Only", perIP: "0.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(RateLimitEnvVar, tt.global)
+			t.Setenv(RateLimitPerIPEnvVar, tt.perIP)
+
+			if middleware := rateLimitsFromEnv().middleware(); (middleware == nil) != tt.expectedNil {
+				t.Errorf("expected nil middleware: %v, got %v", tt.expectedNil, middleware == nil)
+			}
+		})
+	}
+
+	t.Setenv(RateLimitPerIPEnvVar, "2.5")
+	limit, burst, ok := rateLimitFromEnv(RateLimitPerIPEnvVar)
+	if !ok || limit != 2.5 || burst != 3 {
+		t.Errorf("expected limit 2.5 with burst 3, got %v, %d, %v", limit, burst, ok)
+	}
+}
@@ -0,0 +1,44 @@
+package gitsetup
+
+import (
+	_ "embed"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+//go:embed schema/repo_request.json
+var repoRequestSchemaJSON string
+
+// repoRequestSchema validates the body of every RepoRequest before it is decoded.
+var repoRequestSchema = mustLoadSchema(repoRequestSchemaJSON)
+
+//go:embed schema/ecr_repo_update.json
+var ecrRepoUpdateSchemaJSON string
+
+// ecrRepoUpdateSchema validates the body of every ECRRepoUpdateRequest before it is decoded.
+var ecrRepoUpdateSchema = mustLoadSchema(ecrRepoUpdateSchemaJSON)
+
+//go:embed schema/clone_config_update.json
+var cloneConfigUpdateSchemaJSON string
+
+// cloneConfigUpdateSchema validates the body of every CloneConfigUpdateRequest before it is decoded.
+var cloneConfigUpdateSchema = mustLoadSchema(cloneConfigUpdateSchemaJSON)
+
+// mustLoadSchema compiles an embedded JSON schema, panicking when it is invalid.
+func mustLoadSchema(schema string) *gojsonschema.Schema {
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(schema))
+	if err != nil {
+		panic("invalid embedded JSON schema: " + err.Error())
+	}
+	return compiled
+}
+
+// schemaErrors formats the errors of a failed validation as "field: description" lines joined by "; ".
+func schemaErrors(result *gojsonschema.Result) string {
+	var messages []string
+	for _, err := range result.Errors() {
+		messages = append(messages, err.Field()+": "+err.Description())
+	}
+	return strings.Join(messages, "; ")
+}
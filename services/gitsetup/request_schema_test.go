@@ -0,0 +1,64 @@
+package gitsetup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateRepoHandler_SchemaValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		expectedBody string
+	}{
+		{
+			name:         "Missing Repo Name",
+			body:         `{"description": "test description"}`,
+			expectedBody: "Invalid request: (root): repo_name is required",
+		},
+		{
+			name:         "Wrong Type",
+			body:         `{"repo_name": 42}`,
+			expectedBody: "Invalid request: repo_name: Invalid type. Expected: string, given: integer",
+		},
+		{
+			name:         "Invalid Repo Name",
+			body:         `{"repo_name": "test repo"}`,
+			expectedBody: "Invalid request: repo_name: Does not match pattern '^[A-Za-z0-9._-]*$'",
+		},
+		{
+			name:         "Invalid Secret Name",
+			body:         `{"repo_name": "test-repo", "secrets": {"API-KEY": "value"}}`,
+			expectedBody: "Invalid request: secrets: Property name of \"API-KEY\" does not match; secrets: Does not match pattern '^[A-Za-z_][A-Za-z0-9_]*$'",
+		},
+		{
+			name:         "Invalid Role ARN",
+			body:         `{"repo_name": "test-repo", "assume_role_arn": "ecr-admin"}`,
+			expectedBody: "Invalid request: assume_role_arn: Does not match pattern '^(arn:aws[a-z-]*:iam::[0-9]{12}:role/.+)?$'",
+		},
+		{
+			name:         "Unknown Field",
+			body:         `{"repo_name": "test-repo", "repo_nmae": "typo"}`,
+			expectedBody: "Invalid request: (root): Additional property repo_nmae is not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/create-repo", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			CreateRepoHandler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package gitsetup
+
+import "io"
+
+// MaxResponseBodyBytes is the largest GitHub response body that will be read into memory.
+var MaxResponseBodyBytes int64 = 1 << 20 // 1 MB
+
+// readResponseBody reads at most MaxResponseBodyBytes from body.
+func readResponseBody(body io.Reader) ([]byte, error) {
+	// Read one byte past the limit so a body of exactly the limit is still accepted
+	data, err := io.ReadAll(io.LimitReader(body, MaxResponseBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > MaxResponseBodyBytes {
+		return nil, ErrResponseBodyTooLarge
+	}
+	return data, nil
+}
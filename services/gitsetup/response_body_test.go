@@ -0,0 +1,64 @@
+package gitsetup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReadResponseBody(t *testing.T) {
+	originalMax := MaxResponseBodyBytes
+	MaxResponseBodyBytes = 10
+	defer func() { MaxResponseBodyBytes = originalMax }()
+
+	tests := []struct {
+		name        string
+		body        string
+		expectedErr error
+	}{
+		{name: "Under Limit", body: "short"},
+		{name: "At Limit", body: strings.Repeat("a", 10)},
+		{name: "Over Limit", body: strings.Repeat("a", 11), expectedErr: ErrResponseBodyTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := readResponseBody(strings.NewReader(tt.body))
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err == nil && string(data) != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, string(data))
+			}
+		})
+	}
+}
+
+func TestCreateGitRepository_ResponseBodyTooLarge(t *testing.T) {
+	originalMax := MaxResponseBodyBytes
+	MaxResponseBodyBytes = 10
+	defer func() { MaxResponseBodyBytes = originalMax }()
+
+	client := &GitClient{
+		HTTPClient: &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusInternalServerError,
+					Body:       io.NopCloser(bytes.NewBufferString(strings.Repeat("x", 1000))),
+				}, nil
+			},
+		},
+		FetchSecretFunc: mockFetchSecretFunc,
+	}
+
+	_, err := client.CreateGitRepository(RepoConfig{
+		Name:        "test-repo",
+		TemplateURL: "https://api.github.com/repos/template-owner/template-repo/generate",
+	})
+	if !errors.Is(err, ErrResponseBodyTooLarge) {
+		t.Errorf("expected %v, got %v", ErrResponseBodyTooLarge, err)
+	}
+}
@@ -0,0 +1,104 @@
+package gitsetup
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// walkDir walks the cloned repository and can be overridden in tests.
+var walkDir = filepath.WalkDir
+
+// rewriteImportPaths replaces oldModule with newModule in the imports of every .go file under root,
+// including checked out submodules. .git, vendor and testdata directories are skipped, since testdata
+// holds fixtures that need not be valid Go.
+// It returns the paths of the files that were modified.
+func rewriteImportPaths(root, oldModule, newModule string) ([]string, error) {
+	if oldModule == "" || oldModule == newModule {
+		return nil, nil
+	}
+
+	var modified []string
+	err := walkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (d.Name() == ".git" || d.Name() == "vendor" || d.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		changed, err := rewriteFileImports(path, oldModule, newModule)
+		if err != nil {
+			return err
+		}
+		if changed {
+			modified = append(modified, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error updating import paths: %v", err)
+	}
+
+	return modified, nil
+}
+
+// rewriteFileImports rewrites the import specs of a single file, leaving the rest of the file untouched.
+func rewriteFileImports(path, oldModule, newModule string) (bool, error) {
+	src, err := readFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ImportsOnly)
+	if err != nil {
+		return false, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	type replacement struct {
+		start, end int
+		value      string
+	}
+	var replacements []replacement
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if importPath != oldModule && !strings.HasPrefix(importPath, oldModule+"/") {
+			continue
+		}
+		replacements = append(replacements, replacement{
+			start: fset.Position(imp.Path.Pos()).Offset,
+			end:   fset.Position(imp.Path.End()).Offset,
+			value: strconv.Quote(newModule + strings.TrimPrefix(importPath, oldModule)),
+		})
+	}
+	if len(replacements) == 0 {
+		return false, nil
+	}
+
+	// Apply from the end of the file so earlier offsets stay valid
+	sort.Slice(replacements, func(i, j int) bool { return replacements[i].start > replacements[j].start })
+	output := src
+	for _, r := range replacements {
+		output = append(append(append([]byte{}, output[:r.start]...), r.value...), output[r.end:]...)
+	}
+
+	if err := writeFile(path, output, 0644); err != nil {
+		return false, fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return true, nil
+}
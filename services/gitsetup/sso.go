@@ -0,0 +1,167 @@
+package gitsetup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+)
+
+// IAM Identity Center (SSO) settings used by FetchSecretToken and FetchSecretValueWithSSORefresh.
+const (
+	UseSSOEnvVar = "USE_SSO"
+
+	SSOAccountIDEnvVar = "AUTOBUILD_SSO_ACCOUNT_ID"
+	SSORoleNameEnvVar  = "AUTOBUILD_SSO_ROLE_NAME"
+	SSOStartURLEnvVar  = "AUTOBUILD_SSO_START_URL"
+	// SSORegionEnvVar is the region of the SSO portal, us-east-1 when unset.
+	SSORegionEnvVar = "AUTOBUILD_SSO_REGION"
+	// SSOSessionEnvVar names the sso-session whose cached token is refreshed when it expires.
+	// When unset the token cached for the start URL is used as is.
+	SSOSessionEnvVar = "AUTOBUILD_SSO_SESSION"
+)
+
+// ssoSettings holds the IAM Identity Center settings read from the environment.
+type ssoSettings struct {
+	accountID, roleName, startURL, region, session string
+}
+
+// ssoClientCache holds the Secrets Manager client using SSO credentials once it is created, so the
+// credential cache refreshes the role credentials only when they expire. Failures are not kept, so
+// the next fetch tries again, e.g. after the SSO login.
+var ssoClientCache struct {
+	sync.Mutex
+	client SecretsManagerClient
+}
+
+// ssoSecretsManagerClient returns the Secrets Manager client using SSO credentials.
+var ssoSecretsManagerClient = cachedSSOSecretsManagerClient
+
+// cachedSSOSecretsManagerClient returns the client in ssoClientCache, creating it when there is none yet.
+func cachedSSOSecretsManagerClient() (SecretsManagerClient, error) {
+	ssoClientCache.Lock()
+	defer ssoClientCache.Unlock()
+	if ssoClientCache.client != nil {
+		return ssoClientCache.client, nil
+	}
+
+	client, err := newSSOSecretsManagerClient(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	ssoClientCache.client = client
+	return client, nil
+}
+
+// FetchSecretValueWithSSORefresh is FetchSecretValue using IAM Identity Center credentials when USE_SSO is true.
+// The role credentials are refreshed when they expire, and the SSO token too when AUTOBUILD_SSO_SESSION is set.
+// It falls back to the default credential chain when the SSO credentials cannot be used.
+func FetchSecretValueWithSSORefresh(key string) (string, error) {
+	return fetchSecretByConfigWithSSO(SecretConfig{
+		SecretID: secretIDFromEnv(GitHubTokenSecretIDEnvVar, DefaultGitHubTokenSecretID),
+		Key:      key,
+	})
+}
+
+// fetchSecretByConfigWithSSO is FetchSecretByConfig using IAM Identity Center credentials when USE_SSO is true.
+func fetchSecretByConfigWithSSO(cfg SecretConfig) (string, error) {
+	if !ssoEnabled() {
+		return FetchSecretByConfig(cfg)
+	}
+
+	value, err := fetchSecretWithSSO(cfg)
+	if err != nil {
+		return "", err
+	}
+	return verifySecretChecksum(cfg, value)
+}
+
+// fetchSecretWithSSO returns cfg.Key from the cache, fetching the whole secret with the SSO credentials on a miss.
+func fetchSecretWithSSO(cfg SecretConfig) (string, error) {
+	if value, found := cachedSecret(cfg); found {
+		return value, nil
+	}
+
+	client, err := ssoSecretsManagerClient()
+	if err != nil {
+		slog.Warn("falling back to the default AWS credentials", "secret", cfg.SecretID, "error", err)
+		return fetchSecret(cfg)
+	}
+
+	secretData, err := loadSecret(context.Background(), client, cfg.SecretID, cfg.VersionStage)
+	if err != nil {
+		slog.Warn("falling back to the default AWS credentials", "secret", cfg.SecretID, "error", err)
+		return fetchSecret(cfg)
+	}
+
+	value, found := secretData[cfg.Key]
+	if !found {
+		return "", fmt.Errorf("%w: %s", ErrSecretKeyNotFound, cfg.Key)
+	}
+	return value, nil
+}
+
+// ssoEnabled reports whether USE_SSO is set to true.
+func ssoEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(UseSSOEnvVar))
+	return enabled
+}
+
+// ssoSettingsFromEnv reads the IAM Identity Center settings. The account ID, role name and start URL are required.
+func ssoSettingsFromEnv() (ssoSettings, error) {
+	settings := ssoSettings{
+		accountID: os.Getenv(SSOAccountIDEnvVar),
+		roleName:  os.Getenv(SSORoleNameEnvVar),
+		startURL:  os.Getenv(SSOStartURLEnvVar),
+		region:    os.Getenv(SSORegionEnvVar),
+		session:   os.Getenv(SSOSessionEnvVar),
+	}
+	if settings.accountID == "" || settings.roleName == "" || settings.startURL == "" {
+		return ssoSettings{}, errors.New(SSOAccountIDEnvVar + ", " + SSORoleNameEnvVar + " and " + SSOStartURLEnvVar + " must be set to use SSO")
+	}
+	if settings.region == "" {
+		settings.region = "us-east-1"
+	}
+	return settings, nil
+}
+
+// newSSOSecretsManagerClient creates a Secrets Manager client with cached, refreshing SSO credentials.
+func newSSOSecretsManagerClient(ctx context.Context) (SecretsManagerClient, error) {
+	settings, err := ssoSettingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := configLoader.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoadingAWSConfig, err)
+	}
+
+	ssoCfg := cfg.Copy()
+	ssoCfg.Region = settings.region
+	provider := ssocreds.New(sso.NewFromConfig(ssoCfg), settings.accountID, settings.roleName, settings.startURL,
+		func(o *ssocreds.Options) {
+			if settings.session == "" {
+				return
+			}
+			tokenPath, err := ssocreds.StandardCachedTokenFilepath(settings.session)
+			if err != nil {
+				slog.Warn("not refreshing the SSO token", "session", settings.session, "error", err)
+				return
+			}
+			o.SSOTokenProvider = ssocreds.NewSSOTokenProvider(ssooidc.NewFromConfig(ssoCfg), tokenPath)
+		})
+
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+	return secretsmanager.NewFromConfig(cfg), nil
+}
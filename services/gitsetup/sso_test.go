@@ -0,0 +1,173 @@
+package gitsetup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+// useSSOClient replaces the SSO Secrets Manager client and gives the test an empty secret cache.
+func useSSOClient(t *testing.T, client SecretsManagerClient, err error) {
+	originalClient := ssoSecretsManagerClient
+	ssoSecretsManagerClient = func() (SecretsManagerClient, error) { return client, err }
+
+	secretCache.Lock()
+	originalData := secretCache.data
+	secretCache.data = map[string]string{}
+	secretCache.Unlock()
+
+	t.Cleanup(func() {
+		ssoSecretsManagerClient = originalClient
+		secretCache.Lock()
+		secretCache.data = originalData
+		secretCache.Unlock()
+	})
+}
+
+func TestFetchSecretValueWithSSORefresh(t *testing.T) {
+	t.Setenv(UseSSOEnvVar, "true")
+	client := &mockSecretsManagerClient{secretString: `{"GITHUB_TOKEN": "sso_token"}`}
+	useSSOClient(t, client, nil)
+
+	value, err := FetchSecretValueWithSSORefresh("GITHUB_TOKEN")
+	if err != nil || value != "sso_token" {
+		t.Fatalf("expected sso_token, got %q, %v", value, err)
+	}
+
+	// The value is cached like any other secret
+	if _, err := FetchSecretValueWithSSORefresh("GITHUB_TOKEN"); err != nil || len(client.secretIDs) != 1 {
+		t.Errorf("expected one Secrets Manager call, got %d (%v)", len(client.secretIDs), err)
+	}
+
+	if _, err := FetchSecretValueWithSSORefresh("MISSING"); !errors.Is(err, ErrSecretKeyNotFound) {
+		t.Errorf("expected ErrSecretKeyNotFound, got %v", err)
+	}
+}
+
+func TestFetchSecretValueWithSSORefresh_FallsBack(t *testing.T) {
+	tests := []struct {
+		name      string
+		useSSO    string
+		client    SecretsManagerClient
+		clientErr error
+	}{
+		{name: "SSO Disabled", useSSO: "", client: &mockSecretsManagerClient{err: errors.New("SSO client must not be used")}},
+		{name: "SSO Client Error", useSSO: "true", clientErr: errors.New("AUTOBUILD_SSO_ACCOUNT_ID must be set")},
+		{name: "SSO Credentials Error", useSSO: "true", client: &mockSecretsManagerClient{err: errors.New("the SSO session has expired")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(UseSSOEnvVar, tt.useSSO)
+			useSSOClient(t, tt.client, tt.clientErr)
+
+			originalClient, originalLoader := secretsManagerClient, configLoader
+			defer func() { secretsManagerClient, configLoader = originalClient, originalLoader }()
+			secretsManagerClient = &mockSecretsManagerClient{secretString: `{"GITHUB_TOKEN": "default_token"}`}
+			configLoader = &mockConfigLoader{}
+
+			value, err := FetchSecretValueWithSSORefresh("GITHUB_TOKEN")
+			if err != nil || value != "default_token" {
+				t.Errorf("expected the default credentials to be used, got %q, %v", value, err)
+			}
+		})
+	}
+}
+
+func TestFetchSecretValueWithSSORefresh_BackgroundRefresh(t *testing.T) {
+	t.Setenv(UseSSOEnvVar, "true")
+	now := fixedTime(t)
+	useSecretCacheTTL(t, time.Hour)
+	client := &mockSecretsManagerClient{secretString: `{"GITHUB_TOKEN": "v1"}`}
+	useSSOClient(t, client, nil)
+
+	originalClient := secretsManagerClient
+	defer func() { secretsManagerClient = originalClient }()
+	defaultClient := &mockSecretsManagerClient{err: errors.New("default credentials must not be used")}
+	secretsManagerClient = defaultClient
+
+	if value, err := FetchSecretValueWithSSORefresh("GITHUB_TOKEN"); err != nil || value != "v1" {
+		t.Fatalf("expected v1, got %q, %v", value, err)
+	}
+
+	// The refresh within the last fifth of the TTL uses the SSO client that fetched the secret
+	client.secretString = `{"GITHUB_TOKEN": "v2"}`
+	*now = now.Add(55 * time.Minute)
+	if _, err := FetchSecretValueWithSSORefresh("GITHUB_TOKEN"); err != nil {
+		t.Fatalf("expected the cached value, got %v", err)
+	}
+	waitForSecretRefresh(t)
+
+	if value, err := FetchSecretValueWithSSORefresh("GITHUB_TOKEN"); err != nil || value != "v2" {
+		t.Errorf("expected the refreshed v2, got %q, %v", value, err)
+	}
+	if len(defaultClient.secretIDs) != 0 {
+		t.Errorf("expected the default client not to be used, got %d calls", len(defaultClient.secretIDs))
+	}
+}
+
+func TestFetchSecretToken_SSO(t *testing.T) {
+	t.Setenv(UseSSOEnvVar, "true")
+	t.Setenv(GitHubTokenEnvVar, "")
+	useSSOClient(t, &mockSecretsManagerClient{secretString: `{"GITHUB_TOKEN": "sso_token"}`}, nil)
+
+	sum := sha256.Sum256([]byte("sso_token"))
+	t.Setenv(GitHubTokenChecksumEnvVar, hex.EncodeToString(sum[:]))
+	if token, err := FetchSecretToken(); err != nil || token != "sso_token" {
+		t.Fatalf("expected sso_token, got %q, %v", token, err)
+	}
+
+	t.Setenv(GitHubTokenChecksumEnvVar, "0000")
+	if _, err := FetchSecretToken(); !errors.Is(err, ErrSecretTampered) {
+		t.Errorf("expected ErrSecretTampered, got %v", err)
+	}
+}
+
+func TestCachedSSOSecretsManagerClient_RetriesFailures(t *testing.T) {
+	originalLoader := configLoader
+	configLoader = &mockConfigLoader{}
+	t.Cleanup(func() {
+		configLoader = originalLoader
+		ssoClientCache.Lock()
+		ssoClientCache.client = nil
+		ssoClientCache.Unlock()
+	})
+
+	t.Setenv(SSOAccountIDEnvVar, "")
+	if _, err := cachedSSOSecretsManagerClient(); err == nil {
+		t.Fatal("expected an error without the SSO settings")
+	}
+
+	t.Setenv(SSOAccountIDEnvVar, "123456789012")
+	t.Setenv(SSORoleNameEnvVar, "AutoBuildGo")
+	t.Setenv(SSOStartURLEnvVar, "https://my-org.awsapps.com/start")
+	client, err := cachedSSOSecretsManagerClient()
+	if err != nil {
+		t.Fatalf("expected the client to be created once the settings are fixed, got %v", err)
+	}
+	if again, _ := cachedSSOSecretsManagerClient(); again != client {
+		t.Error("expected the client to be reused")
+	}
+}
+
+func TestSSOSettingsFromEnv(t *testing.T) {
+	t.Setenv(SSOAccountIDEnvVar, "123456789012")
+	t.Setenv(SSORoleNameEnvVar, "AutoBuildGo")
+	t.Setenv(SSOStartURLEnvVar, "https://my-org.awsapps.com/start")
+	t.Setenv(SSORegionEnvVar, "")
+
+	settings, err := ssoSettingsFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if settings.region != "us-east-1" {
+		t.Errorf("expected the region to default to us-east-1, got %q", settings.region)
+	}
+
+	t.Setenv(SSORoleNameEnvVar, "")
+	if _, err := ssoSettingsFromEnv(); err == nil {
+		t.Error("expected an error without a role name")
+	}
+}
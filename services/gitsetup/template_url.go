@@ -0,0 +1,90 @@
+package gitsetup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// templatePathPattern matches the generate endpoint path on api.github.com and, with the
+// /api/v3 prefix, on GitHub Enterprise Server.
+var templatePathPattern = regexp.MustCompile(`^(/api/v3)?/repos/([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+)/generate$`)
+
+// ValidateTemplateURL checks that templateURL is a GitHub template generate endpoint, i.e.
+// https://api.github.com/repos/{owner}/{repo}/generate or
+// https://{host}/api/v3/repos/{owner}/{repo}/generate for GitHub Enterprise.
+func ValidateTemplateURL(templateURL string) error {
+	_, _, err := parseTemplateURL(templateURL)
+	return err
+}
+
+// VerifyTemplateRepository checks that the repository behind templateURL exists and is marked as a template.
+func (client *GitClient) VerifyTemplateRepository(templateURL string) error {
+	repoURL, fullName, err := parseTemplateURL(templateURL)
+	if err != nil {
+		return err
+	}
+
+	token, err := client.refreshToken(context.Background())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, repoURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+
+	resp, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newGitHubError(resp.StatusCode, "failed to fetch template repository %s, status code: %d", fullName, resp.StatusCode)
+	}
+
+	var result struct {
+		IsTemplate bool `json:"is_template"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+
+	if !result.IsTemplate {
+		return fmt.Errorf("repository %s is not a template repository", fullName)
+	}
+	return nil
+}
+
+// parseTemplateURL validates templateURL and returns the API URL and full name of the template repository.
+func parseTemplateURL(templateURL string) (string, string, error) {
+	parsed, err := url.Parse(templateURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	if parsed.Scheme != "https" {
+		return "", "", fmt.Errorf("invalid template URL %q: scheme must be https", templateURL)
+	}
+
+	matches := templatePathPattern.FindStringSubmatch(parsed.Path)
+	if matches == nil {
+		return "", "", fmt.Errorf("invalid template URL %q: path must be /repos/{owner}/{repo}/generate", templateURL)
+	}
+
+	// api.github.com serves the API from the root, GitHub Enterprise from /api/v3
+	enterprise := matches[1] != ""
+	if enterprise == (parsed.Host == "api.github.com") {
+		return "", "", fmt.Errorf("invalid template URL %q: host does not match the API path", templateURL)
+	}
+
+	repoURL := strings.TrimSuffix(parsed.Scheme+"://"+parsed.Host+parsed.Path, "/generate")
+	return repoURL, matches[2] + "/" + matches[3], nil
+}
@@ -0,0 +1,82 @@
+package gitsetup
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestValidateTemplateURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		expectedErr bool
+	}{
+		{name: "GitHub", url: "https://api.github.com/repos/template-owner/template-repo/generate"},
+		{name: "GitHub Enterprise", url: "https://github.example.com/api/v3/repos/template-owner/template-repo/generate"},
+		{name: "Missing Protocol Scheme", url: ":invalid-url", expectedErr: true},
+		{name: "Plain HTTP", url: "http://api.github.com/repos/template-owner/template-repo/generate", expectedErr: true},
+		{name: "Missing Generate Suffix", url: "https://api.github.com/repos/template-owner/template-repo", expectedErr: true},
+		{name: "Web URL", url: "https://github.com/template-owner/template-repo", expectedErr: true},
+		{name: "Enterprise Path On GitHub", url: "https://api.github.com/api/v3/repos/template-owner/template-repo/generate", expectedErr: true},
+		{name: "GitHub Path On Enterprise", url: "https://github.example.com/repos/template-owner/template-repo/generate", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTemplateURL(tt.url)
+			if (err != nil) != tt.expectedErr {
+				t.Errorf("expected error: %v, got: %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyTemplateRepository(t *testing.T) {
+	const templateURL = "https://api.github.com/repos/template-owner/template-repo/generate"
+
+	tests := []struct {
+		name               string
+		status             int
+		body               string
+		expectedErrMessage string
+	}{
+		{name: "Template Repository", status: http.StatusOK, body: `{"is_template": true}`},
+		{
+			name:               "Not A Template",
+			status:             http.StatusOK,
+			body:               `{"is_template": false}`,
+			expectedErrMessage: "repository template-owner/template-repo is not a template repository",
+		},
+		{
+			name:               "Repository Not Found",
+			status:             http.StatusNotFound,
+			expectedErrMessage: "failed to fetch template repository template-owner/template-repo, status code: 404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &GitClient{
+				HTTPClient: &mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						if req.URL.String() != "https://api.github.com/repos/template-owner/template-repo" {
+							t.Errorf("unexpected request URL: %s", req.URL)
+						}
+						return &http.Response{StatusCode: tt.status, Body: io.NopCloser(bytes.NewBufferString(tt.body))}, nil
+					},
+				},
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+
+			err := client.VerifyTemplateRepository(templateURL)
+			if (err != nil) != (tt.expectedErrMessage != "") {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedErrMessage != "", err)
+			}
+			if err != nil && err.Error() != tt.expectedErrMessage {
+				t.Errorf("expected error message: %s, got: %s", tt.expectedErrMessage, err.Error())
+			}
+		})
+	}
+}
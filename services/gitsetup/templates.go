@@ -0,0 +1,55 @@
+package gitsetup
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// IncludeURLsHeader makes ListTemplatesHandler return the template URLs, which may be internal.
+const IncludeURLsHeader = "X-Include-URLs"
+
+// TemplateInfo describes a template repository of the template registry.
+type TemplateInfo struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// ListTemplatesHandler lists the templates of the TEMPLATE_REGISTRY secret, a JSON object
+// mapping template names to generate endpoint URLs, sorted by name.
+func ListTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("ListTemplatesHandler invoked")
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	registry, err := FetchSecretValue("TEMPLATE_REGISTRY")
+	if err != nil {
+		http.Error(w, "Failed to fetch template registry: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var urls map[string]string
+	if err := json.Unmarshal([]byte(registry), &urls); err != nil {
+		http.Error(w, "Failed to parse template registry: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	includeURLs := strings.EqualFold(r.Header.Get(IncludeURLsHeader), "true")
+	templates := make([]TemplateInfo, 0, len(urls))
+	for name, url := range urls {
+		template := TemplateInfo{Name: name}
+		if includeURLs {
+			template.URL = url
+		}
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(templates)
+}
@@ -0,0 +1,80 @@
+package gitsetup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestListTemplatesHandler(t *testing.T) {
+	seedSecretCache(t)
+	secretCache.Lock()
+	secretCache.data["github_token/TEMPLATE_REGISTRY"] = `{
+		"go-service": "https://api.github.com/repos/template-owner/go-service/generate",
+		"go-cli": "https://api.github.com/repos/template-owner/go-cli/generate"
+	}`
+	secretCache.Unlock()
+
+	tests := []struct {
+		name           string
+		method         string
+		includeURLs    string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Names Only",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"name":"go-cli"},{"name":"go-service"}]`,
+		},
+		{
+			name:           "Include URLs",
+			method:         http.MethodGet,
+			includeURLs:    "true",
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"name":"go-cli","url":"https://api.github.com/repos/template-owner/go-cli/generate"},` +
+				`{"name":"go-service","url":"https://api.github.com/repos/template-owner/go-service/generate"}]`,
+		},
+		{
+			name:           "Invalid Method",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   "Method not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/templates", nil)
+			if tt.includeURLs != "" {
+				req.Header.Set(IncludeURLsHeader, tt.includeURLs)
+			}
+			w := httptest.NewRecorder()
+
+			ListTemplatesHandler(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestListTemplatesHandler_InvalidRegistry(t *testing.T) {
+	seedSecretCache(t)
+	secretCache.Lock()
+	secretCache.data["github_token/TEMPLATE_REGISTRY"] = "not json"
+	secretCache.Unlock()
+
+	w := httptest.NewRecorder()
+	ListTemplatesHandler(w, httptest.NewRequest(http.MethodGet, "/templates", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
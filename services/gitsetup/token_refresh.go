@@ -0,0 +1,52 @@
+package gitsetup
+
+import (
+	"context"
+	"time"
+)
+
+// tokenRefreshWindow is how long before it expires a cached token is replaced, so a request
+// started with it does not fail half way.
+const tokenRefreshWindow = 5 * time.Minute
+
+// WithTokenLifetime caches the token returned by FetchSecretFunc for d, e.g. an hour for GitHub App
+// installation tokens. By default a token is fetched for every call.
+func WithTokenLifetime(d time.Duration) GitClientOption {
+	return func(o *gitClientOptions) {
+		o.tokenLifetime = d
+	}
+}
+
+// clearToken drops the cached token, so the next call fetches a new one.
+func (client *GitClient) clearToken() {
+	client.tokenMu.Lock()
+	defer client.tokenMu.Unlock()
+	client.token = ""
+	client.tokenExpiresAt = time.Time{}
+}
+
+// refreshToken returns the cached token, calling FetchSecretFunc again when no token is cached or
+// the cached one expires within tokenRefreshWindow. Without a TokenLifetime every call fetches a token.
+func (client *GitClient) refreshToken(ctx context.Context) (string, error) {
+	if client.TokenLifetime <= 0 {
+		return client.FetchSecretFunc()
+	}
+
+	client.tokenMu.Lock()
+	defer client.tokenMu.Unlock()
+
+	if client.token != "" && timeNow().Add(tokenRefreshWindow).Before(client.tokenExpiresAt) {
+		return client.token, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	token, err := client.FetchSecretFunc()
+	if err != nil {
+		return "", err
+	}
+	client.token = token
+	client.tokenExpiresAt = timeNow().Add(client.TokenLifetime)
+	return token, nil
+}
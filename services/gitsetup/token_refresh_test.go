@@ -0,0 +1,114 @@
+package gitsetup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// countingTokenFetcher returns a new token, "token-1", "token-2", ..., on every call.
+func countingTokenFetcher(calls *int) func() (string, error) {
+	return func() (string, error) {
+		*calls++
+		return fmt.Sprintf("token-%d", *calls), nil
+	}
+}
+
+func TestGitClient_RefreshToken(t *testing.T) {
+	now := fixedTime(t)
+	var calls int
+	client := NewGitClientWithOptions(WithTokenLifetime(time.Hour))
+	client.FetchSecretFunc = countingTokenFetcher(&calls)
+
+	steps := []struct {
+		elapsed       time.Duration
+		expectedToken string
+	}{
+		{elapsed: 0, expectedToken: "token-1"},
+		{elapsed: 30 * time.Minute, expectedToken: "token-1"},
+		// Within 5 minutes of the expiry the token is replaced
+		{elapsed: 25 * time.Minute, expectedToken: "token-2"},
+		{elapsed: time.Minute, expectedToken: "token-2"},
+	}
+	for i, step := range steps {
+		*now = now.Add(step.elapsed)
+		token, err := client.refreshToken(context.Background())
+		if err != nil {
+			t.Fatalf("step %d: expected no error, got: %v", i, err)
+		}
+		if token != step.expectedToken {
+			t.Errorf("step %d: expected %s, got %s", i, step.expectedToken, token)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 fetches, got %d", calls)
+	}
+}
+
+func TestGitClient_RefreshToken_NoLifetime(t *testing.T) {
+	var calls int
+	client := &GitClient{FetchSecretFunc: countingTokenFetcher(&calls)}
+
+	for i := 0; i < 3; i++ {
+		client.refreshToken(context.Background())
+	}
+	if calls != 3 {
+		t.Errorf("expected a fetch per call without a token lifetime, got %d", calls)
+	}
+}
+
+func TestGitClient_RefreshToken_Errors(t *testing.T) {
+	fixedTime(t)
+	client := &GitClient{
+		FetchSecretFunc: func() (string, error) { return "", errors.New("secret unavailable") },
+		TokenLifetime:   time.Hour,
+	}
+
+	if _, err := client.refreshToken(context.Background()); err == nil || err.Error() != "secret unavailable" {
+		t.Errorf("expected the fetch error, got: %v", err)
+	}
+	if client.token != "" {
+		t.Errorf("expected a failed fetch not to be cached, got %q", client.token)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int
+	client.FetchSecretFunc = countingTokenFetcher(&calls)
+	if _, err := client.refreshToken(ctx); !errors.Is(err, context.Canceled) || calls != 0 {
+		t.Errorf("expected context.Canceled without a fetch, got %v after %d fetches", err, calls)
+	}
+}
+
+func TestServerGitClient(t *testing.T) {
+	client := serverGitClient()
+	if serverGitClient() != client {
+		t.Error("expected the handlers to share one GitClient")
+	}
+	if client.TokenLifetime != ServerTokenLifetime {
+		t.Errorf("expected a token lifetime of %v, got %v", ServerTokenLifetime, client.TokenLifetime)
+	}
+
+	originalFetch := client.FetchSecretFunc
+	defer func() {
+		client.FetchSecretFunc = originalFetch
+		client.clearToken()
+	}()
+	var calls int
+	client.FetchSecretFunc = countingTokenFetcher(&calls)
+
+	for i := 0; i < 2; i++ {
+		client.refreshToken(context.Background())
+	}
+	if calls != 1 {
+		t.Errorf("expected the token to be reused, got %d fetches", calls)
+	}
+
+	// A reload drops the cached token
+	reloadConfig()
+	if token, _ := client.refreshToken(context.Background()); token != "token-2" {
+		t.Errorf("expected a new token after the reload, got %s", token)
+	}
+}
@@ -0,0 +1,55 @@
+package gitsetup
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Connection pool defaults of the transport shared by every GitClient.
+const (
+	DefaultMaxIdleConnsPerHost = 32
+	DefaultIdleConnTimeout     = 90 * time.Second
+
+	MaxIdleConnsPerHostEnvVar = "AUTOBUILD_HTTP_MAX_IDLE_CONNS_PER_HOST"
+	IdleConnTimeoutEnvVar     = "AUTOBUILD_HTTP_IDLE_CONN_TIMEOUT"
+)
+
+// sharedTransport pools connections to GitHub across all GitClient instances.
+var sharedTransport *http.Transport
+
+func init() {
+	sharedTransport = newSharedTransport()
+}
+
+// newSharedTransport clones http.DefaultTransport with the pool size and idle timeout
+// from the environment, falling back to the defaults for unset or invalid values.
+func newSharedTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	if value := os.Getenv(MaxIdleConnsPerHostEnvVar); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			transport.MaxIdleConnsPerHost = n
+		} else {
+			slog.Warn("ignoring invalid connection pool size", "env", MaxIdleConnsPerHostEnvVar, "value", value)
+		}
+	}
+	// Keep the overall idle pool large enough for the per host limit
+	if transport.MaxIdleConns < transport.MaxIdleConnsPerHost {
+		transport.MaxIdleConns = transport.MaxIdleConnsPerHost
+	}
+
+	transport.IdleConnTimeout = DefaultIdleConnTimeout
+	if value := os.Getenv(IdleConnTimeoutEnvVar); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			transport.IdleConnTimeout = d
+		} else {
+			slog.Warn("ignoring invalid idle connection timeout", "env", IdleConnTimeoutEnvVar, "value", value)
+		}
+	}
+
+	return transport
+}
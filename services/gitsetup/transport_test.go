@@ -0,0 +1,38 @@
+package gitsetup
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSharedTransport(t *testing.T) {
+	tests := []struct {
+		name                string
+		maxIdleConnsPerHost string
+		idleConnTimeout     string
+		expectedMaxIdle     int
+		expectedTimeout     time.Duration
+	}{
+		{name: "Defaults", expectedMaxIdle: DefaultMaxIdleConnsPerHost, expectedTimeout: DefaultIdleConnTimeout},
+		{name: "From Environment", maxIdleConnsPerHost: "200", idleConnTimeout: "30s", expectedMaxIdle: 200, expectedTimeout: 30 * time.Second},
+		{name: "Invalid Values", maxIdleConnsPerHost: "many", idleConnTimeout: "-1s", expectedMaxIdle: DefaultMaxIdleConnsPerHost, expectedTimeout: DefaultIdleConnTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(MaxIdleConnsPerHostEnvVar, tt.maxIdleConnsPerHost)
+			t.Setenv(IdleConnTimeoutEnvVar, tt.idleConnTimeout)
+
+			transport := newSharedTransport()
+			if transport.MaxIdleConnsPerHost != tt.expectedMaxIdle {
+				t.Errorf("expected MaxIdleConnsPerHost %d, got %d", tt.expectedMaxIdle, transport.MaxIdleConnsPerHost)
+			}
+			if transport.MaxIdleConns < transport.MaxIdleConnsPerHost {
+				t.Errorf("expected MaxIdleConns of at least %d, got %d", transport.MaxIdleConnsPerHost, transport.MaxIdleConns)
+			}
+			if transport.IdleConnTimeout != tt.expectedTimeout {
+				t.Errorf("expected IdleConnTimeout %v, got %v", tt.expectedTimeout, transport.IdleConnTimeout)
+			}
+		})
+	}
+}
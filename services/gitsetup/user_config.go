@@ -0,0 +1,112 @@
+package gitsetup
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserConfigFile is the per-user config file read by LoadUserConfig, relative to the home directory.
+const UserConfigFile = ".autobuildgo/config.yaml"
+
+// UserConfig holds the defaults a user can keep in ~/.autobuildgo/config.yaml:
+//
+//	template_url: https://api.github.com/repos/my-org/service-template/generate
+//	github_host: github.mycompany.com
+//	server:
+//	  cors_origins: [https://ui.mycompany.com]
+//	  rate_limit: 10
+//
+// There is no clone_method setting. The repository is cloned into a temporary directory that is
+// removed after the push, and git authenticates with the token through a header that is neither in
+// the remote URL nor in .git/config, so cloning over SSH would only add the need for an SSH key.
+// A clone_method key is logged and ignored.
+type UserConfig struct {
+	// TemplateURL is used by RepoConfig instead of the TEMPLATE_URL secret.
+	TemplateURL string `yaml:"template_url"`
+	// GitHubHost is the GitHub Enterprise host, or github.com. Empty uses github.com.
+	GitHubHost string `yaml:"github_host"`
+	// Server holds the web server settings, which are read again when the server receives SIGHUP.
+	Server ServerUserConfig `yaml:"server"`
+}
+
+// ServerUserConfig holds the web server settings of the UserConfigFile. A setting that is not
+// set falls back to its environment variable.
+type ServerUserConfig struct {
+	// CORSOrigins replaces AUTOBUILD_CORS_ORIGINS.
+	CORSOrigins []string `yaml:"cors_origins"`
+	// RateLimit replaces AUTOBUILD_RATE_LIMIT, in requests per second.
+	RateLimit float64 `yaml:"rate_limit"`
+	// RateLimitPerIP replaces AUTOBUILD_RATE_LIMIT_PER_IP, in requests per second.
+	RateLimitPerIP float64 `yaml:"rate_limit_per_ip"`
+}
+
+// userHomeDir is used by LoadUserConfig and can be replaced in tests.
+var userHomeDir = os.UserHomeDir
+
+// LoadUserConfig reads UserConfigFile from the user's home directory. A missing file, or no
+// home directory, gives the zero UserConfig.
+func LoadUserConfig() (UserConfig, error) {
+	home, err := userHomeDir()
+	if err != nil {
+		return UserConfig{}, nil
+	}
+
+	data, err := readFile(filepath.Join(home, UserConfigFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return UserConfig{}, nil
+	}
+	if err != nil {
+		return UserConfig{}, fmt.Errorf("%w: %v", ErrReadingUserConfig, err)
+	}
+
+	var userConfig UserConfig
+	if err := yaml.Unmarshal(data, &userConfig); err != nil {
+		return UserConfig{}, fmt.Errorf("%w: %v", ErrReadingUserConfig, err)
+	}
+	var unsupported struct {
+		CloneMethod string `yaml:"clone_method"`
+	}
+	if yaml.Unmarshal(data, &unsupported) == nil && unsupported.CloneMethod != "" {
+		slog.Warn("ignoring unsupported key", "file", UserConfigFile, "key", "clone_method")
+	}
+	return userConfig, nil
+}
+
+// RepoConfig returns DefaultRepoConfig with TemplateURL, when set, instead of the TEMPLATE_URL secret.
+func (u UserConfig) RepoConfig(repoName, description string) (RepoConfig, error) {
+	if u.TemplateURL == "" {
+		return DefaultRepoConfig(repoName, description)
+	}
+	return newRepoConfig(repoName, description, u.TemplateURL)
+}
+
+// GitClientOptions returns the options that point a GitClient at GitHubHost.
+func (u UserConfig) GitClientOptions() []GitClientOption {
+	_, apiURL := gitHubURLs(u.GitHubHost)
+	if apiURL == gitHubAPIURL {
+		return nil
+	}
+	return []GitClientOption{WithBaseURL(apiURL)}
+}
+
+// gitHubURLs returns the web and API base URLs of a GitHub host, e.g. a GitHub Enterprise host.
+// Empty uses github.com. A host with a scheme is used as the API URL as is.
+func gitHubURLs(host string) (webURL, apiURL string) {
+	host = strings.TrimSuffix(host, "/")
+	switch host {
+	case "", "github.com", "api.github.com":
+		return gitHubWebURL, gitHubAPIURL
+	}
+	if u, err := url.Parse(host); err == nil && u.Scheme != "" && u.Host != "" {
+		return u.Scheme + "://" + u.Host, host
+	}
+	// GitHub Enterprise Server serves the REST API under /api/v3
+	return "https://" + host, "https://" + host + "/api/v3"
+}
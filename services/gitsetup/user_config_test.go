@@ -0,0 +1,165 @@
+package gitsetup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// useHomeDir makes LoadUserConfig read the UserConfigFile from a temporary home directory
+// holding content. Empty content leaves the file out.
+func useHomeDir(t *testing.T, content string) {
+	home := t.TempDir()
+	if content != "" {
+		path := filepath.Join(home, UserConfigFile)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	originalHomeDir := userHomeDir
+	userHomeDir = func() (string, error) { return home, nil }
+	t.Cleanup(func() { userHomeDir = originalHomeDir })
+}
+
+func TestLoadUserConfig(t *testing.T) {
+	useHomeDir(t, "template_url: https://api.github.com/repos/org/template/generate\ngithub_host: github.example.com\n"+
+		"server:\n  cors_origins: [https://ui.example.com]\n  rate_limit: 10\n  rate_limit_per_ip: 0.5\n")
+
+	userConfig, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	expected := UserConfig{
+		TemplateURL: "https://api.github.com/repos/org/template/generate",
+		GitHubHost:  "github.example.com",
+		Server: ServerUserConfig{
+			CORSOrigins:    []string{"https://ui.example.com"},
+			RateLimit:      10,
+			RateLimitPerIP: 0.5,
+		},
+	}
+	if !reflect.DeepEqual(userConfig, expected) {
+		t.Errorf("expected %+v, got %+v", expected, userConfig)
+	}
+}
+
+func TestLoadUserConfig_CloneMethodIgnored(t *testing.T) {
+	useHomeDir(t, "github_host: github.example.com\nclone_method: ssh\n")
+
+	userConfig, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if expected := (UserConfig{GitHubHost: "github.example.com"}); !reflect.DeepEqual(userConfig, expected) {
+		t.Errorf("expected %+v, got %+v", expected, userConfig)
+	}
+}
+
+func TestLoadUserConfig_Missing(t *testing.T) {
+	useHomeDir(t, "")
+
+	userConfig, err := LoadUserConfig()
+	if err != nil || !reflect.DeepEqual(userConfig, UserConfig{}) {
+		t.Errorf("expected the zero config without an error, got %+v, %v", userConfig, err)
+	}
+
+	userHomeDir = func() (string, error) { return "", errors.New("$HOME is not defined") }
+	userConfig, err = LoadUserConfig()
+	if err != nil || !reflect.DeepEqual(userConfig, UserConfig{}) {
+		t.Errorf("expected the zero config without a home directory, got %+v, %v", userConfig, err)
+	}
+}
+
+func TestLoadUserConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "Invalid YAML", content: "template_url: [unclosed"},
+		{name: "Wrong Type", content: "github_host: [github.example.com]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useHomeDir(t, tt.content)
+			if _, err := LoadUserConfig(); !errors.Is(err, ErrReadingUserConfig) {
+				t.Errorf("expected ErrReadingUserConfig, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUserConfig_GitClientOptions(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{host: "", expected: gitHubAPIURL},
+		{host: "github.com", expected: gitHubAPIURL},
+		{host: "github.example.com", expected: "https://github.example.com/api/v3"},
+		{host: "http://localhost:8080/", expected: "http://localhost:8080"},
+	}
+
+	for _, tt := range tests {
+		client := NewGitClientWithOptions(UserConfig{GitHubHost: tt.host}.GitClientOptions()...)
+		if client.BaseURL != tt.expected {
+			t.Errorf("host %q: expected base URL %q, got %q", tt.host, tt.expected, client.BaseURL)
+		}
+	}
+}
+
+func TestUserConfig_RepoConfig(t *testing.T) {
+	seedSecretCache(t)
+
+	userConfig := UserConfig{TemplateURL: "https://api.github.com/repos/org/template/generate"}
+	config, err := userConfig.RepoConfig("test-repo", "")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if config.TemplateURL != "https://api.github.com/repos/org/template/generate" {
+		t.Errorf("expected the template URL of the user config, got %q", config.TemplateURL)
+	}
+
+	if _, err := userConfig.RepoConfig("", ""); !errors.Is(err, ErrEmptyRepoName) {
+		t.Errorf("expected ErrEmptyRepoName, got %v", err)
+	}
+}
+
+func TestDefaultRepoConfig_IgnoresUserConfig(t *testing.T) {
+	// DefaultRepoConfig is used by the web server, which must not depend on the config file of its user
+	useHomeDir(t, "template_url: https://api.github.com/repos/org/template/generate\n")
+	seedSecretCache(t)
+
+	config, err := DefaultRepoConfig("test-repo", "")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if config.TemplateURL == "https://api.github.com/repos/org/template/generate" {
+		t.Errorf("expected the TEMPLATE_URL secret to be used, got %q", config.TemplateURL)
+	}
+}
+
+func TestGitHubURLs(t *testing.T) {
+	tests := []struct {
+		host        string
+		expectedWeb string
+		expectedAPI string
+	}{
+		{host: "", expectedWeb: "https://github.com", expectedAPI: "https://api.github.com"},
+		{host: "github.example.com", expectedWeb: "https://github.example.com", expectedAPI: "https://github.example.com/api/v3"},
+		{host: "http://localhost:8080/", expectedWeb: "http://localhost:8080", expectedAPI: "http://localhost:8080"},
+	}
+
+	for _, tt := range tests {
+		webURL, apiURL := gitHubURLs(tt.host)
+		if webURL != tt.expectedWeb || apiURL != tt.expectedAPI {
+			t.Errorf("host %q: expected %q and %q, got %q and %q", tt.host, tt.expectedWeb, tt.expectedAPI, webURL, apiURL)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package gitsetup
+
+import (
+	"crypto/sha256"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Username cache settings. A TTL of 0 disables the cache.
+const (
+	DefaultUsernameCacheTTL = time.Hour
+	UsernameCacheTTLEnvVar  = "AUTOBUILD_USERNAME_CACHE_TTL"
+)
+
+type usernameCacheEntry struct {
+	username string
+	expires  time.Time
+}
+
+// usernameCache holds the GitHub username per token. Entries are keyed by the SHA-256
+// of the token so raw tokens are not kept in memory any longer than needed.
+var usernameCache = struct {
+	sync.Mutex
+	ttl  time.Duration
+	data map[[sha256.Size]byte]usernameCacheEntry
+}{ttl: usernameCacheTTLFromEnv(), data: make(map[[sha256.Size]byte]usernameCacheEntry)}
+
+// usernameCacheTTLFromEnv returns the username cache TTL from the environment,
+// falling back to DefaultUsernameCacheTTL for unset or invalid values.
+func usernameCacheTTLFromEnv() time.Duration {
+	value := os.Getenv(UsernameCacheTTLEnvVar)
+	if value == "" {
+		return DefaultUsernameCacheTTL
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		slog.Warn("ignoring invalid username cache TTL", "env", UsernameCacheTTLEnvVar, "value", value)
+		return DefaultUsernameCacheTTL
+	}
+	return ttl
+}
+
+// cachedGitHubUsername returns the username cached for token, calling fetch on a miss or
+// an expired entry. Errors are not cached.
+func cachedGitHubUsername(token string, fetch func(token string) (string, error)) (string, error) {
+	key := sha256.Sum256([]byte(token))
+
+	usernameCache.Lock()
+	ttl := usernameCache.ttl
+	entry, found := usernameCache.data[key]
+	usernameCache.Unlock()
+	if found && timeNow().Before(entry.expires) {
+		return entry.username, nil
+	}
+
+	username, err := fetch(token)
+	if err != nil || ttl == 0 {
+		return username, err
+	}
+
+	usernameCache.Lock()
+	usernameCache.data[key] = usernameCacheEntry{username: username, expires: timeNow().Add(ttl)}
+	usernameCache.Unlock()
+	return username, nil
+}
@@ -0,0 +1,118 @@
+package gitsetup
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+	"time"
+)
+
+// useUsernameCache gives the test an empty username cache with the given TTL and a fixed clock.
+func useUsernameCache(t *testing.T, ttl time.Duration) *time.Time {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	usernameCache.Lock()
+	originalTTL, originalData := usernameCache.ttl, usernameCache.data
+	usernameCache.ttl = ttl
+	usernameCache.data = make(map[[sha256.Size]byte]usernameCacheEntry)
+	usernameCache.Unlock()
+	originalTimeNow := timeNow
+	timeNow = func() time.Time { return now }
+
+	t.Cleanup(func() {
+		usernameCache.Lock()
+		usernameCache.ttl, usernameCache.data = originalTTL, originalData
+		usernameCache.Unlock()
+		timeNow = originalTimeNow
+	})
+	return &now
+}
+
+func TestCachedGitHubUsername(t *testing.T) {
+	now := useUsernameCache(t, time.Hour)
+
+	calls := 0
+	fetch := func(token string) (string, error) {
+		calls++
+		return "user-" + token, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		username, err := cachedGitHubUsername("token-a", fetch)
+		if err != nil || username != "user-token-a" {
+			t.Fatalf("expected user-token-a, got %q, %v", username, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 fetch for a cached token, got %d", calls)
+	}
+
+	if username, _ := cachedGitHubUsername("token-b", fetch); username != "user-token-b" || calls != 2 {
+		t.Errorf("expected another token to be fetched, got %q after %d fetches", username, calls)
+	}
+
+	*now = now.Add(time.Hour)
+	if _, err := cachedGitHubUsername("token-a", fetch); err != nil || calls != 3 {
+		t.Errorf("expected an expired entry to be fetched again, got %d fetches, %v", calls, err)
+	}
+
+	if _, ok := usernameCache.data[sha256.Sum256([]byte("token-a"))]; !ok {
+		t.Error("expected the entry to be keyed by the SHA-256 of the token")
+	}
+}
+
+func TestCachedGitHubUsername_ErrorsNotCached(t *testing.T) {
+	useUsernameCache(t, time.Hour)
+
+	calls := 0
+	fetch := func(token string) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errors.New("bad credentials")
+		}
+		return "octocat", nil
+	}
+
+	if _, err := cachedGitHubUsername("token", fetch); err == nil {
+		t.Fatal("expected the fetch error to be returned")
+	}
+	if username, err := cachedGitHubUsername("token", fetch); err != nil || username != "octocat" {
+		t.Errorf("expected octocat after a failed fetch, got %q, %v", username, err)
+	}
+}
+
+func TestCachedGitHubUsername_Disabled(t *testing.T) {
+	useUsernameCache(t, 0)
+
+	calls := 0
+	fetch := func(token string) (string, error) {
+		calls++
+		return "octocat", nil
+	}
+
+	cachedGitHubUsername("token", fetch)
+	cachedGitHubUsername("token", fetch)
+	if calls != 2 {
+		t.Errorf("expected every call to fetch with a TTL of 0, got %d fetches", calls)
+	}
+}
+
+func TestUsernameCacheTTLFromEnv(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{value: "", expected: DefaultUsernameCacheTTL},
+		{value: "10m", expected: 10 * time.Minute},
+		{value: "0", expected: 0},
+		{value: "-1m", expected: DefaultUsernameCacheTTL},
+		{value: "soon", expected: DefaultUsernameCacheTTL},
+	}
+
+	for _, tt := range tests {
+		t.Setenv(UsernameCacheTTLEnvVar, tt.value)
+		if got := usernameCacheTTLFromEnv(); got != tt.expected {
+			t.Errorf("%q: expected %v, got %v", tt.value, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package gitsetup
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// checkOK is reported for a VerifyHandler check that passed.
+const checkOK = "ok"
+
+// credentialsExpiryWarning is how close to expiring AWS credentials are when VerifyHandler warns about them.
+const credentialsExpiryWarning = 15 * time.Minute
+
+// VerifyResponse is returned by VerifyHandler with "ok" or the error message per check.
+// Warnings report problems that do not fail a check yet, such as AWS credentials about to expire.
+type VerifyResponse struct {
+	Checks   map[string]string `json:"checks"`
+	Warnings map[string]string `json:"warnings,omitempty"`
+}
+
+// VerifyHandler checks the prerequisites of CreateRepoHandler without creating anything:
+// AWS credentials, ECR connectivity, the GitHub token and the template repository.
+// It answers 200 OK when every check passes and 207 Multi-Status otherwise.
+func VerifyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("VerifyHandler invoked")
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	warnings := make(map[string]string)
+	checks := map[string]func() error{
+		"aws_creds": func() error {
+			creds, err := GetAWSCredentialsFunc(ctx)
+			if err != nil {
+				return err
+			}
+			if creds.WillExpireSoon(credentialsExpiryWarning) {
+				warnings["aws_creds"] = fmt.Sprintf("credentials expire at %s", creds.ExpiresAt.UTC().Format(time.RFC3339))
+				log.Printf("Verify warning: AWS %s", warnings["aws_creds"])
+			}
+			return nil
+		},
+		"ecr": func() error {
+			ecrClient, err := CreateECRClientFunc()
+			if err != nil {
+				return err
+			}
+			return PingECRFunc(ctx, ecrClient)
+		},
+		"github_token": func() error {
+			token, err := gitHubService.FetchSecretToken()
+			if err != nil {
+				return err
+			}
+			_, err = gitHubService.FetchGitHubUsername(ctx, token)
+			return err
+		},
+		"template_url": func() error {
+			templateURL, err := FetchTemplateURL()
+			if err != nil {
+				return err
+			}
+			return NewGitClientFunc().VerifyTemplateRepository(templateURL)
+		},
+	}
+
+	response := VerifyResponse{Checks: make(map[string]string, len(checks))}
+	status := http.StatusOK
+	for name, check := range checks {
+		if err := check(); err != nil {
+			log.Printf("Verify check %s failed: %v", name, err)
+			response.Checks[name] = err.Error()
+			status = http.StatusMultiStatus
+			continue
+		}
+		response.Checks[name] = checkOK
+	}
+	if len(warnings) > 0 {
+		response.Warnings = warnings
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
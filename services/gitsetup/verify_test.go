@@ -0,0 +1,170 @@
+package gitsetup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	localECR "github.com/lep13/AutoBuildGo/services/ecr"
+)
+
+func TestVerifyHandler(t *testing.T) {
+	seedSecretCache(t)
+
+	originalService := gitHubService
+	originalCredentials, originalPing := GetAWSCredentialsFunc, PingECRFunc
+	defer func() {
+		gitHubService = originalService
+		GetAWSCredentialsFunc, PingECRFunc = originalCredentials, originalPing
+	}()
+
+	templateClient := func(isTemplate bool) func() *GitClient {
+		return func() *GitClient {
+			return &GitClient{
+				HTTPClient: &mockHTTPClient{
+					doFunc: func(req *http.Request) (*http.Response, error) {
+						body, _ := json.Marshal(map[string]bool{"is_template": isTemplate})
+						return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBuffer(body))}, nil
+					},
+				},
+				FetchSecretFunc: mockFetchSecretFunc,
+			}
+		}
+	}
+
+	tests := []struct {
+		name           string
+		credentialsErr error
+		createECRFunc  func() (localECR.ECRClientInterface, error)
+		pingErr        error
+		gitHubService  GitHubService
+		isTemplate     bool
+		expectedStatus int
+		expectedChecks map[string]string
+	}{
+		{
+			name:           "All Checks Pass",
+			createECRFunc:  mockCreateECRClient,
+			gitHubService:  mockGitHubService{token: "mock_token", username: "octocat"},
+			isTemplate:     true,
+			expectedStatus: http.StatusOK,
+			expectedChecks: map[string]string{"aws_creds": "ok", "ecr": "ok", "github_token": "ok", "template_url": "ok"},
+		},
+		{
+			name:           "Some Checks Fail",
+			credentialsErr: errors.New("no AWS credentials configured"),
+			createECRFunc:  mockCreateECRClient,
+			pingErr:        errors.New("failed to reach ECR: AccessDeniedException"),
+			gitHubService:  mockGitHubService{token: "mock_token", usernameErr: errors.New("bad credentials")},
+			expectedStatus: http.StatusMultiStatus,
+			expectedChecks: map[string]string{
+				"aws_creds":    "no AWS credentials configured",
+				"ecr":          "failed to reach ECR: AccessDeniedException",
+				"github_token": "bad credentials",
+				"template_url": "repository template-owner/template-repo is not a template repository",
+			},
+		},
+		{
+			name:           "ECR Client Error",
+			createECRFunc:  mockCreateECRClientError,
+			gitHubService:  mockGitHubService{token: "mock_token", username: "octocat"},
+			isTemplate:     true,
+			expectedStatus: http.StatusMultiStatus,
+			expectedChecks: map[string]string{"aws_creds": "ok", "ecr": "mock error creating ECR client", "github_token": "ok", "template_url": "ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			GetAWSCredentialsFunc = func(ctx context.Context) (localECR.AWSCredentials, error) {
+				return localECR.AWSCredentials{}, tt.credentialsErr
+			}
+			CreateECRClientFunc = tt.createECRFunc
+			PingECRFunc = func(ctx context.Context, client localECR.ECRClientInterface) error { return tt.pingErr }
+			gitHubService = tt.gitHubService
+			NewGitClientFunc = templateClient(tt.isTemplate)
+
+			w := httptest.NewRecorder()
+			VerifyHandler(w, httptest.NewRequest(http.MethodPost, "/verify", nil))
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			var response VerifyResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			for name, expected := range tt.expectedChecks {
+				if got := response.Checks[name]; got != expected {
+					t.Errorf("%s: expected %q, got %q", name, expected, got)
+				}
+			}
+			if len(response.Checks) != len(tt.expectedChecks) {
+				t.Errorf("expected %d checks, got %v", len(tt.expectedChecks), response.Checks)
+			}
+		})
+	}
+}
+
+func TestVerifyHandler_InvalidMethod(t *testing.T) {
+	w := httptest.NewRecorder()
+	VerifyHandler(w, httptest.NewRequest(http.MethodGet, "/verify", nil))
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestVerifyHandler_CredentialsExpiringSoon(t *testing.T) {
+	seedSecretCache(t)
+
+	originalService := gitHubService
+	originalCredentials, originalPing := GetAWSCredentialsFunc, PingECRFunc
+	defer func() {
+		gitHubService = originalService
+		GetAWSCredentialsFunc, PingECRFunc = originalCredentials, originalPing
+	}()
+
+	expiresAt := time.Now().Add(5 * time.Minute)
+	GetAWSCredentialsFunc = func(ctx context.Context) (localECR.AWSCredentials, error) {
+		return localECR.AWSCredentials{AccessKeyID: "ASIA", ExpiresAt: expiresAt}, nil
+	}
+	CreateECRClientFunc = mockCreateECRClient
+	PingECRFunc = func(ctx context.Context, client localECR.ECRClientInterface) error { return nil }
+	gitHubService = mockGitHubService{token: "mock_token", username: "octocat"}
+	NewGitClientFunc = func() *GitClient {
+		return &GitClient{
+			HTTPClient: &mockHTTPClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"is_template": true}`))}, nil
+				},
+			},
+			FetchSecretFunc: mockFetchSecretFunc,
+		}
+	}
+
+	w := httptest.NewRecorder()
+	VerifyHandler(w, httptest.NewRequest(http.MethodPost, "/verify", nil))
+
+	// Credentials about to expire still work, so the check passes with a warning
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var response VerifyResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Checks["aws_creds"] != checkOK {
+		t.Errorf("expected aws_creds %q, got %q", checkOK, response.Checks["aws_creds"])
+	}
+	expected := "credentials expire at " + expiresAt.UTC().Format(time.RFC3339)
+	if response.Warnings["aws_creds"] != expected {
+		t.Errorf("expected warning %q, got %q", expected, response.Warnings["aws_creds"])
+	}
+}
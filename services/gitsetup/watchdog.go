@@ -0,0 +1,113 @@
+package gitsetup
+
+import (
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// DefaultServerAddr is the address the web server listens on when ServerConfig does not set one.
+const DefaultServerAddr = ":8082"
+
+// SocketPathEnvVar names the Unix domain socket HandleWebServer serves on instead of DefaultServerAddr.
+const SocketPathEnvVar = "AUTOBUILD_SOCKET_PATH"
+
+// serverShutdownTimeout is how long WebServer.Start waits for in-flight requests when its context is done.
+const serverShutdownTimeout = 10 * time.Second
+
+// watchDogStableRun is how long the server must stay up before its restart count is reset.
+const watchDogStableRun = time.Minute
+
+// Wrapper variables so the server loop can be tested without binding a port or exiting
+var (
+	listenAndServeFunc = (*http.Server).ListenAndServe
+	listenFunc         = net.Listen
+	logFatalf          = log.Fatalf
+	timeNow            = time.Now
+)
+
+// ServerConfig configures the AutoBuildGo web server.
+type ServerConfig struct {
+	Addr string         // Address to listen on, DefaultServerAddr when empty
+	Mux  *http.ServeMux // Mux the routes are registered on, a new one is created when nil
+	// SocketPath serves on a Unix domain socket at this path instead of Addr, e.g. for a reverse
+	// proxy on the same host. The socket file is removed when the server stops.
+	SocketPath string
+}
+
+// newHTTPServer returns a server for handler, which must already have the routes registered,
+// listening on addr or DefaultServerAddr when empty.
+func newHTTPServer(addr string, handler http.Handler) *http.Server {
+	if addr == "" {
+		addr = DefaultServerAddr
+	}
+	return &http.Server{Addr: addr, Handler: handler}
+}
+
+// serve serves until the server fails or is shut down, on the Unix domain socket at socketPath
+// when set and on server.Addr otherwise.
+func serve(server *http.Server, socketPath string) error {
+	if socketPath == "" {
+		log.Printf("Server is starting on %s...", server.Addr)
+		return listenAndServeFunc(server)
+	}
+
+	removeStaleSocket(socketPath)
+	listener, err := listenFunc("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	defer removeSocket(socketPath)
+	log.Printf("Server is starting on unix socket %s...", socketPath)
+	return server.Serve(listener)
+}
+
+// removeStaleSocket removes the socket file left behind by a server that did not stop cleanly,
+// which would make listening on socketPath fail. Anything other than a socket is left alone.
+func removeStaleSocket(socketPath string) {
+	info, err := os.Lstat(socketPath)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	removeSocket(socketPath)
+}
+
+// removeSocket removes the socket file of a stopped server. Closing the listener usually removes
+// it already, so a missing file is not an error.
+func removeSocket(socketPath string) {
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Failed to remove socket %s: %v", socketPath, err)
+	}
+}
+
+// WatchDogServer runs the web server and restarts it whenever it stops with an error,
+// waiting backoff between attempts. It gives up with log.Fatalf after maxRestarts
+// consecutive failures; a run that stays up for a minute resets the count.
+func WatchDogServer(cfg ServerConfig, maxRestarts int, backoff time.Duration) {
+	// Routes can only be registered once per mux, so this happens before the restart loop
+	handler := serverHandler(RegisterRoutes(cfg.Mux))
+	startReloadOnSIGHUP()
+	restarts := 0
+	for {
+		started := timeNow()
+		err := serve(newHTTPServer(cfg.Addr, handler), cfg.SocketPath)
+		if err == nil || errors.Is(err, http.ErrServerClosed) {
+			return
+		}
+
+		if timeNow().Sub(started) >= watchDogStableRun {
+			restarts = 0
+		}
+		if restarts >= maxRestarts {
+			logFatalf("Server failed after %d restarts: %v", restarts, err)
+			return
+		}
+
+		restarts++
+		log.Printf("Server stopped: %v, restarting in %s (restart %d of %d)", err, backoff, restarts, maxRestarts)
+		Clock(backoff)
+	}
+}
@@ -0,0 +1,90 @@
+package gitsetup
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// watchDogTestEnv replaces the server, clock, time and fatal globals used by WatchDogServer.
+type watchDogTestEnv struct {
+	results []error // returned by successive listenAndServeFunc calls
+	runTime time.Duration
+	starts  int
+	sleeps  []time.Duration
+	fatal   string
+}
+
+func newWatchDogTestEnv(t *testing.T, results ...error) *watchDogTestEnv {
+	env := &watchDogTestEnv{results: results}
+
+	originalListen, originalFatalf := listenAndServeFunc, logFatalf
+	originalClock, originalNow := Clock, timeNow
+	t.Cleanup(func() {
+		listenAndServeFunc, logFatalf = originalListen, originalFatalf
+		Clock, timeNow = originalClock, originalNow
+	})
+
+	now := time.Now()
+	listenAndServeFunc = func(server *http.Server) error {
+		err := env.results[env.starts]
+		env.starts++
+		now = now.Add(env.runTime)
+		return err
+	}
+	logFatalf = func(format string, args ...interface{}) {
+		env.fatal = fmt.Sprintf(format, args...)
+	}
+	Clock = func(d time.Duration) { env.sleeps = append(env.sleeps, d) }
+	timeNow = func() time.Time { return now }
+	return env
+}
+
+func TestWatchDogServer_RestartsUntilClosed(t *testing.T) {
+	portInUse := errors.New("listen tcp :8082: bind: address already in use")
+	env := newWatchDogTestEnv(t, portInUse, portInUse, http.ErrServerClosed)
+
+	WatchDogServer(ServerConfig{}, 3, time.Second)
+
+	if env.starts != 3 {
+		t.Errorf("expected the server to be started 3 times, got %d", env.starts)
+	}
+	if len(env.sleeps) != 2 || env.sleeps[0] != time.Second {
+		t.Errorf("expected 2 waits of 1s between restarts, got %v", env.sleeps)
+	}
+	if env.fatal != "" {
+		t.Errorf("expected no fatal error, got %q", env.fatal)
+	}
+}
+
+func TestWatchDogServer_GivesUpAfterMaxRestarts(t *testing.T) {
+	portInUse := errors.New("address already in use")
+	env := newWatchDogTestEnv(t, portInUse, portInUse, portInUse)
+
+	WatchDogServer(ServerConfig{Addr: ":9090"}, 2, time.Second)
+
+	if env.starts != 3 {
+		t.Errorf("expected the server to be started 3 times, got %d", env.starts)
+	}
+	expected := "Server failed after 2 restarts: address already in use"
+	if env.fatal != expected {
+		t.Errorf("expected fatal error %q, got %q", expected, env.fatal)
+	}
+}
+
+func TestWatchDogServer_StableRunResetsRestarts(t *testing.T) {
+	connReset := errors.New("connection reset")
+	env := newWatchDogTestEnv(t, connReset, connReset, connReset, http.ErrServerClosed)
+	env.runTime = 2 * watchDogStableRun
+
+	WatchDogServer(ServerConfig{}, 1, time.Second)
+
+	if env.starts != 4 {
+		t.Errorf("expected the server to be started 4 times, got %d", env.starts)
+	}
+	if env.fatal != "" {
+		t.Errorf("expected no fatal error, got %q", env.fatal)
+	}
+}
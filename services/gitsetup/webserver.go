@@ -1,70 +1,201 @@
 package gitsetup
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsECR "github.com/aws/aws-sdk-go-v2/service/ecr"
 	"github.com/lep13/AutoBuildGo/services/ecr"
+	"github.com/xeipuuv/gojsonschema"
 )
 
+// ServerTokenLifetime is how long the GitClient shared by the handlers reuses a GitHub token.
+// Reloading the configuration with SIGHUP drops the token earlier.
+const ServerTokenLifetime = time.Hour
+
+// serverGitClient returns the GitClient shared by the handlers, so its token is fetched once
+// per ServerTokenLifetime instead of once per request.
+var serverGitClient = sync.OnceValue(func() *GitClient {
+	return NewGitClientWithOptions(WithTokenLifetime(ServerTokenLifetime))
+})
+
 // Wrapper variables for external dependencies
 var (
-	CreateECRClientFunc  = ecr.CreateECRClient
-	CreateRepoFunc       = ecr.CreateRepo
-	NewGitClientFunc     = NewGitClient
-	CloneAndPushRepoFunc = CloneAndPushRepo
-	SleepFunc            = time.Sleep // Make sleep function configurable
+	CreateECRClientFunc          = ecr.CreateECRClientFromEnv
+	CreateECRClientWithRoleFunc  = ecr.CreateECRClientWithRole
+	CreateRepoFunc               = ecr.CreateRepo
+	NewGitClientFunc             = serverGitClient
+	CloneAndPushRepoFunc         = CloneAndPushRepoWithConfigContext
+	CreateRepoSecretsFunc        = CreateRepoSecretsContext
+	PrefetchSecretsFunc          = PrefetchSecrets
+	ForkGitRepositoryFunc        = (*GitClient).ForkGitRepository
+	MetricsFunc                  = publishMetric
+	GetAWSCredentialsFunc        = ecr.GetAWSCredentials
+	PingECRFunc                  = ecr.Ping
+	UpdateImageTagMutabilityFunc = ecr.UpdateImageTagMutability
 )
 
+// RequireJSONContentType makes CreateRepoHandler reject requests that are not sent as application/json.
+var RequireJSONContentType = true
+
+// AllowedRoleARNsEnvVar lists the role ARNs, comma separated, that requests may pass as assume_role_arn.
+// Requests with any other role are rejected, so no role can be assumed while it is unset.
+const AllowedRoleARNsEnvVar = "AUTOBUILD_ALLOWED_ROLE_ARNS"
+
 type RepoRequest struct {
-	RepoName    string `json:"repo_name"`
-	Description string `json:"description"`
+	RepoName      string            `json:"repo_name"`
+	Description   string            `json:"description"`
+	Secrets       map[string]string `json:"secrets,omitempty"`
+	DefaultBranch string            `json:"default_branch,omitempty"`
+	// AssumeRoleARN creates the ECR repository in another account by assuming this role
+	AssumeRoleARN string `json:"assume_role_arn,omitempty"`
+	ExternalID    string `json:"external_id,omitempty"`
 }
 
-func HandleWebServer() {
-	http.HandleFunc("/create-repo", CreateRepoHandler)
-	log.Println("Server is starting on :8082...")
-	err := http.ListenAndServe(":8082", nil)
-	if err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+// CreateRepoResponse is returned by CreateRepoHandler and ImportRepoHandler on success.
+type CreateRepoResponse struct {
+	Message       string `json:"message"`
+	RepositoryURI string `json:"repository_uri"`
+	RepositoryARN string `json:"repository_arn"`
+}
+
+// RegisterRoutes registers all AutoBuildGo routes on mux, creating a new mux when nil.
+// The /debug/pprof/ endpoints are added when AUTOBUILD_PPROF is true and require the AUTOBUILD_ADMIN_TOKEN bearer token.
+func RegisterRoutes(mux *http.ServeMux) *http.ServeMux {
+	if mux == nil {
+		mux = http.NewServeMux()
+	}
+	mux.HandleFunc("/create-repo", CreateRepoHandler)
+	mux.HandleFunc("/import-repo", ImportRepoHandler)
+	mux.HandleFunc("/fork-repo", ForkRepoHandler)
+	mux.HandleFunc("/templates", ListTemplatesHandler)
+	mux.HandleFunc("/verify", VerifyHandler)
+	mux.HandleFunc("/ecr-repo", UpdateECRRepoHandler)
+	mux.HandleFunc("/update-clone-config", requireAdminToken(UpdateCloneConfigHandler))
+	if pprofEnabled() {
+		registerPprofRoutes(mux)
+	}
+	return mux
+}
+
+// WebServer serves the AutoBuildGo API. Its routes can also be mounted on the mux of a larger
+// service with RegisterRoutes.
+type WebServer struct {
+	Config ServerConfig
+	// Handler serves the requests. NewWebServer sets it to the routes registered on Config.Mux, wrapped
+	// with the middleware configured through the environment. Tests can set their own handler.
+	Handler http.Handler
+}
+
+// NewWebServer returns a WebServer for cfg, registering the routes on cfg.Mux (a new mux when nil).
+func NewWebServer(cfg ServerConfig) *WebServer {
+	return &WebServer{
+		Config:  cfg,
+		Handler: serverHandler(RegisterRoutes(cfg.Mux)),
+	}
+}
+
+// RegisterRoutes registers all AutoBuildGo routes on mux, which must not be nil.
+func (s *WebServer) RegisterRoutes(mux *http.ServeMux) {
+	RegisterRoutes(mux)
+}
+
+// Start serves the API on Config.Addr, or Config.SocketPath when set, until the server fails or ctx is done. When ctx is done the
+// server is shut down, giving in-flight requests serverShutdownTimeout to finish, and Start returns nil.
+func (s *WebServer) Start(ctx context.Context) error {
+	startReloadOnSIGHUP()
+
+	server := newHTTPServer(s.Config.Addr, s.Handler)
+	shutdownDone := make(chan struct{})
+	stopShutdown := context.AfterFunc(ctx, func() {
+		defer close(shutdownDone)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Server shutdown failed: %v", err)
+		}
+	})
+
+	err := serve(server, s.Config.SocketPath)
+	if stopShutdown() {
+		// ctx is not done, so the server stopped on its own
+		return err
+	}
+	<-shutdownDone
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
 	}
+	return err
+}
+
+// HandleWebServer registers all AutoBuildGo routes on mux (a new mux is created when nil)
+// and serves it on :8082 with a WebServer. It blocks while serving and returns the mux only once
+// the server has stopped; to compose the routes with other handlers, use RegisterRoutes instead.
+// Cross-origin requests are allowed from the origins in AUTOBUILD_CORS_ORIGINS.
+func HandleWebServer(mux *http.ServeMux) *http.ServeMux {
+	return HandleWebServerContext(context.Background(), mux)
+}
+
+// HandleWebServerContext is HandleWebServer, shutting the server down gracefully when ctx is done.
+// It serves on the Unix domain socket in AUTOBUILD_SOCKET_PATH instead of :8082 when that is set.
+func HandleWebServerContext(ctx context.Context, mux *http.ServeMux) *http.ServeMux {
+	if mux == nil {
+		mux = http.NewServeMux()
+	}
+	cfg := ServerConfig{Addr: DefaultServerAddr, Mux: mux, SocketPath: os.Getenv(SocketPathEnvVar)}
+	if err := NewWebServer(cfg).Start(ctx); err != nil {
+		logFatalf("Server failed to start: %v", err)
+	}
+	return mux
 }
 
 func CreateRepoHandler(w http.ResponseWriter, r *http.Request) {
-	log.Println("CreateRepoHandler invoked")
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	HandlerLogger.Info("CreateRepoHandler invoked")
+	req, ok := decodeRepoRequest(w, r)
+	if !ok {
 		return
 	}
 
-	var req RepoRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+	// Fail fast while GitHub is failing, before an ECR repository is created that would be left behind
+	if !gitHubAvailable() {
+		http.Error(w, ErrGitHubUnavailable.Error(), http.StatusServiceUnavailable)
 		return
 	}
 
-	if req.RepoName == "" {
-		http.Error(w, "Repository name is required", http.StatusBadRequest)
+	// Reject a second request for the same repository while the first is still running
+	if !acquireRepo(w, req.RepoName) {
 		return
 	}
+	defer createRequests.Release(req.RepoName)
+
+	// Publish the outcome once the handler returns; rejected requests above are not counted
+	succeeded := false
+	defer func() { recordRepoCreation(r.Context(), succeeded) }()
+
+	// Load the token and template URL with one Secrets Manager call; the steps below fetch them again
+	// and report any error, so a failed prefetch is only logged.
+	if err := PrefetchSecretsFunc(r.Context(), "GITHUB_TOKEN", "TEMPLATE_URL"); err != nil {
+		HandlerLogger.Error("Failed to prefetch secrets", err, "repo", req.RepoName)
+	}
 
 	description := req.Description
 	if description == "" {
 		description = "Created from a template via automated setup"
 	}
 
-	// Use the wrapper function to create ECR client
-	ecrClient, err := CreateECRClientFunc()
-	if err != nil {
-		http.Error(w, "Failed to create ECR client: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Use the wrapper function to create ECR Repository
-	if err := CreateRepoFunc(req.RepoName, ecrClient); err != nil {
-		http.Error(w, "Failed to create ECR repository: "+err.Error(), http.StatusInternalServerError)
+	ecrRepo, ok := createECRRepository(w, req)
+	if !ok {
 		return
 	}
 
@@ -74,23 +205,232 @@ func CreateRepoHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to create default repository configuration: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	config.Secrets = req.Secrets
+	config.DefaultBranch = req.DefaultBranch
 
 	gitClient := NewGitClientFunc() // Create an instance of GitClient
 
-	if err := gitClient.CreateGitRepository(config); err != nil {
-		http.Error(w, "Failed to create Git repository: "+err.Error(), http.StatusInternalServerError)
+	gitRepo, err := gitClient.CreateGitRepositoryContext(r.Context(), config)
+	if err != nil {
+		writeGitHubError(w, "Failed to create Git repository: ", err)
 		return
 	}
+	HandlerLogger.Info("Git repository created", "repo", req.RepoName, "url", gitRepo.HTMLURL)
 
-	// 20 second time delay
-	SleepFunc(20 * time.Second)
+	// Wait for GitHub to copy the template before cloning
+	readyCtx, cancel := context.WithTimeout(r.Context(), repoReadyTimeout)
+	defer cancel()
+	if err := gitRepo.Ready.WaitReady(readyCtx); err != nil {
+		writeGitHubError(w, "Git repository did not become ready: ", err)
+		return
+	}
 
 	// Use the wrapper function to clone and push the repository
-	if err := CloneAndPushRepoFunc(req.RepoName); err != nil {
+	cloneConfig := DefaultCloneConfig()
+	cloneConfig.Branch = config.DefaultBranch
+	cloneConfig.ECRRepositoryURI = aws.ToString(ecrRepo.Repository.RepositoryUri)
+	if err := CloneAndPushRepoFunc(r.Context(), req.RepoName, cloneConfig); err != nil {
+		writeGitHubError(w, "Failed to clone and push repository: ", err)
+		return
+	}
+
+	// Use the wrapper function to create the GitHub Actions secrets
+	if len(config.Secrets) > 0 {
+		if err := CreateRepoSecretsFunc(r.Context(), httpClient, req.RepoName, config.Secrets); err != nil {
+			http.Error(w, "Failed to create repository secrets: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	succeeded = true
+	HandlerLogger.Info("ECR and Git repositories created", "repo", req.RepoName)
+	writeRepoResponse(w, "ECR and Git repositories created successfully", ecrRepo)
+}
+
+// ImportRepoHandler sets up an existing GitHub repository that was not created from the template:
+// it creates the ECR repository and updates go.mod, without creating the Git repository.
+func ImportRepoHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("ImportRepoHandler invoked")
+	req, ok := decodeRepoRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if !acquireRepo(w, req.RepoName) {
+		return
+	}
+	defer createRequests.Release(req.RepoName)
+
+	// Check the Git repository first so nothing is created for a mistyped name
+	exists, err := NewGitClientFunc().CheckGitRepositoryExistsContext(r.Context(), req.RepoName)
+	if err != nil {
+		http.Error(w, "Failed to check Git repository: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "Git repository not found", http.StatusNotFound)
+		return
+	}
+
+	ecrRepo, ok := createECRRepository(w, req)
+	if !ok {
+		return
+	}
+
+	cloneConfig := DefaultCloneConfig()
+	cloneConfig.Branch = req.DefaultBranch
+	// An existing repository already has its own version history, so no initial tag is added to it.
+	cloneConfig.InitialTag = ""
+	cloneConfig.ECRRepositoryURI = aws.ToString(ecrRepo.Repository.RepositoryUri)
+	if err := CloneAndPushRepoFunc(r.Context(), req.RepoName, cloneConfig); err != nil {
 		http.Error(w, "Failed to clone and push repository: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	writeRepoResponse(w, "ECR repository created and Git repository imported successfully", ecrRepo)
+}
+
+// decodeRepoRequest checks the method and content type of r, validates its body against the
+// RepoRequest JSON schema and decodes it.
+// When it returns false the error response has already been written.
+func decodeRepoRequest(w http.ResponseWriter, r *http.Request) (RepoRequest, bool) {
+	var req RepoRequest
+	if !decodeValidatedJSONRequest(w, r, repoRequestSchema, &req) {
+		return RepoRequest{}, false
+	}
+	// Checked before anything is created, since any role the server can assume could otherwise be used
+	if req.AssumeRoleARN != "" && !roleARNAllowed(req.AssumeRoleARN) {
+		http.Error(w, fmt.Sprintf("Role %s is not allowed", req.AssumeRoleARN), http.StatusForbidden)
+		return RepoRequest{}, false
+	}
+	return req, true
+}
+
+// roleARNAllowed reports whether roleARN is listed in AUTOBUILD_ALLOWED_ROLE_ARNS.
+func roleARNAllowed(roleARN string) bool {
+	for _, allowed := range strings.Split(os.Getenv(AllowedRoleARNsEnvVar), ",") {
+		if strings.TrimSpace(allowed) == roleARN {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeJSONRequest checks that r is a JSON POST request and decodes its body, limited to
+// maxRequestBodySize bytes, into v.
+// When it returns false the error response has already been written.
+func decodeJSONRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	return decodeValidatedJSONRequest(w, r, nil, v)
+}
+
+// decodeValidatedJSONRequest is decodeJSONRequest that first validates the body against schema,
+// answering 400 with the failing field paths when it does not match. A nil schema skips validation.
+func decodeValidatedJSONRequest(w http.ResponseWriter, r *http.Request, schema *gojsonschema.Schema, v any) bool {
+	return decodeJSONRequestWithMethod(w, r, http.MethodPost, schema, v)
+}
+
+// decodeJSONRequestWithMethod is decodeValidatedJSONRequest for requests sent with method instead of POST.
+func decodeJSONRequestWithMethod(w http.ResponseWriter, r *http.Request, method string, schema *gojsonschema.Schema, v any) bool {
+	if r.Method != method {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+
+	if RequireJSONContentType && !isJSONContentType(r.Header.Get("Content-Type")) {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return false
+		}
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return false
+	}
+
+	if schema != nil {
+		result, err := schema.Validate(gojsonschema.NewBytesLoader(body))
+		if err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return false
+		}
+		if !result.Valid() {
+			http.Error(w, "Invalid request: "+schemaErrors(result), http.StatusBadRequest)
+			return false
+		}
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// createECRRepository creates the ECR repository for req, assuming the requested role if any.
+// When it returns false the error response has already been written.
+func createECRRepository(w http.ResponseWriter, req RepoRequest) (*awsECR.CreateRepositoryOutput, bool) {
+	// Use the wrapper function to create ECR client
+	var ecrClient ecr.ECRClientInterface
+	var err error
+	if req.AssumeRoleARN != "" {
+		ecrClient, err = CreateECRClientWithRoleFunc(req.AssumeRoleARN, req.ExternalID)
+	} else {
+		ecrClient, err = CreateECRClientFunc()
+	}
+	if err != nil {
+		http.Error(w, "Failed to create ECR client: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	// Use the wrapper function to create ECR Repository
+	ecrRepo, err := CreateRepoFunc(req.RepoName, ecrClient)
+	if err != nil {
+		http.Error(w, "Failed to create ECR repository: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return ecrRepo, true
+}
+
+// writeRepoResponse writes a CreateRepoResponse with message and the URI and ARN of ecrRepo.
+func writeRepoResponse(w http.ResponseWriter, message string, ecrRepo *awsECR.CreateRepositoryOutput) {
+	response := CreateRepoResponse{Message: message}
+	if ecrRepo != nil && ecrRepo.Repository != nil {
+		response.RepositoryURI = aws.ToString(ecrRepo.Repository.RepositoryUri)
+		response.RepositoryARN = aws.ToString(ecrRepo.Repository.RepositoryArn)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("ECR and Git repositories created successfully"))
+	json.NewEncoder(w).Encode(response)
+}
+
+// acquireRepo marks repoName as in progress in createRequests, or writes a 409 error and returns
+// false when another create, import or fork request for it is still running.
+func acquireRepo(w http.ResponseWriter, repoName string) bool {
+	if !createRequests.Acquire(repoName) {
+		http.Error(w, fmt.Sprintf("creation for repo %s already in progress", repoName), http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+// writeGitHubError writes err prefixed with message as a 500 error, or a 503 error when the
+// GitHub circuit breaker is open.
+func writeGitHubError(w http.ResponseWriter, message string, err error) {
+	if errors.Is(err, ErrGitHubUnavailable) {
+		http.Error(w, ErrGitHubUnavailable.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, message+err.Error(), http.StatusInternalServerError)
+}
+
+// isJSONContentType reports whether contentType is application/json, ignoring parameters such as charset.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
 }
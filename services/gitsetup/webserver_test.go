@@ -2,41 +2,57 @@ package gitsetup
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	awsECR "github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
 	localECR "github.com/lep13/AutoBuildGo/services/ecr"
 )
 
 // Mock implementation of ECRClientInterface
-func mockCreateECRClient() (*awsECR.Client, error) {
+func mockCreateECRClient() (localECR.ECRClientInterface, error) {
 	return &awsECR.Client{}, nil
 }
 
-func mockCreateECRClientError() (*awsECR.Client, error) {
+func mockCreateECRClientError() (localECR.ECRClientInterface, error) {
 	return nil, errors.New("mock error creating ECR client")
 }
 
-func mockCreateRepo(repoName string, client localECR.ECRClientInterface) error {
-	return nil
+func mockCreateRepo(repoName string, client localECR.ECRClientInterface) (*awsECR.CreateRepositoryOutput, error) {
+	return &awsECR.CreateRepositoryOutput{
+		Repository: &types.Repository{
+			RepositoryUri: aws.String("123456789012.dkr.ecr.us-east-1.amazonaws.com/" + repoName),
+			RepositoryArn: aws.String("arn:aws:ecr:us-east-1:123456789012:repository/" + repoName),
+		},
+	}, nil
 }
 
-func mockCreateRepoError(repoName string, client localECR.ECRClientInterface) error {
-	return errors.New("mock error creating ECR repository")
+func mockCreateRepoError(repoName string, client localECR.ECRClientInterface) (*awsECR.CreateRepositoryOutput, error) {
+	return nil, errors.New("mock error creating ECR repository")
 }
 
-func mockCloneAndPushRepo(repoName string) error {
+// createdResponse is the body CreateRepoHandler returns for test-repo created by mockCreateRepo.
+const createdResponse = `{"message":"ECR and Git repositories created successfully",` +
+	`"repository_uri":"123456789012.dkr.ecr.us-east-1.amazonaws.com/test-repo",` +
+	`"repository_arn":"arn:aws:ecr:us-east-1:123456789012:repository/test-repo"}`
+
+func mockCloneAndPushRepo(ctx context.Context, repoName string, cloneConfig CloneConfig) error {
 	return nil
 }
 
-func mockCloneAndPushRepoError(repoName string) error {
+func mockCloneAndPushRepoError(ctx context.Context, repoName string, cloneConfig CloneConfig) error {
 	return errors.New("mock error cloning and pushing repository")
 }
 
@@ -44,6 +60,13 @@ func mockNewGitClient() *GitClient {
 	return &GitClient{
 		HTTPClient: &mockHTTPClient{
 			doFunc: func(req *http.Request) (*http.Response, error) {
+				// The username lookup and the settings applied after creation
+				switch req.Method {
+				case http.MethodGet:
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+				case http.MethodPatch:
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString("{}"))}, nil
+				}
 				return &http.Response{
 					StatusCode: http.StatusCreated,
 					Body:       io.NopCloser(bytes.NewBufferString("")),
@@ -76,19 +99,39 @@ func mockDefaultRepoConfigError(repoName, description string) (RepoConfig, error
 	return RepoConfig{}, errors.New("mock error creating default repo config")
 }
 
+// seedSecretCache stores the secrets used by DefaultRepoConfig so tests do not reach Secrets Manager.
+func seedSecretCache(t *testing.T) {
+	secretCache.Lock()
+	originalData := secretCache.data
+	secretCache.data = map[string]string{
+		"github_token/GITHUB_TOKEN": "test_github_token",
+		"github_token/TEMPLATE_URL": "https://api.github.com/repos/template-owner/template-repo/generate",
+	}
+	secretCache.Unlock()
+
+	t.Cleanup(func() {
+		secretCache.Lock()
+		secretCache.data = originalData
+		secretCache.Unlock()
+	})
+}
+
 func TestCreateRepoHandler(t *testing.T) {
-	// Mock the SleepFunc for the tests
-	originalSleepFunc := SleepFunc
-	SleepFunc = func(d time.Duration) {}
-	defer func() { SleepFunc = originalSleepFunc }()
+	seedSecretCache(t)
+
+	// Mock the Clock for the tests
+	originalClock := Clock
+	Clock = func(d time.Duration) {}
+	defer func() { Clock = originalClock }()
 
 	tests := []struct {
 		name           string
 		body           RepoRequest
-		createECRFunc  func() (*awsECR.Client, error)
-		createRepoFunc func(string, localECR.ECRClientInterface) error
+		createECRFunc  func() (localECR.ECRClientInterface, error)
+		createRepoFunc func(string, localECR.ECRClientInterface) (*awsECR.CreateRepositoryOutput, error)
 		newGitClient   func() *GitClient
-		cloneAndPush   func(string) error
+		cloneAndPush   func(context.Context, string, CloneConfig) error
+		contentType    string
 		expectedStatus int
 		expectedBody   string
 	}{
@@ -103,7 +146,7 @@ func TestCreateRepoHandler(t *testing.T) {
 			newGitClient:   mockNewGitClient,
 			cloneAndPush:   mockCloneAndPushRepo,
 			expectedStatus: http.StatusOK,
-			expectedBody:   "ECR and Git repositories created successfully",
+			expectedBody:   createdResponse,
 		},
 		{
 			name:           "Invalid Method",
@@ -111,11 +154,33 @@ func TestCreateRepoHandler(t *testing.T) {
 			expectedStatus: http.StatusMethodNotAllowed,
 			expectedBody:   "Method not allowed",
 		},
+		{
+			name: "Unsupported Content Type",
+			body: RepoRequest{
+				RepoName: "test-repo",
+			},
+			contentType:    "text/plain",
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedBody:   "Content-Type must be application/json",
+		},
+		{
+			name: "JSON Content Type With Charset",
+			body: RepoRequest{
+				RepoName: "test-repo",
+			},
+			createECRFunc:  mockCreateECRClient,
+			createRepoFunc: mockCreateRepo,
+			newGitClient:   mockNewGitClient,
+			cloneAndPush:   mockCloneAndPushRepo,
+			contentType:    "application/json; charset=utf-8",
+			expectedStatus: http.StatusOK,
+			expectedBody:   createdResponse,
+		},
 		{
 			name:           "Empty Repo Name",
 			body:           RepoRequest{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   "Repository name is required",
+			expectedBody:   "Invalid request: repo_name: String length must be greater than or equal to 1",
 		},
 		{
 			name: "Error Creating ECR Client",
@@ -180,6 +245,11 @@ func TestCreateRepoHandler(t *testing.T) {
 			} else {
 				body, _ := json.Marshal(tt.body)
 				req = httptest.NewRequest(http.MethodPost, "/create-repo", bytes.NewBuffer(body))
+				contentType := tt.contentType
+				if contentType == "" {
+					contentType = "application/json"
+				}
+				req.Header.Set("Content-Type", contentType)
 			}
 			w := httptest.NewRecorder()
 
@@ -202,10 +272,11 @@ func TestCreateRepoHandler(t *testing.T) {
 		})
 	}
 }
+
 func TestHandleWebServer(t *testing.T) {
 	// Run the server in a goroutine
 	go func() {
-		HandleWebServer()
+		HandleWebServer(nil)
 	}()
 
 	// Wait a short time to ensure the server has started
@@ -223,9 +294,236 @@ func TestHandleWebServer(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, resp.StatusCode)
 	}
 }
+
+func TestWebServer_StartStopsWithContext(t *testing.T) {
+	var addr string
+	originalListen := listenAndServeFunc
+	t.Cleanup(func() { listenAndServeFunc = originalListen })
+	started := make(chan struct{})
+	listenAndServeFunc = func(server *http.Server) error {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return err
+		}
+		addr = listener.Addr().String()
+		close(started)
+		return server.Serve(listener)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server := &WebServer{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})}
+	done := make(chan error, 1)
+	go func() { done <- server.Start(ctx) }()
+	<-started
+
+	resp, err := http.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("Failed to send request to server: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTeapot {
+		t.Errorf("expected the configured handler to answer, got status %d", resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected no error after the context is canceled, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Start to return after the context is canceled")
+	}
+}
+
+func TestWebServer_StartError(t *testing.T) {
+	originalListen := listenAndServeFunc
+	t.Cleanup(func() { listenAndServeFunc = originalListen })
+	var listenAddr string
+	listenAndServeFunc = func(server *http.Server) error {
+		listenAddr = server.Addr
+		return errors.New("address already in use")
+	}
+
+	server := NewWebServer(ServerConfig{Addr: ":9090"})
+	if server.Handler == nil {
+		t.Error("expected NewWebServer to set up the default handler")
+	}
+	err := server.Start(context.Background())
+
+	if err == nil || err.Error() != "address already in use" {
+		t.Errorf("expected the listen error, got: %v", err)
+	}
+	if listenAddr != ":9090" {
+		t.Errorf("expected the server to listen on :9090, got %q", listenAddr)
+	}
+}
+
+func TestWebServer_UnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "autobuildgo.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	server := NewWebServer(ServerConfig{SocketPath: socketPath})
+	server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	done := make(chan error, 1)
+	go func() { done <- server.Start(ctx) }()
+
+	if status := getOverSocket(t, socketPath, "/"); status != http.StatusTeapot {
+		t.Errorf("expected status %d, got %d", http.StatusTeapot, status)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected no error after the context is canceled, got: %v", err)
+	}
+	if _, err := os.Stat(socketPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the socket file to be removed, got: %v", err)
+	}
+}
+
+// getOverSocket sends a GET request for path to the server listening on socketPath, retrying
+// while the server starts, and returns the response status.
+func getOverSocket(t *testing.T, socketPath, path string) int {
+	t.Helper()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt < 50; attempt++ {
+		if resp, err = client.Get("http://autobuildgo" + path); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Failed to send request over the socket: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestWebServer_StaleUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "autobuildgo.sock")
+	// Leave a socket file behind like a server that crashed
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create the stale socket: %v", err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server := &WebServer{Config: ServerConfig{SocketPath: socketPath}, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})}
+	done := make(chan error, 1)
+	go func() { done <- server.Start(ctx) }()
+
+	if status := getOverSocket(t, socketPath, "/"); status != http.StatusTeapot {
+		t.Errorf("expected status %d, got %d", http.StatusTeapot, status)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected no error after the context is canceled, got: %v", err)
+	}
+}
+
+func TestWebServer_SocketPathNotASocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "autobuildgo.sock")
+	if err := os.WriteFile(socketPath, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewWebServer(ServerConfig{SocketPath: socketPath}).Start(context.Background()); err == nil {
+		t.Error("expected listening on a regular file to fail")
+	}
+	if _, err := os.Stat(socketPath); err != nil {
+		t.Errorf("expected the regular file to be kept, got: %v", err)
+	}
+}
+
+func TestHandleWebServerContext_SocketPath(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "autobuildgo.sock")
+	t.Setenv(SocketPathEnvVar, socketPath)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan *http.ServeMux, 1)
+	go func() { done <- HandleWebServerContext(ctx, nil) }()
+
+	if status := getOverSocket(t, socketPath, "/create-repo"); status != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, status)
+	}
+	cancel()
+	if mux := <-done; mux == nil {
+		t.Error("expected the mux to be returned after the server stops")
+	}
+}
+
+func TestWebServer_UnixSocketListenError(t *testing.T) {
+	originalListen := listenFunc
+	t.Cleanup(func() { listenFunc = originalListen })
+	listenFunc = func(network, address string) (net.Listener, error) {
+		if network != "unix" || address != "/run/autobuildgo.sock" {
+			t.Errorf("unexpected listen on %s %s", network, address)
+		}
+		return nil, errors.New("permission denied")
+	}
+
+	err := NewWebServer(ServerConfig{SocketPath: "/run/autobuildgo.sock"}).Start(context.Background())
+	if err == nil || err.Error() != "permission denied" {
+		t.Errorf("expected the listen error, got: %v", err)
+	}
+}
+
+func TestWebServer_RegisterRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	(&WebServer{}).RegisterRoutes(mux)
+
+	if _, pattern := mux.Handler(httptest.NewRequest(http.MethodPost, "/create-repo", nil)); pattern != "/create-repo" {
+		t.Errorf("expected /create-repo to be registered, got pattern %q", pattern)
+	}
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	// A nil mux should be replaced with a new one
+	mux := RegisterRoutes(nil)
+	if mux == nil {
+		t.Fatal("expected a new ServeMux, got nil")
+	}
+
+	// Routes should be registered on a caller supplied mux alongside its own handlers
+	custom := http.NewServeMux()
+	custom.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	if got := RegisterRoutes(custom); got != custom {
+		t.Errorf("expected the supplied ServeMux to be returned")
+	}
+
+	for path, expectedStatus := range map[string]int{
+		"/create-repo": http.StatusMethodNotAllowed,
+		"/import-repo": http.StatusMethodNotAllowed,
+		"/fork-repo":   http.StatusMethodNotAllowed,
+		"/healthz":     http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		custom.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != expectedStatus {
+			t.Errorf("%s: expected status %d, got %d", path, expectedStatus, w.Code)
+		}
+	}
+}
+
 func TestCreateRepoHandler_BadRequest(t *testing.T) {
 	// Test bad request with invalid JSON
 	req := httptest.NewRequest(http.MethodPost, "/create-repo", strings.NewReader("{invalid json}"))
+	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
 	CreateRepoHandler(w, req)
@@ -239,12 +537,19 @@ func TestCreateRepoHandler_BadRequest(t *testing.T) {
 }
 
 func TestCreateRepoHandler_DefaultDescription(t *testing.T) {
+	seedSecretCache(t)
+
+	originalClock := Clock
+	Clock = func(d time.Duration) {}
+	defer func() { Clock = originalClock }()
+
 	// Test default description when none is provided
 	reqBody := RepoRequest{
 		RepoName: "test-repo",
 	}
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/create-repo", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
 	// Mock dependencies
@@ -263,6 +568,163 @@ func TestCreateRepoHandler_DefaultDescription(t *testing.T) {
 	}
 }
 
+func TestCreateRepoHandler_AssumeRole(t *testing.T) {
+	seedSecretCache(t)
+	t.Setenv(AllowedRoleARNsEnvVar, "arn:aws:iam::123456789012:role/other, arn:aws:iam::123456789012:role/ecr-admin")
+
+	originalClock, originalWithRole := Clock, CreateECRClientWithRoleFunc
+	Clock = func(d time.Duration) {}
+	defer func() { Clock, CreateECRClientWithRoleFunc = originalClock, originalWithRole }()
+
+	var roleARN, externalID string
+	CreateECRClientWithRoleFunc = func(arn, id string) (localECR.ECRClientInterface, error) {
+		roleARN, externalID = arn, id
+		return &awsECR.Client{}, nil
+	}
+	CreateECRClientFunc = mockCreateECRClientError
+	CreateRepoFunc = mockCreateRepo
+	NewGitClientFunc = mockNewGitClient
+	CloneAndPushRepoFunc = mockCloneAndPushRepo
+
+	body := `{"repo_name": "test-repo", "assume_role_arn": "arn:aws:iam::123456789012:role/ecr-admin", "external_id": "external-id"}`
+	req := httptest.NewRequest(http.MethodPost, "/create-repo", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	CreateRepoHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if roleARN != "arn:aws:iam::123456789012:role/ecr-admin" || externalID != "external-id" {
+		t.Errorf("expected the role to be assumed, got role %q and external ID %q", roleARN, externalID)
+	}
+}
+
+func TestRepoHandlers_RoleNotAllowed(t *testing.T) {
+	t.Setenv(AllowedRoleARNsEnvVar, "arn:aws:iam::123456789012:role/ecr-admin")
+
+	originalWithRole, originalNewGitClient := CreateECRClientWithRoleFunc, NewGitClientFunc
+	defer func() { CreateECRClientWithRoleFunc, NewGitClientFunc = originalWithRole, originalNewGitClient }()
+	CreateECRClientWithRoleFunc = func(arn, id string) (localECR.ECRClientInterface, error) {
+		t.Errorf("expected role %s not to be assumed", arn)
+		return &awsECR.Client{}, nil
+	}
+	NewGitClientFunc = func() *GitClient {
+		t.Error("expected the request to be rejected before GitHub is called")
+		return mockNewGitClient()
+	}
+
+	for _, path := range []string{"/create-repo", "/import-repo"} {
+		t.Run(path, func(t *testing.T) {
+			body := `{"repo_name": "test-repo", "assume_role_arn": "arn:aws:iam::999999999999:role/admin"}`
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			RegisterRoutes(http.NewServeMux()).ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("expected status %d, got %d: %s", http.StatusForbidden, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// mockNewGitClientWithRepo returns a GitClient for which test-repo exists when exists is true.
+func mockNewGitClientWithRepo(exists bool) func() *GitClient {
+	return func() *GitClient {
+		return &GitClient{
+			HTTPClient: &mockHTTPClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path == "/user" {
+						return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"login": "octocat"}`))}, nil
+					}
+					status := http.StatusNotFound
+					if exists {
+						status = http.StatusOK
+					}
+					return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+				},
+			},
+			FetchSecretFunc: mockFetchSecretFunc,
+		}
+	}
+}
+
+func TestImportRepoHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		newGitClient   func() *GitClient
+		createRepoFunc func(string, localECR.ECRClientInterface) (*awsECR.CreateRepositoryOutput, error)
+		expectedStatus int
+		expectedBody   string
+		expectedClones int
+	}{
+		{
+			name:           "Successful Import",
+			newGitClient:   mockNewGitClientWithRepo(true),
+			createRepoFunc: mockCreateRepo,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"message":"ECR repository created and Git repository imported successfully",` +
+				`"repository_uri":"123456789012.dkr.ecr.us-east-1.amazonaws.com/test-repo",` +
+				`"repository_arn":"arn:aws:ecr:us-east-1:123456789012:repository/test-repo"}`,
+			expectedClones: 1,
+		},
+		{
+			name:           "Git Repository Not Found",
+			newGitClient:   mockNewGitClientWithRepo(false),
+			createRepoFunc: mockCreateRepo,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Git repository not found",
+		},
+		{
+			name:           "Error Creating ECR Repository",
+			newGitClient:   mockNewGitClientWithRepo(true),
+			createRepoFunc: mockCreateRepoError,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to create ECR repository: mock error creating ECR repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clones := 0
+			var clonedConfig CloneConfig
+			CreateECRClientFunc = mockCreateECRClient
+			CreateRepoFunc = tt.createRepoFunc
+			NewGitClientFunc = tt.newGitClient
+			CloneAndPushRepoFunc = func(ctx context.Context, repoName string, cloneConfig CloneConfig) error {
+				clones++
+				clonedConfig = cloneConfig
+				return nil
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/import-repo", strings.NewReader(`{"repo_name": "test-repo"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ImportRepoHandler(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, body)
+			}
+			if clones != tt.expectedClones {
+				t.Errorf("expected %d clones, got %d", tt.expectedClones, clones)
+			}
+			if clones > 0 && clonedConfig.ECRRepositoryURI != "123456789012.dkr.ecr.us-east-1.amazonaws.com/test-repo" {
+				t.Errorf("expected the ECR repository URI to be passed to the clone, got %q", clonedConfig.ECRRepositoryURI)
+			}
+			if clones > 0 && clonedConfig.InitialTag != "" {
+				t.Errorf("expected no initial tag for an imported repository, got %q", clonedConfig.InitialTag)
+			}
+		})
+	}
+}
+
 // func TestCreateRepoHandler_ErrorCreatingDefaultRepoConfig(t *testing.T) {
 // 	// Mock the DefaultRepoConfig function to simulate an error
 // 	originalDefaultRepoConfig := DefaultRepoConfig
@@ -0,0 +1,44 @@
+//go:build integration
+
+// Package localstacktest starts the LocalStack container the integration tests of the ecr and
+// gitsetup packages run against. It is only built with the integration build tag.
+package localstacktest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/localstack"
+)
+
+// Image is the LocalStack image the integration tests run against.
+const Image = "localstack/localstack:3.4"
+
+// Start starts a LocalStack container, terminated when the test ends, and returns its edge endpoint.
+func Start(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+
+	container, err := localstack.RunContainer(ctx, testcontainers.WithImage(Image))
+	if err != nil {
+		t.Fatalf("failed to start LocalStack: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(ctx); err != nil {
+			t.Errorf("failed to terminate LocalStack: %v", err)
+		}
+	})
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get LocalStack host: %v", err)
+	}
+	port, err := container.MappedPort(ctx, "4566/tcp")
+	if err != nil {
+		t.Fatalf("failed to get LocalStack port: %v", err)
+	}
+
+	return fmt.Sprintf("http://%s:%s", host, port.Port())
+}